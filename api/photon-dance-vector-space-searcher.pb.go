@@ -81,6 +81,10 @@ const (
 	DocType_HTMLDoc DocType = 0
 	// TXT格式文件
 	DocType_TextDoc DocType = 1
+	// Markdown格式文件
+	DocType_MarkdownDoc DocType = 2
+	// JSON格式记录, 正文字段由配置指定
+	DocType_JSONDoc DocType = 3
 )
 
 // Enum value maps for DocType.
@@ -88,10 +92,14 @@ var (
 	DocType_name = map[int32]string{
 		0: "HTMLDoc",
 		1: "TextDoc",
+		2: "MarkdownDoc",
+		3: "JSONDoc",
 	}
 	DocType_value = map[string]int32{
-		"HTMLDoc": 0,
-		"TextDoc": 1,
+		"HTMLDoc":     0,
+		"TextDoc":     1,
+		"MarkdownDoc": 2,
+		"JSONDoc":     3,
 	}
 )
 
@@ -170,6 +178,119 @@ func (PacketDeliveryStatus) EnumDescriptor() ([]byte, []int) {
 	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{2}
 }
 
+type DocOperation int32
+
+const (
+	// 新增文档, 文档已存在时忽略
+	DocOperation_AddDoc DocOperation = 0
+	// 新增或替换文档, 文档已存在时以新内容替换旧的倒排记录
+	DocOperation_UpsertDoc DocOperation = 1
+	// 删除文档
+	DocOperation_DeleteDoc DocOperation = 2
+)
+
+// Enum value maps for DocOperation.
+var (
+	DocOperation_name = map[int32]string{
+		0: "AddDoc",
+		1: "UpsertDoc",
+		2: "DeleteDoc",
+	}
+	DocOperation_value = map[string]int32{
+		"AddDoc":    0,
+		"UpsertDoc": 1,
+		"DeleteDoc": 2,
+	}
+)
+
+func (x DocOperation) Enum() *DocOperation {
+	p := new(DocOperation)
+	*p = x
+	return p
+}
+
+func (x DocOperation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DocOperation) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[3].Descriptor()
+}
+
+func (DocOperation) Type() protoreflect.EnumType {
+	return &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[3]
+}
+
+func (x DocOperation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DocOperation.Descriptor instead.
+func (DocOperation) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{3}
+}
+
+// 排序函数.
+type RankingFunction int32
+
+const (
+	// 使用服务端配置的默认排序函数
+	RankingFunction_DefaultRanking RankingFunction = 0
+	// TF-IDF余弦相似度
+	RankingFunction_TFIDFCosine RankingFunction = 1
+	// Okapi BM25
+	RankingFunction_BM25 RankingFunction = 2
+	// 基于Dirichlet平滑的查询似然语言模型
+	RankingFunction_LMDirichlet RankingFunction = 3
+	// 按字段 (正文与汉字n-gram子字段) 分别做长度归一化的BM25F
+	RankingFunction_BM25F RankingFunction = 4
+)
+
+// Enum value maps for RankingFunction.
+var (
+	RankingFunction_name = map[int32]string{
+		0: "DefaultRanking",
+		1: "TFIDFCosine",
+		2: "BM25",
+		3: "LMDirichlet",
+		4: "BM25F",
+	}
+	RankingFunction_value = map[string]int32{
+		"DefaultRanking": 0,
+		"TFIDFCosine":    1,
+		"BM25":           2,
+		"LMDirichlet":    3,
+		"BM25F":          4,
+	}
+)
+
+func (x RankingFunction) Enum() *RankingFunction {
+	p := new(RankingFunction)
+	*p = x
+	return p
+}
+
+func (x RankingFunction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RankingFunction) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[4].Descriptor()
+}
+
+func (RankingFunction) Type() protoreflect.EnumType {
+	return &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[4]
+}
+
+func (x RankingFunction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RankingFunction.Descriptor instead.
+func (RankingFunction) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{4}
+}
+
 type ServiceStatus int32
 
 const (
@@ -200,11 +321,11 @@ func (x ServiceStatus) String() string {
 }
 
 func (ServiceStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[3].Descriptor()
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[5].Descriptor()
 }
 
 func (ServiceStatus) Type() protoreflect.EnumType {
-	return &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[3]
+	return &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes[5]
 }
 
 func (x ServiceStatus) Number() protoreflect.EnumNumber {
@@ -213,7 +334,7 @@ func (x ServiceStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ServiceStatus.Descriptor instead.
 func (ServiceStatus) EnumDescriptor() ([]byte, []int) {
-	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{3}
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{5}
 }
 
 // 传输数据包.
@@ -227,6 +348,13 @@ type Packet struct {
 	DocId          string               `protobuf:"bytes,3,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
 	DocTitle       string               `protobuf:"bytes,4,opt,name=doc_title,json=docTitle,proto3" json:"doc_title,omitempty"`
 	DeliveryStatus PacketDeliveryStatus `protobuf:"varint,5,opt,name=delivery_status,json=deliveryStatus,proto3,enum=amazingchow.photon_dance_vector_space_searcher.PacketDeliveryStatus" json:"delivery_status,omitempty"`
+	Operation      DocOperation         `protobuf:"varint,6,opt,name=operation,proto3,enum=amazingchow.photon_dance_vector_space_searcher.DocOperation" json:"operation,omitempty"`
+	// 站点名, 为空时使用web_station的枚举名, 用于查找解析网页的站点适配器
+	Site string `protobuf:"bytes,7,opt,name=site,proto3" json:"site,omitempty"`
+	// 文档原始地址, site没有对应的适配器时按地址查找
+	DocUrl string `protobuf:"bytes,8,opt,name=doc_url,json=docUrl,proto3" json:"doc_url,omitempty"`
+	// 文档发布日期, 由站点适配器从网页中抽取
+	DocDate string `protobuf:"bytes,9,opt,name=doc_date,json=docDate,proto3" json:"doc_date,omitempty"`
 }
 
 func (x *Packet) Reset() {
@@ -296,14 +424,53 @@ func (x *Packet) GetDeliveryStatus() PacketDeliveryStatus {
 	return PacketDeliveryStatus_InDelivery
 }
 
+func (x *Packet) GetOperation() DocOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return DocOperation_AddDoc
+}
+
+func (x *Packet) GetSite() string {
+	if x != nil {
+		return x.Site
+	}
+	return ""
+}
+
+func (x *Packet) GetDocUrl() string {
+	if x != nil {
+		return x.DocUrl
+	}
+	return ""
+}
+
+func (x *Packet) GetDocDate() string {
+	if x != nil {
+		return x.DocDate
+	}
+	return ""
+}
+
 // -------------------- request & response --------------------
 type QueryRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// 查询语句, 支持 +必选 / -排除 / AND / OR / NOT 以及括号分组, 未加前缀的词条为可选词条;
+	// 双引号包围的部分为短语查询, 短语后接~N为邻近查询 (词条以任意顺序出现在额外N个位置之内)
 	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	Topk  uint32 `protobuf:"varint,2,opt,name=topk,proto3" json:"topk,omitempty"`
+	// 返回得分最高的topk个文档, 设置了limit时被忽略
+	Topk    uint32          `protobuf:"varint,2,opt,name=topk,proto3" json:"topk,omitempty"`
+	Ranking RankingFunction `protobuf:"varint,3,opt,name=ranking,proto3,enum=amazingchow.photon_dance_vector_space_searcher.RankingFunction" json:"ranking,omitempty"`
+	// 结果窗口的起始位置, 设置了page_token时被忽略
+	Offset uint32 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	// 结果窗口的大小
+	Limit uint32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	// 上一页返回的next_page_token, 翻页期间始终访问同一份索引快照;
+	// 快照已被淘汰或服务重启之后令牌失效, 返回snapshot expired错误
+	PageToken string `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (x *QueryRequest) Reset() {
@@ -352,18 +519,138 @@ func (x *QueryRequest) GetTopk() uint32 {
 	return 0
 }
 
+func (x *QueryRequest) GetRanking() RankingFunction {
+	if x != nil {
+		return x.Ranking
+	}
+	return RankingFunction_DefaultRanking
+}
+
+func (x *QueryRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// 命中文档.
+type SearchHit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DocId string `protobuf:"bytes,1,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// 排序函数给出的得分
+	Score float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	// 名次, 从1开始
+	Rank uint32 `protobuf:"varint,4,opt,name=rank,proto3" json:"rank,omitempty"`
+	// 发布日期, 由站点适配器从网页中抽取
+	Date string `protobuf:"bytes,5,opt,name=date,proto3" json:"date,omitempty"`
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchHit) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+func (x *SearchHit) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SearchHit) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchHit) GetRank() uint32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchHit) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
 type QueryResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// 命中文档的标题, 请改用hits
+	//
+	// Deprecated: Do not use.
 	Docs []string `protobuf:"bytes,1,rep,name=docs,proto3" json:"docs,omitempty"`
+	// 按得分降序排列的命中文档
+	Hits []*SearchHit `protobuf:"bytes,2,rep,name=hits,proto3" json:"hits,omitempty"`
+	// 命中文档总数
+	TotalHits uint64 `protobuf:"varint,3,opt,name=total_hits,json=totalHits,proto3" json:"total_hits,omitempty"`
+	// 用于获取下一页的令牌, 没有下一页时为空
+	NextPageToken string `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
 func (x *QueryResponse) Reset() {
 	*x = QueryResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[2]
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -376,7 +663,7 @@ func (x *QueryResponse) String() string {
 func (*QueryResponse) ProtoMessage() {}
 
 func (x *QueryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[2]
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -389,9 +676,10 @@ func (x *QueryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{2}
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{3}
 }
 
+// Deprecated: Do not use.
 func (x *QueryResponse) GetDocs() []string {
 	if x != nil {
 		return x.Docs
@@ -399,6 +687,27 @@ func (x *QueryResponse) GetDocs() []string {
 	return nil
 }
 
+func (x *QueryResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetTotalHits() uint64 {
+	if x != nil {
+		return x.TotalHits
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 type GetSystemInfoRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -408,7 +717,7 @@ type GetSystemInfoRequest struct {
 func (x *GetSystemInfoRequest) Reset() {
 	*x = GetSystemInfoRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[3]
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -421,7 +730,7 @@ func (x *GetSystemInfoRequest) String() string {
 func (*GetSystemInfoRequest) ProtoMessage() {}
 
 func (x *GetSystemInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[3]
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -434,7 +743,7 @@ func (x *GetSystemInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetSystemInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetSystemInfoRequest) Descriptor() ([]byte, []int) {
-	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{3}
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{4}
 }
 
 type GetSystemInfoResponse struct {
@@ -442,8 +751,10 @@ type GetSystemInfoResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DocumentCapacity   uint64        `protobuf:"varint,1,opt,name=document_capacity,json=documentCapacity,proto3" json:"document_capacity,omitempty"`
-	Document           uint64        `protobuf:"varint,2,opt,name=document,proto3" json:"document,omitempty"`
+	// 文档总量上限, 0表示不设上限
+	DocumentCapacity uint64 `protobuf:"varint,1,opt,name=document_capacity,json=documentCapacity,proto3" json:"document_capacity,omitempty"`
+	Document         uint64 `protobuf:"varint,2,opt,name=document,proto3" json:"document,omitempty"`
+	// 词汇总量上限, 0表示不设上限
 	VocabularyCapacity uint64        `protobuf:"varint,3,opt,name=vocabulary_capacity,json=vocabularyCapacity,proto3" json:"vocabulary_capacity,omitempty"`
 	Vocabulary         uint64        `protobuf:"varint,4,opt,name=vocabulary,proto3" json:"vocabulary,omitempty"`
 	ServiceStatus      ServiceStatus `protobuf:"varint,5,opt,name=service_status,json=serviceStatus,proto3,enum=amazingchow.photon_dance_vector_space_searcher.ServiceStatus" json:"service_status,omitempty"`
@@ -452,7 +763,7 @@ type GetSystemInfoResponse struct {
 func (x *GetSystemInfoResponse) Reset() {
 	*x = GetSystemInfoResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[4]
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -465,7 +776,7 @@ func (x *GetSystemInfoResponse) String() string {
 func (*GetSystemInfoResponse) ProtoMessage() {}
 
 func (x *GetSystemInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[4]
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -478,7 +789,7 @@ func (x *GetSystemInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetSystemInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetSystemInfoResponse) Descriptor() ([]byte, []int) {
-	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{4}
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetSystemInfoResponse) GetDocumentCapacity() uint64 {
@@ -516,146 +827,973 @@ func (x *GetSystemInfoResponse) GetServiceStatus() ServiceStatus {
 	return ServiceStatus_Unavailable
 }
 
-var File_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto protoreflect.FileDescriptor
-
-var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDesc = []byte{
-	0x0a, 0x65, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6d, 0x61,
-	0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2f, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x2d,
-	0x64, 0x61, 0x6e, 0x63, 0x65, 0x2d, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2d, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x2d, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x2f, 0x70,
-	0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x2d, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x2d, 0x76, 0x65, 0x63, 0x74,
-	0x6f, 0x72, 0x2d, 0x73, 0x70, 0x61, 0x63, 0x65, 0x2d, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65,
-	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67,
-	0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63,
-	0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73,
-	0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
-	0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xdc, 0x02, 0x0a, 0x06, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
-	0x12, 0x5b, 0x0a, 0x0b, 0x77, 0x65, 0x62, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3a, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63,
-	0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65,
-	0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65,
-	0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x57, 0x65, 0x62, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x0a, 0x77, 0x65, 0x62, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x52, 0x0a,
-	0x08, 0x64, 0x6f, 0x63, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x37, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68,
-	0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f,
-	0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72,
-	0x2e, 0x44, 0x6f, 0x63, 0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x64, 0x6f, 0x63, 0x54, 0x79, 0x70,
-	0x65, 0x12, 0x15, 0x0a, 0x06, 0x64, 0x6f, 0x63, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x64, 0x6f, 0x63, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x6f, 0x63, 0x5f,
-	0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x63,
-	0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x6d, 0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72,
-	0x79, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x44,
-	0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f,
-	0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72,
-	0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e,
-	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x22, 0x38, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f,
-	0x70, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74, 0x6f, 0x70, 0x6b, 0x22, 0x23,
-	0x0a, 0x0d, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x12, 0x0a, 0x04, 0x64, 0x6f, 0x63, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x64,
-	0x6f, 0x63, 0x73, 0x22, 0x16, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
-	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x97, 0x02, 0x0a, 0x15,
-	0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e,
-	0x74, 0x5f, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x10, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69,
-	0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2f,
-	0x0a, 0x13, 0x76, 0x6f, 0x63, 0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x5f, 0x63, 0x61, 0x70,
-	0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x76, 0x6f, 0x63,
-	0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12,
-	0x1e, 0x0a, 0x0a, 0x76, 0x6f, 0x63, 0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x0a, 0x76, 0x6f, 0x63, 0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x12,
-	0x64, 0x0a, 0x0e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3d, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e,
-	0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e,
-	0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f,
-	0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x2a, 0x18, 0x0a, 0x0a, 0x57, 0x65, 0x62, 0x53, 0x74, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x4f, 0x46, 0x52, 0x50, 0x43, 0x10, 0x00, 0x2a,
-	0x23, 0x0a, 0x07, 0x44, 0x6f, 0x63, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x48, 0x54,
-	0x4d, 0x4c, 0x44, 0x6f, 0x63, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x54, 0x65, 0x78, 0x74, 0x44,
-	0x6f, 0x63, 0x10, 0x01, 0x2a, 0x36, 0x0a, 0x14, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x44, 0x65,
-	0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0e, 0x0a, 0x0a,
-	0x49, 0x6e, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a,
-	0x4f, 0x75, 0x74, 0x4f, 0x66, 0x53, 0x74, 0x6f, 0x63, 0x6b, 0x10, 0x01, 0x2a, 0x2f, 0x0a, 0x0d,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0f, 0x0a,
-	0x0b, 0x55, 0x6e, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x10, 0x00, 0x12, 0x0d,
-	0x0a, 0x09, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x10, 0x01, 0x32, 0xe3, 0x02,
-	0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x9a,
-	0x01, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x3c, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69,
-	0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61,
-	0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65,
-	0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3d, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67,
-	0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63,
-	0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73,
-	0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x22, 0x09, 0x2f,
-	0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x01, 0x2a, 0x12, 0xb5, 0x01, 0x0a, 0x0d,
-	0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x44, 0x2e,
-	0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74,
-	0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f,
-	0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x47,
-	0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x45, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f,
-	0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76,
-	0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72,
-	0x63, 0x68, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e,
-	0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x17, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x11, 0x12, 0x0f, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x69,
-	0x6e, 0x66, 0x6f, 0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2f, 0x70, 0x68,
-	0x6f, 0x74, 0x6f, 0x6e, 0x2d, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x2d, 0x76, 0x65, 0x63, 0x74, 0x6f,
-	0x72, 0x2d, 0x73, 0x70, 0x61, 0x63, 0x65, 0x2d, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72,
-	0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+type TriggerCheckpointRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-var (
-	file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescOnce sync.Once
-	file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData = file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDesc
-)
-
-func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP() []byte {
-	file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescOnce.Do(func() {
-		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData = protoimpl.X.CompressGZIP(file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData)
-	})
-	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData
+func (x *TriggerCheckpointRequest) Reset() {
+	*x = TriggerCheckpointRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+func (x *TriggerCheckpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerCheckpointRequest) ProtoMessage() {}
+
+func (x *TriggerCheckpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerCheckpointRequest.ProtoReflect.Descriptor instead.
+func (*TriggerCheckpointRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{6}
+}
+
+type TriggerCheckpointResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 本次dump的代数
+	Generation uint64 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	Document   uint64 `protobuf:"varint,2,opt,name=document,proto3" json:"document,omitempty"`
+}
+
+func (x *TriggerCheckpointResponse) Reset() {
+	*x = TriggerCheckpointResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TriggerCheckpointResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerCheckpointResponse) ProtoMessage() {}
+
+func (x *TriggerCheckpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerCheckpointResponse.ProtoReflect.Descriptor instead.
+func (*TriggerCheckpointResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TriggerCheckpointResponse) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *TriggerCheckpointResponse) GetDocument() uint64 {
+	if x != nil {
+		return x.Document
+	}
+	return 0
+}
+
+type DictionaryTerm struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// 词频, 为0时使用默认词频
+	Frequency uint32 `protobuf:"varint,2,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	// 词性, 可以为空
+	Pos string `protobuf:"bytes,3,opt,name=pos,proto3" json:"pos,omitempty"`
+}
+
+func (x *DictionaryTerm) Reset() {
+	*x = DictionaryTerm{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DictionaryTerm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DictionaryTerm) ProtoMessage() {}
+
+func (x *DictionaryTerm) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DictionaryTerm.ProtoReflect.Descriptor instead.
+func (*DictionaryTerm) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DictionaryTerm) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *DictionaryTerm) GetFrequency() uint32 {
+	if x != nil {
+		return x.Frequency
+	}
+	return 0
+}
+
+func (x *DictionaryTerm) GetPos() string {
+	if x != nil {
+		return x.Pos
+	}
+	return ""
+}
+
+type AddDictionaryTermsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Terms []*DictionaryTerm `protobuf:"bytes,1,rep,name=terms,proto3" json:"terms,omitempty"`
+}
+
+func (x *AddDictionaryTermsRequest) Reset() {
+	*x = AddDictionaryTermsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddDictionaryTermsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddDictionaryTermsRequest) ProtoMessage() {}
+
+func (x *AddDictionaryTermsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddDictionaryTermsRequest.ProtoReflect.Descriptor instead.
+func (*AddDictionaryTermsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AddDictionaryTermsRequest) GetTerms() []*DictionaryTerm {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+type RemoveDictionaryTermsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Texts []string `protobuf:"bytes,1,rep,name=texts,proto3" json:"texts,omitempty"`
+}
+
+func (x *RemoveDictionaryTermsRequest) Reset() {
+	*x = RemoveDictionaryTermsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveDictionaryTermsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveDictionaryTermsRequest) ProtoMessage() {}
+
+func (x *RemoveDictionaryTermsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveDictionaryTermsRequest.ProtoReflect.Descriptor instead.
+func (*RemoveDictionaryTermsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RemoveDictionaryTermsRequest) GetTexts() []string {
+	if x != nil {
+		return x.Texts
+	}
+	return nil
+}
+
+type ReloadDictionaryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReloadDictionaryRequest) Reset() {
+	*x = ReloadDictionaryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReloadDictionaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadDictionaryRequest) ProtoMessage() {}
+
+func (x *ReloadDictionaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadDictionaryRequest.ProtoReflect.Descriptor instead.
+func (*ReloadDictionaryRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{11}
+}
+
+type GetDictionaryInfoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 最多返回的待重新索引的文档ID个数, 为0时返回100个
+	Limit uint32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *GetDictionaryInfoRequest) Reset() {
+	*x = GetDictionaryInfoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDictionaryInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDictionaryInfoRequest) ProtoMessage() {}
+
+func (x *GetDictionaryInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDictionaryInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetDictionaryInfoRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetDictionaryInfoRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type DictionaryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 当前的词典版本
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// 用户词典中的词条数
+	UserTerms uint64 `protobuf:"varint,2,opt,name=user_terms,json=userTerms,proto3" json:"user_terms,omitempty"`
+	// 使用其他词典版本分词, 需要重新索引的文档数
+	StaleDocs   uint64   `protobuf:"varint,3,opt,name=stale_docs,json=staleDocs,proto3" json:"stale_docs,omitempty"`
+	StaleDocIds []string `protobuf:"bytes,4,rep,name=stale_doc_ids,json=staleDocIds,proto3" json:"stale_doc_ids,omitempty"`
+}
+
+func (x *DictionaryResponse) Reset() {
+	*x = DictionaryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DictionaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DictionaryResponse) ProtoMessage() {}
+
+func (x *DictionaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DictionaryResponse.ProtoReflect.Descriptor instead.
+func (*DictionaryResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DictionaryResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DictionaryResponse) GetUserTerms() uint64 {
+	if x != nil {
+		return x.UserTerms
+	}
+	return 0
+}
+
+func (x *DictionaryResponse) GetStaleDocs() uint64 {
+	if x != nil {
+		return x.StaleDocs
+	}
+	return 0
+}
+
+func (x *DictionaryResponse) GetStaleDocIds() []string {
+	if x != nil {
+		return x.StaleDocIds
+	}
+	return nil
+}
+
+type ListStopWordsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 停词表名, 可选english/chinese/special
+	List string `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+}
+
+func (x *ListStopWordsRequest) Reset() {
+	*x = ListStopWordsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListStopWordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStopWordsRequest) ProtoMessage() {}
+
+func (x *ListStopWordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStopWordsRequest.ProtoReflect.Descriptor instead.
+func (*ListStopWordsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListStopWordsRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+type EditStopWordsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 停词表名, 可选english/chinese/special
+	List  string   `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Words []string `protobuf:"bytes,2,rep,name=words,proto3" json:"words,omitempty"`
+}
+
+func (x *EditStopWordsRequest) Reset() {
+	*x = EditStopWordsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EditStopWordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditStopWordsRequest) ProtoMessage() {}
+
+func (x *EditStopWordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditStopWordsRequest.ProtoReflect.Descriptor instead.
+func (*EditStopWordsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EditStopWordsRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *EditStopWordsRequest) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+type StopWordsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 处理文档时使用的停词表版本
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// 处理查询语句时使用的停词表版本, 即当前索引快照中的文档去停词时使用的版本
+	QueryVersion string `protobuf:"bytes,2,opt,name=query_version,json=queryVersion,proto3" json:"query_version,omitempty"`
+	// 停词表中的停词个数
+	Total uint64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	// 停词表中的停词, 只在列出停词时返回
+	Words []string `protobuf:"bytes,4,rep,name=words,proto3" json:"words,omitempty"`
+}
+
+func (x *StopWordsResponse) Reset() {
+	*x = StopWordsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopWordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopWordsResponse) ProtoMessage() {}
+
+func (x *StopWordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopWordsResponse.ProtoReflect.Descriptor instead.
+func (*StopWordsResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StopWordsResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *StopWordsResponse) GetQueryVersion() string {
+	if x != nil {
+		return x.QueryVersion
+	}
+	return ""
+}
+
+func (x *StopWordsResponse) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *StopWordsResponse) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+var File_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto protoreflect.FileDescriptor
+
+var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDesc = []byte{
+	0x0a, 0x65, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6d, 0x61,
+	0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2f, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x2d,
+	0x64, 0x61, 0x6e, 0x63, 0x65, 0x2d, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2d, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x2d, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x2f, 0x70,
+	0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x2d, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x2d, 0x76, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x2d, 0x73, 0x70, 0x61, 0x63, 0x65, 0x2d, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67,
+	0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63,
+	0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x80, 0x04, 0x0a, 0x06, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
+	0x12, 0x5b, 0x0a, 0x0b, 0x77, 0x65, 0x62, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3a, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63,
+	0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65,
+	0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x57, 0x65, 0x62, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0a, 0x77, 0x65, 0x62, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x52, 0x0a,
+	0x08, 0x64, 0x6f, 0x63, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x37, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68,
+	0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72,
+	0x2e, 0x44, 0x6f, 0x63, 0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x64, 0x6f, 0x63, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x15, 0x0a, 0x06, 0x64, 0x6f, 0x63, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x64, 0x6f, 0x63, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x6f, 0x63, 0x5f,
+	0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x63,
+	0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x6d, 0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72,
+	0x79, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x44,
+	0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f,
+	0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e,
+	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x5a, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3c, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e,
+	0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e,
+	0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f,
+	0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x44, 0x6f, 0x63, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x73, 0x69, 0x74, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x6f, 0x63, 0x5f, 0x75, 0x72, 0x6c, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x63, 0x55, 0x72, 0x6c, 0x12, 0x19, 0x0a,
+	0x08, 0x64, 0x6f, 0x63, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x64, 0x6f, 0x63, 0x44, 0x61, 0x74, 0x65, 0x22, 0xe0, 0x01, 0x0a, 0x0c, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x6f, 0x70, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74,
+	0x6f, 0x70, 0x6b, 0x12, 0x59, 0x0a, 0x07, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x3f, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68,
+	0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f,
+	0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x52, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x46, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x16,
+	0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06,
+	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x76, 0x0a, 0x09, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x48, 0x69, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x64, 0x6f, 0x63, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x64, 0x6f, 0x63, 0x49, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x61, 0x6e, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x65, 0x22, 0xbd, 0x01, 0x0a, 0x0d, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x04, 0x64, 0x6f, 0x63, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x42, 0x02, 0x18, 0x01, 0x52, 0x04, 0x64, 0x6f, 0x63, 0x73, 0x12, 0x4d, 0x0a,
+	0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x39, 0x2e, 0x61, 0x6d,
+	0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e,
+	0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x48, 0x69, 0x74, 0x52, 0x04, 0x68, 0x69, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x68, 0x69, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x48, 0x69, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e,
+	0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x22, 0x16, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x97, 0x02, 0x0a, 0x15,
+	0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e,
+	0x74, 0x5f, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x10, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69,
+	0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2f,
+	0x0a, 0x13, 0x76, 0x6f, 0x63, 0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x5f, 0x63, 0x61, 0x70,
+	0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x76, 0x6f, 0x63,
+	0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12,
+	0x1e, 0x0a, 0x0a, 0x76, 0x6f, 0x63, 0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0a, 0x76, 0x6f, 0x63, 0x61, 0x62, 0x75, 0x6c, 0x61, 0x72, 0x79, 0x12,
+	0x64, 0x0a, 0x0e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3d, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e,
+	0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e,
+	0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f,
+	0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x1a, 0x0a, 0x18, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x57, 0x0a, 0x19, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e,
+	0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x54, 0x0a, 0x0e, 0x44, 0x69,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x70, 0x6f, 0x73,
+	0x22, 0x71, 0x0a, 0x19, 0x41, 0x64, 0x64, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72,
+	0x79, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x54, 0x0a,
+	0x05, 0x74, 0x65, 0x72, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3e, 0x2e, 0x61,
+	0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f,
+	0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x44, 0x69,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x52, 0x05, 0x74, 0x65,
+	0x72, 0x6d, 0x73, 0x22, 0x34, 0x0a, 0x1c, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x69, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x65, 0x78, 0x74, 0x73, 0x22, 0x19, 0x0a, 0x17, 0x52, 0x65, 0x6c,
+	0x6f, 0x61, 0x64, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x30, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x44, 0x69, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x72, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x90, 0x01, 0x0a, 0x12, 0x44, 0x69, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x5f,
+	0x74, 0x65, 0x72, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x75, 0x73, 0x65,
+	0x72, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x5f,
+	0x64, 0x6f, 0x63, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x74, 0x61, 0x6c,
+	0x65, 0x44, 0x6f, 0x63, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x5f, 0x64,
+	0x6f, 0x63, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x74,
+	0x61, 0x6c, 0x65, 0x44, 0x6f, 0x63, 0x49, 0x64, 0x73, 0x22, 0x2a, 0x0a, 0x14, 0x4c, 0x69, 0x73,
+	0x74, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6c, 0x69, 0x73, 0x74, 0x22, 0x40, 0x0a, 0x14, 0x45, 0x64, 0x69, 0x74, 0x53, 0x74, 0x6f,
+	0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x22, 0x7e, 0x0a, 0x11, 0x53, 0x74, 0x6f, 0x70, 0x57,
+	0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x2a, 0x18, 0x0a, 0x0a, 0x57, 0x65, 0x62, 0x53, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x4f, 0x46, 0x52, 0x50, 0x43, 0x10,
+	0x00, 0x2a, 0x41, 0x0a, 0x07, 0x44, 0x6f, 0x63, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07,
+	0x48, 0x54, 0x4d, 0x4c, 0x44, 0x6f, 0x63, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x54, 0x65, 0x78,
+	0x74, 0x44, 0x6f, 0x63, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x61, 0x72, 0x6b, 0x64, 0x6f,
+	0x77, 0x6e, 0x44, 0x6f, 0x63, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x4a, 0x53, 0x4f, 0x4e, 0x44,
+	0x6f, 0x63, 0x10, 0x03, 0x2a, 0x36, 0x0a, 0x14, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x44, 0x65,
+	0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0e, 0x0a, 0x0a,
+	0x49, 0x6e, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a,
+	0x4f, 0x75, 0x74, 0x4f, 0x66, 0x53, 0x74, 0x6f, 0x63, 0x6b, 0x10, 0x01, 0x2a, 0x38, 0x0a, 0x0c,
+	0x44, 0x6f, 0x63, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06,
+	0x41, 0x64, 0x64, 0x44, 0x6f, 0x63, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x55, 0x70, 0x73, 0x65,
+	0x72, 0x74, 0x44, 0x6f, 0x63, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x44, 0x6f, 0x63, 0x10, 0x02, 0x2a, 0x5c, 0x0a, 0x0f, 0x52, 0x61, 0x6e, 0x6b, 0x69, 0x6e,
+	0x67, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x0e, 0x44, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x52, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x10, 0x00, 0x12, 0x0f, 0x0a,
+	0x0b, 0x54, 0x46, 0x49, 0x44, 0x46, 0x43, 0x6f, 0x73, 0x69, 0x6e, 0x65, 0x10, 0x01, 0x12, 0x08,
+	0x0a, 0x04, 0x42, 0x4d, 0x32, 0x35, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x4c, 0x4d, 0x44, 0x69,
+	0x72, 0x69, 0x63, 0x68, 0x6c, 0x65, 0x74, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4d, 0x32,
+	0x35, 0x46, 0x10, 0x04, 0x2a, 0x2f, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x6e, 0x61, 0x76, 0x61, 0x69, 0x6c,
+	0x61, 0x62, 0x6c, 0x65, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61,
+	0x62, 0x6c, 0x65, 0x10, 0x01, 0x32, 0x94, 0x0f, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x9a, 0x01, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x3c, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70,
+	0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65,
+	0x72, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3d,
+	0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f,
+	0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x22, 0x09, 0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x3a, 0x01, 0x2a, 0x12, 0xb5, 0x01, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x44, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63,
+	0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65,
+	0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x45, 0x2e, 0x61, 0x6d,
+	0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e,
+	0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74,
+	0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x17, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x11, 0x12, 0x0f, 0x2f, 0x76, 0x31, 0x2f,
+	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0xc9, 0x01, 0x0a, 0x11,
+	0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x12, 0x48, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e,
+	0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x65, 0x72, 0x2e, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x49, 0x2e, 0x61, 0x6d,
+	0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e,
+	0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x54, 0x72, 0x69,
+	0x67, 0x67, 0x65, 0x72, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1f, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x19, 0x22, 0x14,
+	0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x3a, 0x01, 0x2a, 0x12, 0xc8, 0x01, 0x0a, 0x12, 0x41, 0x64, 0x64, 0x44,
+	0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x12, 0x49,
+	0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f,
+	0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e,
+	0x41, 0x64, 0x64, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65, 0x72,
+	0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x42, 0x2e, 0x61, 0x6d, 0x61, 0x7a,
+	0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64,
+	0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x2f, 0x64, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x2f, 0x61, 0x64, 0x64, 0x3a,
+	0x01, 0x2a, 0x12, 0xd1, 0x01, 0x0a, 0x15, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x69, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x12, 0x4c, 0x2e, 0x61,
+	0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f,
+	0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x65,
+	0x72, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x42, 0x2e, 0x61, 0x6d, 0x61,
+	0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f,
+	0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x22, 0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69,
+	0x6e, 0x2f, 0x64, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x2f, 0x72, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0xc7, 0x01, 0x0a, 0x10, 0x52, 0x65, 0x6c, 0x6f, 0x61,
+	0x64, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x12, 0x47, 0x2e, 0x61, 0x6d,
+	0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e,
+	0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x6c,
+	0x6f, 0x61, 0x64, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x42, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68,
+	0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f,
+	0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20,
+	0x22, 0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x64, 0x69, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x2f, 0x72, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x3a, 0x01, 0x2a,
+	0x12, 0xbf, 0x01, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x72, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x48, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67,
+	0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63,
+	0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x69, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x72, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x42, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70,
+	0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65,
+	0x72, 0x2e, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x12, 0x14, 0x2f, 0x76,
+	0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x64, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x72, 0x79, 0x12, 0xb5, 0x01, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74, 0x6f, 0x70, 0x57,
+	0x6f, 0x72, 0x64, 0x73, 0x12, 0x44, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68,
+	0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f,
+	0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x41, 0x2e, 0x61, 0x6d, 0x61,
+	0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f,
+	0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x53, 0x74, 0x6f, 0x70,
+	0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x15, 0x12, 0x13, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x2f, 0x73, 0x74, 0x6f, 0x70, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x12, 0xbb, 0x01, 0x0a, 0x0c, 0x41,
+	0x64, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x44, 0x2e, 0x61, 0x6d,
+	0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e,
+	0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x45, 0x64, 0x69,
+	0x74, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x41, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e,
+	0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x65, 0x72, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x22, 0x17, 0x2f, 0x76,
+	0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x73, 0x74, 0x6f, 0x70, 0x77, 0x6f, 0x72, 0x64,
+	0x73, 0x2f, 0x61, 0x64, 0x64, 0x3a, 0x01, 0x2a, 0x12, 0xc1, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x44, 0x2e, 0x61,
+	0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f,
+	0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2e, 0x45, 0x64,
+	0x69, 0x74, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x41, 0x2e, 0x61, 0x6d, 0x61, 0x7a, 0x69, 0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77,
+	0x2e, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x65, 0x72, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1f, 0x22, 0x1a, 0x2f,
+	0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x73, 0x74, 0x6f, 0x70, 0x77, 0x6f, 0x72,
+	0x64, 0x73, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x3a, 0x01, 0x2a, 0x42, 0x3f, 0x5a, 0x3d,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6d, 0x61, 0x7a, 0x69,
+	0x6e, 0x67, 0x63, 0x68, 0x6f, 0x77, 0x2f, 0x70, 0x68, 0x6f, 0x74, 0x6f, 0x6e, 0x2d, 0x64, 0x61,
+	0x6e, 0x63, 0x65, 0x2d, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2d, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x2d, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x65, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescOnce sync.Once
+	file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData = file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDesc
+)
+
+func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescGZIP() []byte {
+	file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescOnce.Do(func() {
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData = protoimpl.X.CompressGZIP(file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData)
+	})
+	return file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDescData
+}
+
+var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_goTypes = []interface{}{
-	(WebStation)(0),               // 0: amazingchow.photon_dance_vector_space_searcher.WebStation
-	(DocType)(0),                  // 1: amazingchow.photon_dance_vector_space_searcher.DocType
-	(PacketDeliveryStatus)(0),     // 2: amazingchow.photon_dance_vector_space_searcher.PacketDeliveryStatus
-	(ServiceStatus)(0),            // 3: amazingchow.photon_dance_vector_space_searcher.ServiceStatus
-	(*Packet)(nil),                // 4: amazingchow.photon_dance_vector_space_searcher.Packet
-	(*QueryRequest)(nil),          // 5: amazingchow.photon_dance_vector_space_searcher.QueryRequest
-	(*QueryResponse)(nil),         // 6: amazingchow.photon_dance_vector_space_searcher.QueryResponse
-	(*GetSystemInfoRequest)(nil),  // 7: amazingchow.photon_dance_vector_space_searcher.GetSystemInfoRequest
-	(*GetSystemInfoResponse)(nil), // 8: amazingchow.photon_dance_vector_space_searcher.GetSystemInfoResponse
+	(WebStation)(0),                      // 0: amazingchow.photon_dance_vector_space_searcher.WebStation
+	(DocType)(0),                         // 1: amazingchow.photon_dance_vector_space_searcher.DocType
+	(PacketDeliveryStatus)(0),            // 2: amazingchow.photon_dance_vector_space_searcher.PacketDeliveryStatus
+	(DocOperation)(0),                    // 3: amazingchow.photon_dance_vector_space_searcher.DocOperation
+	(RankingFunction)(0),                 // 4: amazingchow.photon_dance_vector_space_searcher.RankingFunction
+	(ServiceStatus)(0),                   // 5: amazingchow.photon_dance_vector_space_searcher.ServiceStatus
+	(*Packet)(nil),                       // 6: amazingchow.photon_dance_vector_space_searcher.Packet
+	(*QueryRequest)(nil),                 // 7: amazingchow.photon_dance_vector_space_searcher.QueryRequest
+	(*SearchHit)(nil),                    // 8: amazingchow.photon_dance_vector_space_searcher.SearchHit
+	(*QueryResponse)(nil),                // 9: amazingchow.photon_dance_vector_space_searcher.QueryResponse
+	(*GetSystemInfoRequest)(nil),         // 10: amazingchow.photon_dance_vector_space_searcher.GetSystemInfoRequest
+	(*GetSystemInfoResponse)(nil),        // 11: amazingchow.photon_dance_vector_space_searcher.GetSystemInfoResponse
+	(*TriggerCheckpointRequest)(nil),     // 12: amazingchow.photon_dance_vector_space_searcher.TriggerCheckpointRequest
+	(*TriggerCheckpointResponse)(nil),    // 13: amazingchow.photon_dance_vector_space_searcher.TriggerCheckpointResponse
+	(*DictionaryTerm)(nil),               // 14: amazingchow.photon_dance_vector_space_searcher.DictionaryTerm
+	(*AddDictionaryTermsRequest)(nil),    // 15: amazingchow.photon_dance_vector_space_searcher.AddDictionaryTermsRequest
+	(*RemoveDictionaryTermsRequest)(nil), // 16: amazingchow.photon_dance_vector_space_searcher.RemoveDictionaryTermsRequest
+	(*ReloadDictionaryRequest)(nil),      // 17: amazingchow.photon_dance_vector_space_searcher.ReloadDictionaryRequest
+	(*GetDictionaryInfoRequest)(nil),     // 18: amazingchow.photon_dance_vector_space_searcher.GetDictionaryInfoRequest
+	(*DictionaryResponse)(nil),           // 19: amazingchow.photon_dance_vector_space_searcher.DictionaryResponse
+	(*ListStopWordsRequest)(nil),         // 20: amazingchow.photon_dance_vector_space_searcher.ListStopWordsRequest
+	(*EditStopWordsRequest)(nil),         // 21: amazingchow.photon_dance_vector_space_searcher.EditStopWordsRequest
+	(*StopWordsResponse)(nil),            // 22: amazingchow.photon_dance_vector_space_searcher.StopWordsResponse
 }
 var file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_depIdxs = []int32{
-	0, // 0: amazingchow.photon_dance_vector_space_searcher.Packet.web_station:type_name -> amazingchow.photon_dance_vector_space_searcher.WebStation
-	1, // 1: amazingchow.photon_dance_vector_space_searcher.Packet.doc_type:type_name -> amazingchow.photon_dance_vector_space_searcher.DocType
-	2, // 2: amazingchow.photon_dance_vector_space_searcher.Packet.delivery_status:type_name -> amazingchow.photon_dance_vector_space_searcher.PacketDeliveryStatus
-	3, // 3: amazingchow.photon_dance_vector_space_searcher.GetSystemInfoResponse.service_status:type_name -> amazingchow.photon_dance_vector_space_searcher.ServiceStatus
-	5, // 4: amazingchow.photon_dance_vector_space_searcher.QueryService.Query:input_type -> amazingchow.photon_dance_vector_space_searcher.QueryRequest
-	7, // 5: amazingchow.photon_dance_vector_space_searcher.QueryService.GetSystemInfo:input_type -> amazingchow.photon_dance_vector_space_searcher.GetSystemInfoRequest
-	6, // 6: amazingchow.photon_dance_vector_space_searcher.QueryService.Query:output_type -> amazingchow.photon_dance_vector_space_searcher.QueryResponse
-	8, // 7: amazingchow.photon_dance_vector_space_searcher.QueryService.GetSystemInfo:output_type -> amazingchow.photon_dance_vector_space_searcher.GetSystemInfoResponse
-	6, // [6:8] is the sub-list for method output_type
-	4, // [4:6] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	0,  // 0: amazingchow.photon_dance_vector_space_searcher.Packet.web_station:type_name -> amazingchow.photon_dance_vector_space_searcher.WebStation
+	1,  // 1: amazingchow.photon_dance_vector_space_searcher.Packet.doc_type:type_name -> amazingchow.photon_dance_vector_space_searcher.DocType
+	2,  // 2: amazingchow.photon_dance_vector_space_searcher.Packet.delivery_status:type_name -> amazingchow.photon_dance_vector_space_searcher.PacketDeliveryStatus
+	3,  // 3: amazingchow.photon_dance_vector_space_searcher.Packet.operation:type_name -> amazingchow.photon_dance_vector_space_searcher.DocOperation
+	4,  // 4: amazingchow.photon_dance_vector_space_searcher.QueryRequest.ranking:type_name -> amazingchow.photon_dance_vector_space_searcher.RankingFunction
+	8,  // 5: amazingchow.photon_dance_vector_space_searcher.QueryResponse.hits:type_name -> amazingchow.photon_dance_vector_space_searcher.SearchHit
+	5,  // 6: amazingchow.photon_dance_vector_space_searcher.GetSystemInfoResponse.service_status:type_name -> amazingchow.photon_dance_vector_space_searcher.ServiceStatus
+	14, // 7: amazingchow.photon_dance_vector_space_searcher.AddDictionaryTermsRequest.terms:type_name -> amazingchow.photon_dance_vector_space_searcher.DictionaryTerm
+	7,  // 8: amazingchow.photon_dance_vector_space_searcher.QueryService.Query:input_type -> amazingchow.photon_dance_vector_space_searcher.QueryRequest
+	10, // 9: amazingchow.photon_dance_vector_space_searcher.QueryService.GetSystemInfo:input_type -> amazingchow.photon_dance_vector_space_searcher.GetSystemInfoRequest
+	12, // 10: amazingchow.photon_dance_vector_space_searcher.QueryService.TriggerCheckpoint:input_type -> amazingchow.photon_dance_vector_space_searcher.TriggerCheckpointRequest
+	15, // 11: amazingchow.photon_dance_vector_space_searcher.QueryService.AddDictionaryTerms:input_type -> amazingchow.photon_dance_vector_space_searcher.AddDictionaryTermsRequest
+	16, // 12: amazingchow.photon_dance_vector_space_searcher.QueryService.RemoveDictionaryTerms:input_type -> amazingchow.photon_dance_vector_space_searcher.RemoveDictionaryTermsRequest
+	17, // 13: amazingchow.photon_dance_vector_space_searcher.QueryService.ReloadDictionary:input_type -> amazingchow.photon_dance_vector_space_searcher.ReloadDictionaryRequest
+	18, // 14: amazingchow.photon_dance_vector_space_searcher.QueryService.GetDictionaryInfo:input_type -> amazingchow.photon_dance_vector_space_searcher.GetDictionaryInfoRequest
+	20, // 15: amazingchow.photon_dance_vector_space_searcher.QueryService.ListStopWords:input_type -> amazingchow.photon_dance_vector_space_searcher.ListStopWordsRequest
+	21, // 16: amazingchow.photon_dance_vector_space_searcher.QueryService.AddStopWords:input_type -> amazingchow.photon_dance_vector_space_searcher.EditStopWordsRequest
+	21, // 17: amazingchow.photon_dance_vector_space_searcher.QueryService.RemoveStopWords:input_type -> amazingchow.photon_dance_vector_space_searcher.EditStopWordsRequest
+	9,  // 18: amazingchow.photon_dance_vector_space_searcher.QueryService.Query:output_type -> amazingchow.photon_dance_vector_space_searcher.QueryResponse
+	11, // 19: amazingchow.photon_dance_vector_space_searcher.QueryService.GetSystemInfo:output_type -> amazingchow.photon_dance_vector_space_searcher.GetSystemInfoResponse
+	13, // 20: amazingchow.photon_dance_vector_space_searcher.QueryService.TriggerCheckpoint:output_type -> amazingchow.photon_dance_vector_space_searcher.TriggerCheckpointResponse
+	19, // 21: amazingchow.photon_dance_vector_space_searcher.QueryService.AddDictionaryTerms:output_type -> amazingchow.photon_dance_vector_space_searcher.DictionaryResponse
+	19, // 22: amazingchow.photon_dance_vector_space_searcher.QueryService.RemoveDictionaryTerms:output_type -> amazingchow.photon_dance_vector_space_searcher.DictionaryResponse
+	19, // 23: amazingchow.photon_dance_vector_space_searcher.QueryService.ReloadDictionary:output_type -> amazingchow.photon_dance_vector_space_searcher.DictionaryResponse
+	19, // 24: amazingchow.photon_dance_vector_space_searcher.QueryService.GetDictionaryInfo:output_type -> amazingchow.photon_dance_vector_space_searcher.DictionaryResponse
+	22, // 25: amazingchow.photon_dance_vector_space_searcher.QueryService.ListStopWords:output_type -> amazingchow.photon_dance_vector_space_searcher.StopWordsResponse
+	22, // 26: amazingchow.photon_dance_vector_space_searcher.QueryService.AddStopWords:output_type -> amazingchow.photon_dance_vector_space_searcher.StopWordsResponse
+	22, // 27: amazingchow.photon_dance_vector_space_searcher.QueryService.RemoveStopWords:output_type -> amazingchow.photon_dance_vector_space_searcher.StopWordsResponse
+	18, // [18:28] is the sub-list for method output_type
+	8,  // [8:18] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() {
@@ -678,8 +1816,68 @@ func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_da
 				return nil
 			}
 		}
-		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryRequest); i {
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchHit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSystemInfoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSystemInfoResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TriggerCheckpointRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -690,8 +1888,8 @@ func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_da
 				return nil
 			}
 		}
-		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryResponse); i {
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TriggerCheckpointResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -702,8 +1900,8 @@ func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_da
 				return nil
 			}
 		}
-		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetSystemInfoRequest); i {
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DictionaryTerm); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -714,8 +1912,92 @@ func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_da
 				return nil
 			}
 		}
-		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetSystemInfoResponse); i {
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddDictionaryTermsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveDictionaryTermsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReloadDictionaryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDictionaryInfoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DictionaryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListStopWordsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EditStopWordsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopWordsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -732,8 +2014,8 @@ func file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_da
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_github_com_amazingchow_photon_dance_vector_space_searcher_pb_photon_dance_vector_space_searcher_proto_rawDesc,
-			NumEnums:      4,
-			NumMessages:   5,
+			NumEnums:      6,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -762,6 +2044,22 @@ const _ = grpc.SupportPackageIsVersion6
 type QueryServiceClient interface {
 	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
 	GetSystemInfo(ctx context.Context, in *GetSystemInfoRequest, opts ...grpc.CallOption) (*GetSystemInfoResponse, error)
+	// 立即dump一次索引, 期间写入与查询照常进行
+	TriggerCheckpoint(ctx context.Context, in *TriggerCheckpointRequest, opts ...grpc.CallOption) (*TriggerCheckpointResponse, error)
+	// 向可编辑的用户词典添加词条, 并在不重启的情况下重新加载分词词典
+	AddDictionaryTerms(ctx context.Context, in *AddDictionaryTermsRequest, opts ...grpc.CallOption) (*DictionaryResponse, error)
+	// 从可编辑的用户词典删除词条, 并重新加载分词词典
+	RemoveDictionaryTerms(ctx context.Context, in *RemoveDictionaryTermsRequest, opts ...grpc.CallOption) (*DictionaryResponse, error)
+	// 重新加载全部词典文件
+	ReloadDictionary(ctx context.Context, in *ReloadDictionaryRequest, opts ...grpc.CallOption) (*DictionaryResponse, error)
+	// 获取当前的词典版本以及需要重新索引的文档
+	GetDictionaryInfo(ctx context.Context, in *GetDictionaryInfoRequest, opts ...grpc.CallOption) (*DictionaryResponse, error)
+	// 列出停词表中的停词
+	ListStopWords(ctx context.Context, in *ListStopWordsRequest, opts ...grpc.CallOption) (*StopWordsResponse, error)
+	// 向停词表添加停词, 查询语句在构造下一个索引快照之后才使用新的停词表
+	AddStopWords(ctx context.Context, in *EditStopWordsRequest, opts ...grpc.CallOption) (*StopWordsResponse, error)
+	// 从停词表删除停词, 查询语句在构造下一个索引快照之后才使用新的停词表
+	RemoveStopWords(ctx context.Context, in *EditStopWordsRequest, opts ...grpc.CallOption) (*StopWordsResponse, error)
 }
 
 type queryServiceClient struct {
@@ -790,10 +2088,98 @@ func (c *queryServiceClient) GetSystemInfo(ctx context.Context, in *GetSystemInf
 	return out, nil
 }
 
+func (c *queryServiceClient) TriggerCheckpoint(ctx context.Context, in *TriggerCheckpointRequest, opts ...grpc.CallOption) (*TriggerCheckpointResponse, error) {
+	out := new(TriggerCheckpointResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/TriggerCheckpoint", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) AddDictionaryTerms(ctx context.Context, in *AddDictionaryTermsRequest, opts ...grpc.CallOption) (*DictionaryResponse, error) {
+	out := new(DictionaryResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/AddDictionaryTerms", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) RemoveDictionaryTerms(ctx context.Context, in *RemoveDictionaryTermsRequest, opts ...grpc.CallOption) (*DictionaryResponse, error) {
+	out := new(DictionaryResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/RemoveDictionaryTerms", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) ReloadDictionary(ctx context.Context, in *ReloadDictionaryRequest, opts ...grpc.CallOption) (*DictionaryResponse, error) {
+	out := new(DictionaryResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/ReloadDictionary", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) GetDictionaryInfo(ctx context.Context, in *GetDictionaryInfoRequest, opts ...grpc.CallOption) (*DictionaryResponse, error) {
+	out := new(DictionaryResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/GetDictionaryInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) ListStopWords(ctx context.Context, in *ListStopWordsRequest, opts ...grpc.CallOption) (*StopWordsResponse, error) {
+	out := new(StopWordsResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/ListStopWords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) AddStopWords(ctx context.Context, in *EditStopWordsRequest, opts ...grpc.CallOption) (*StopWordsResponse, error) {
+	out := new(StopWordsResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/AddStopWords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) RemoveStopWords(ctx context.Context, in *EditStopWordsRequest, opts ...grpc.CallOption) (*StopWordsResponse, error) {
+	out := new(StopWordsResponse)
+	err := c.cc.Invoke(ctx, "/amazingchow.photon_dance_vector_space_searcher.QueryService/RemoveStopWords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // QueryServiceServer is the server API for QueryService service.
 type QueryServiceServer interface {
 	Query(context.Context, *QueryRequest) (*QueryResponse, error)
 	GetSystemInfo(context.Context, *GetSystemInfoRequest) (*GetSystemInfoResponse, error)
+	// 立即dump一次索引, 期间写入与查询照常进行
+	TriggerCheckpoint(context.Context, *TriggerCheckpointRequest) (*TriggerCheckpointResponse, error)
+	// 向可编辑的用户词典添加词条, 并在不重启的情况下重新加载分词词典
+	AddDictionaryTerms(context.Context, *AddDictionaryTermsRequest) (*DictionaryResponse, error)
+	// 从可编辑的用户词典删除词条, 并重新加载分词词典
+	RemoveDictionaryTerms(context.Context, *RemoveDictionaryTermsRequest) (*DictionaryResponse, error)
+	// 重新加载全部词典文件
+	ReloadDictionary(context.Context, *ReloadDictionaryRequest) (*DictionaryResponse, error)
+	// 获取当前的词典版本以及需要重新索引的文档
+	GetDictionaryInfo(context.Context, *GetDictionaryInfoRequest) (*DictionaryResponse, error)
+	// 列出停词表中的停词
+	ListStopWords(context.Context, *ListStopWordsRequest) (*StopWordsResponse, error)
+	// 向停词表添加停词, 查询语句在构造下一个索引快照之后才使用新的停词表
+	AddStopWords(context.Context, *EditStopWordsRequest) (*StopWordsResponse, error)
+	// 从停词表删除停词, 查询语句在构造下一个索引快照之后才使用新的停词表
+	RemoveStopWords(context.Context, *EditStopWordsRequest) (*StopWordsResponse, error)
 }
 
 // UnimplementedQueryServiceServer can be embedded to have forward compatible implementations.
@@ -806,6 +2192,30 @@ func (*UnimplementedQueryServiceServer) Query(context.Context, *QueryRequest) (*
 func (*UnimplementedQueryServiceServer) GetSystemInfo(context.Context, *GetSystemInfoRequest) (*GetSystemInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSystemInfo not implemented")
 }
+func (*UnimplementedQueryServiceServer) TriggerCheckpoint(context.Context, *TriggerCheckpointRequest) (*TriggerCheckpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerCheckpoint not implemented")
+}
+func (*UnimplementedQueryServiceServer) AddDictionaryTerms(context.Context, *AddDictionaryTermsRequest) (*DictionaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddDictionaryTerms not implemented")
+}
+func (*UnimplementedQueryServiceServer) RemoveDictionaryTerms(context.Context, *RemoveDictionaryTermsRequest) (*DictionaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveDictionaryTerms not implemented")
+}
+func (*UnimplementedQueryServiceServer) ReloadDictionary(context.Context, *ReloadDictionaryRequest) (*DictionaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadDictionary not implemented")
+}
+func (*UnimplementedQueryServiceServer) GetDictionaryInfo(context.Context, *GetDictionaryInfoRequest) (*DictionaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDictionaryInfo not implemented")
+}
+func (*UnimplementedQueryServiceServer) ListStopWords(context.Context, *ListStopWordsRequest) (*StopWordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStopWords not implemented")
+}
+func (*UnimplementedQueryServiceServer) AddStopWords(context.Context, *EditStopWordsRequest) (*StopWordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddStopWords not implemented")
+}
+func (*UnimplementedQueryServiceServer) RemoveStopWords(context.Context, *EditStopWordsRequest) (*StopWordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveStopWords not implemented")
+}
 
 func RegisterQueryServiceServer(s *grpc.Server, srv QueryServiceServer) {
 	s.RegisterService(&_QueryService_serviceDesc, srv)
@@ -847,6 +2257,150 @@ func _QueryService_GetSystemInfo_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _QueryService_TriggerCheckpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerCheckpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).TriggerCheckpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/TriggerCheckpoint",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).TriggerCheckpoint(ctx, req.(*TriggerCheckpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_AddDictionaryTerms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddDictionaryTermsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).AddDictionaryTerms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/AddDictionaryTerms",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).AddDictionaryTerms(ctx, req.(*AddDictionaryTermsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_RemoveDictionaryTerms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveDictionaryTermsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).RemoveDictionaryTerms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/RemoveDictionaryTerms",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).RemoveDictionaryTerms(ctx, req.(*RemoveDictionaryTermsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_ReloadDictionary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadDictionaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).ReloadDictionary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/ReloadDictionary",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).ReloadDictionary(ctx, req.(*ReloadDictionaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_GetDictionaryInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDictionaryInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetDictionaryInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/GetDictionaryInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetDictionaryInfo(ctx, req.(*GetDictionaryInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_ListStopWords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStopWordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).ListStopWords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/ListStopWords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).ListStopWords(ctx, req.(*ListStopWordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_AddStopWords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditStopWordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).AddStopWords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/AddStopWords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).AddStopWords(ctx, req.(*EditStopWordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_RemoveStopWords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditStopWordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).RemoveStopWords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amazingchow.photon_dance_vector_space_searcher.QueryService/RemoveStopWords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).RemoveStopWords(ctx, req.(*EditStopWordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _QueryService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "amazingchow.photon_dance_vector_space_searcher.QueryService",
 	HandlerType: (*QueryServiceServer)(nil),
@@ -859,6 +2413,38 @@ var _QueryService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetSystemInfo",
 			Handler:    _QueryService_GetSystemInfo_Handler,
 		},
+		{
+			MethodName: "TriggerCheckpoint",
+			Handler:    _QueryService_TriggerCheckpoint_Handler,
+		},
+		{
+			MethodName: "AddDictionaryTerms",
+			Handler:    _QueryService_AddDictionaryTerms_Handler,
+		},
+		{
+			MethodName: "RemoveDictionaryTerms",
+			Handler:    _QueryService_RemoveDictionaryTerms_Handler,
+		},
+		{
+			MethodName: "ReloadDictionary",
+			Handler:    _QueryService_ReloadDictionary_Handler,
+		},
+		{
+			MethodName: "GetDictionaryInfo",
+			Handler:    _QueryService_GetDictionaryInfo_Handler,
+		},
+		{
+			MethodName: "ListStopWords",
+			Handler:    _QueryService_ListStopWords_Handler,
+		},
+		{
+			MethodName: "AddStopWords",
+			Handler:    _QueryService_AddStopWords_Handler,
+		},
+		{
+			MethodName: "RemoveStopWords",
+			Handler:    _QueryService_RemoveStopWords_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "github.com/amazingchow/photon-dance-vector-space-searcher/pb/photon-dance-vector-space-searcher.proto",
@@ -13,12 +13,18 @@ import (
 	"github.com/rs/zerolog/log"
 
 	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/deadletter"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/kafka"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
 )
 
 var (
-	cfgPathFlag = flag.String("conf", "config/pipeline.json", "pipeline config")
-	debugFlag   = flag.Bool("debug", false, "debug log level")
+	cfgPathFlag     = flag.String("conf", "config/pipeline.json", "pipeline config")
+	debugFlag       = flag.Bool("debug", false, "debug log level")
+	migrateFlag     = flag.Bool("migrate-dump", false, "migrate json dump under indexer.dump_path to segment file, then exit")
+	replayFlag      = flag.String("replay-dead-letters", "", "replay dead letters in the given jsonl file back into kafka, then exit")
+	replayTopicFlag = flag.Bool("replay-dead-letter-topic", false, "replay dead letters not yet replayed in the dead letter topic back into kafka, then exit")
 )
 
 func main() {
@@ -33,6 +39,25 @@ func main() {
 	var cfg conf.ServiceConfig
 	utils.LoadConfigOrPanic(*cfgPathFlag, &cfg)
 
+	if *migrateFlag {
+		if err := indexing.NewPipeIndexProcessor(cfg.Pipeline.Indexer, nil).MigrateJSONDump(); err != nil {
+			log.Fatal().Err(err).Msg("cannot migrate json dump")
+		}
+		return
+	}
+	if *replayTopicFlag {
+		if err := replayDeadLetterTopic(cfg.Pipeline.Kafka, cfg.Pipeline.DeadLetter); err != nil {
+			log.Fatal().Err(err).Msg("cannot replay dead letter topic")
+		}
+		return
+	}
+	if *replayFlag != "" {
+		if err := replayDeadLetters(cfg.Pipeline.Kafka, *replayFlag); err != nil {
+			log.Fatal().Err(err).Msg("cannot replay dead letters")
+		}
+		return
+	}
+
 	stopGroup := &sync.WaitGroup{}
 	defer func() {
 		stopGroup.Wait()
@@ -68,3 +93,50 @@ LOOP:
 
 	qss.container.Stop()
 }
+
+// replayDeadLetters 将死信文件中的原始消息写回Kafka, 由管道重新处理.
+func replayDeadLetters(cfg *conf.KafkaConfig, path string) error {
+	letters, err := deadletter.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	producer, err := kafka.NewSyncProducer(cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	var topic string
+	if len(cfg.Topic) > 0 {
+		topic = cfg.Topic[0]
+	}
+	n, err := deadletter.Replay(producer, letters, topic)
+	log.Info().Msgf("replay %d/%d dead letters from path=%s", n, len(letters), path)
+	return err
+}
+
+// replayDeadLetterTopic 将死信主题中尚未重放的死信写回Kafka, 由管道重新处理.
+// 重放进度以"消费组名-dead-letter-replay"的名义提交, 多次执行不会重复重放.
+func replayDeadLetterTopic(cfg *conf.KafkaConfig, dlCfg *conf.DeadLetterConfig) error {
+	if dlCfg == nil || dlCfg.Sink != deadletter.SinkKafka || dlCfg.Topic == "" {
+		return deadletter.ErrNoKafkaSink
+	}
+	client, err := kafka.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	producer, err := kafka.NewSyncProducer(cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	var topic string
+	if len(cfg.Topic) > 0 {
+		topic = cfg.Topic[0]
+	}
+	n, err := deadletter.ReplayTopic(client, dlCfg.Topic, cfg.ConsumeGroup+"-dead-letter-replay", producer, topic)
+	log.Info().Msgf("replay %d dead letters from topic=%s", n, dlCfg.Topic)
+	return err
+}
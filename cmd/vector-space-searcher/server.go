@@ -2,35 +2,44 @@ package main
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
 	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/pipeline"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/query"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stopword"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/tokenize"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
 )
 
 type QueryServiceServer struct {
-	container *pipeline.MOFRPCContainer
+	container *pipeline.Container
 }
 
 func NewQueryServiceServer(cfg *conf.PipelineConfig) *QueryServiceServer {
 	return &QueryServiceServer{
-		container: pipeline.NewMOFRPCContainer(cfg),
+		container: pipeline.NewContainer(cfg),
 	}
 }
 
 // Query 查询服务接口.
 func (qss *QueryServiceServer) Query(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
-	if len(req.GetQuery()) == 0 || req.GetTopk() == 0 {
+	if len(req.GetQuery()) == 0 || (req.GetTopk() == 0 && req.GetLimit() == 0) {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid input")
 	}
 
-	docs, err := qss.container.Query(ctx, req.GetTopk(), req.GetQuery())
+	resp, err := qss.container.Query(ctx, req)
 	if err != nil {
-		if err == utils.ErrServiceUnavailable {
+		if errors.Is(err, indexing.ErrUnknownRanking) || errors.Is(err, query.ErrSyntax) || err == utils.ErrInvalidPageToken {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		} else if errors.Is(err, indexing.ErrSnapshotExpired) {
+			return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+		} else if err == utils.ErrServiceUnavailable {
 			return nil, status.Errorf(codes.Unavailable, err.Error())
 		} else if err == utils.ErrContextDone {
 			return nil, status.Errorf(codes.DeadlineExceeded, err.Error())
@@ -38,9 +47,12 @@ func (qss *QueryServiceServer) Query(ctx context.Context, req *pb.QueryRequest)
 		return nil, status.Errorf(codes.Unknown, err.Error())
 	}
 
-	return &pb.QueryResponse{
-		Docs: docs,
-	}, nil
+	resp.Docs = make([]string, len(resp.GetHits()))
+	for idx, hit := range resp.GetHits() {
+		resp.Docs[idx] = hit.GetTitle()
+	}
+
+	return resp, nil
 }
 
 // GetSystemInfo 获取系统信息接口.
@@ -57,3 +69,126 @@ func (qss *QueryServiceServer) GetSystemInfo(ctx context.Context, req *pb.GetSys
 
 	return info, nil
 }
+
+// TriggerCheckpoint 手动触发检查点接口.
+func (qss *QueryServiceServer) TriggerCheckpoint(ctx context.Context, req *pb.TriggerCheckpointRequest) (*pb.TriggerCheckpointResponse, error) {
+	resp, err := qss.container.TriggerCheckpoint(ctx)
+	if err != nil {
+		if err == utils.ErrServiceUnavailable {
+			return nil, status.Errorf(codes.Unavailable, err.Error())
+		} else if err == utils.ErrContextDone {
+			return nil, status.Errorf(codes.DeadlineExceeded, err.Error())
+		}
+		return nil, status.Errorf(codes.Unknown, err.Error())
+	}
+
+	return resp, nil
+}
+
+// AddDictionaryTerms 添加用户词条接口.
+func (qss *QueryServiceServer) AddDictionaryTerms(ctx context.Context, req *pb.AddDictionaryTermsRequest) (*pb.DictionaryResponse, error) {
+	if len(req.GetTerms()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid input")
+	}
+
+	resp, err := qss.container.AddDictionaryTerms(ctx, req)
+	if err != nil {
+		return nil, dictionaryError(err)
+	}
+
+	return resp, nil
+}
+
+// RemoveDictionaryTerms 删除用户词条接口.
+func (qss *QueryServiceServer) RemoveDictionaryTerms(ctx context.Context, req *pb.RemoveDictionaryTermsRequest) (*pb.DictionaryResponse, error) {
+	if len(req.GetTexts()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid input")
+	}
+
+	resp, err := qss.container.RemoveDictionaryTerms(ctx, req)
+	if err != nil {
+		return nil, dictionaryError(err)
+	}
+
+	return resp, nil
+}
+
+// ReloadDictionary 重新加载词典接口.
+func (qss *QueryServiceServer) ReloadDictionary(ctx context.Context, req *pb.ReloadDictionaryRequest) (*pb.DictionaryResponse, error) {
+	resp, err := qss.container.ReloadDictionary(ctx)
+	if err != nil {
+		return nil, dictionaryError(err)
+	}
+
+	return resp, nil
+}
+
+// GetDictionaryInfo 获取词典信息接口.
+func (qss *QueryServiceServer) GetDictionaryInfo(ctx context.Context, req *pb.GetDictionaryInfoRequest) (*pb.DictionaryResponse, error) {
+	resp, err := qss.container.GetDictionaryInfo(ctx, req)
+	if err != nil {
+		return nil, dictionaryError(err)
+	}
+
+	return resp, nil
+}
+
+// dictionaryError 将词典管理接口的错误转换为grpc状态.
+func dictionaryError(err error) error {
+	if errors.Is(err, tokenize.ErrBadUserTerm) {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	} else if err == tokenize.ErrDictionaryNotLoaded || err == tokenize.ErrNoEditableDictionary {
+		return status.Errorf(codes.FailedPrecondition, err.Error())
+	} else if err == utils.ErrServiceUnavailable {
+		return status.Errorf(codes.Unavailable, err.Error())
+	}
+	return status.Errorf(codes.Unknown, err.Error())
+}
+
+// ListStopWords 列出停词接口.
+func (qss *QueryServiceServer) ListStopWords(ctx context.Context, req *pb.ListStopWordsRequest) (*pb.StopWordsResponse, error) {
+	resp, err := qss.container.ListStopWords(ctx, req)
+	if err != nil {
+		return nil, stopWordsError(err)
+	}
+
+	return resp, nil
+}
+
+// AddStopWords 添加停词接口.
+func (qss *QueryServiceServer) AddStopWords(ctx context.Context, req *pb.EditStopWordsRequest) (*pb.StopWordsResponse, error) {
+	if len(req.GetWords()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid input")
+	}
+
+	resp, err := qss.container.AddStopWords(ctx, req)
+	if err != nil {
+		return nil, stopWordsError(err)
+	}
+
+	return resp, nil
+}
+
+// RemoveStopWords 删除停词接口.
+func (qss *QueryServiceServer) RemoveStopWords(ctx context.Context, req *pb.EditStopWordsRequest) (*pb.StopWordsResponse, error) {
+	if len(req.GetWords()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid input")
+	}
+
+	resp, err := qss.container.RemoveStopWords(ctx, req)
+	if err != nil {
+		return nil, stopWordsError(err)
+	}
+
+	return resp, nil
+}
+
+// stopWordsError 将停词管理接口的错误转换为grpc状态.
+func stopWordsError(err error) error {
+	if errors.Is(err, stopword.ErrUnknownList) || errors.Is(err, stopword.ErrBadStopWord) {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	} else if err == stopword.ErrNoEditableLists {
+		return status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	return status.Errorf(codes.Unknown, err.Error())
+}
@@ -1,30 +1,72 @@
 package common
 
 import (
+	"strings"
+	"sync"
+	"unicode"
+
 	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
 )
 
 // LanguageType 语种类型
 type LanguageType int
 
+// NGramPrefix 汉字n-gram子字段词条的前缀, 分词器产生的其他词条不含该字符
+const NGramPrefix = "#"
+
+// 处理阶段名, 用于记录死信
+const (
+	StageConsume   = "consume"
+	StageParse     = "parse"
+	StageTokenize  = "tokenize"
+	StageStopWords = "stopword"
+	StageStemming  = "stemming"
+	StageIndexing  = "indexing"
+)
+
 const (
 	// LanguageTypeEnglish 英语语种类型
 	LanguageTypeEnglish LanguageType = 0
 	// LanguageTypeChinsese 中文语种类型
 	LanguageTypeChinsese LanguageType = 1
+	// LanguageTypeMixed 中英文混合语种类型, 汉字按中文处理, 拉丁字母与数字按英文处理
+	LanguageTypeMixed LanguageType = 2
+	// LanguageTypeAuto 逐个文档以及查询语句检测语种, 只用于配置
+	LanguageTypeAuto LanguageType = 3
 )
 
 var (
+	// LanguageName2Type 语种名到语种类型之间的映射
+	LanguageName2Type = map[string]LanguageType{
+		"english": LanguageTypeEnglish,
+		"chinese": LanguageTypeChinsese,
+		"mixed":   LanguageTypeMixed,
+		"auto":    LanguageTypeAuto,
+	}
+
 	// FileType2FileTypeName 文件类型到文件类型名之间的映射
 	FileType2FileTypeName = map[pb.DocType]string{
-		pb.DocType_HTMLDoc: "html",
-		pb.DocType_TextDoc: "text",
+		pb.DocType_HTMLDoc:     "html",
+		pb.DocType_TextDoc:     "text",
+		pb.DocType_MarkdownDoc: "markdown",
+		pb.DocType_JSONDoc:     "json",
 	}
 
 	// FileType2FileSuffix 文件类型到文件后缀之间的映射
 	FileType2FileSuffix = map[pb.DocType]string{
-		pb.DocType_HTMLDoc: "html",
-		pb.DocType_TextDoc: "txt",
+		pb.DocType_HTMLDoc:     "html",
+		pb.DocType_TextDoc:     "txt",
+		pb.DocType_MarkdownDoc: "md",
+		pb.DocType_JSONDoc:     "json",
+	}
+
+	// RankingFunction2Name 排序函数到排序函数名之间的映射, 空名表示使用默认排序函数
+	RankingFunction2Name = map[pb.RankingFunction]string{
+		pb.RankingFunction_DefaultRanking: "",
+		pb.RankingFunction_TFIDFCosine:    "tfidf",
+		pb.RankingFunction_BM25:           "bm25",
+		pb.RankingFunction_LMDirichlet:    "lm_dirichlet",
+		pb.RankingFunction_BM25F:          "bm25f",
 	}
 )
 
@@ -35,19 +77,163 @@ type File struct {
 	Body []string
 }
 
-// WordsWrapper 封装词袋
-type WordsWrapper struct {
-	Words []string
-}
-
 // ConcordanceWrapper 封装concordance
 type ConcordanceWrapper struct {
-	DocID       string
+	DocID string
+	// 文档或查询语句的语种, 由分词器设置, 决定去停词与词干提取的方式
+	Language LanguageType
+	// 分词使用的词典版本, 未使用词典分词时为空
+	DictVersion string
+	// 去停词使用的停词表版本, 由停词器设置, 未去停词时为空
+	StopWordsVersion string
+	// 索引器对文档执行的操作, 删除操作不携带词条
+	Operation   pb.DocOperation
 	Concordance map[string]uint64
+	// 词条在文档中出现的位置 (从0开始, 升序排列), 与Concordance中的词频一致
+	Positions map[string][]uint32
+}
+
+// Document 在管道各阶段之间流转的文档.
+// Packet为文档对应的消息, 分词之后Concordance携带文档的词条, Ack为文档所属消息的确认句柄.
+type Document struct {
+	Packet      *pb.Packet
+	Concordance *ConcordanceWrapper
+	Ack         *Ack
+}
+
+// Ack 消息确认句柄, 随文档流经各个处理阶段.
+// 文档被持久化之后调用Done, 处理失败时调用Fail, 两者只有第一次调用生效. 为nil时调用不做任何事.
+type Ack struct {
+	once sync.Once
+	done func()
+	fail func(stage string, err error)
+}
+
+// NewAck 新建消息确认句柄.
+func NewAck(done func(), fail func(stage string, err error)) *Ack {
+	return &Ack{done: done, fail: fail}
+}
+
+// Done 确认文档已被持久化.
+func (a *Ack) Done() {
+	if a == nil {
+		return
+	}
+	a.once.Do(a.done)
+}
+
+// Fail 报告文档在stage阶段处理失败.
+func (a *Ack) Fail(stage string, err error) {
+	if a == nil {
+		return
+	}
+	a.once.Do(func() { a.fail(stage, err) })
+}
+
+// NewConcordanceWrapper 新建空的ConcordanceWrapper.
+func NewConcordanceWrapper(docID string) *ConcordanceWrapper {
+	return &ConcordanceWrapper{
+		DocID:       docID,
+		Concordance: make(map[string]uint64),
+		Positions:   make(map[string][]uint32),
+	}
 }
 
-// PacketChannel 用于传输pb.Packet
-type PacketChannel (chan *pb.Packet)
+// Add 记录一次词条出现.
+func (w *ConcordanceWrapper) Add(term string, position uint32) {
+	w.Concordance[term]++
+	w.Positions[term] = append(w.Positions[term], position)
+}
+
+// Remove 移除词条.
+func (w *ConcordanceWrapper) Remove(term string) {
+	delete(w.Concordance, term)
+	delete(w.Positions, term)
+}
+
+// Rename 将词条重命名为to, 若to已存在则合并词频与位置.
+func (w *ConcordanceWrapper) Rename(term, to string) {
+	if term == to {
+		return
+	}
+	freq, ok := w.Concordance[term]
+	if !ok {
+		return
+	}
+	w.Concordance[to] += freq
+	if w.Positions != nil {
+		w.Positions[to] = mergePositions(w.Positions[to], w.Positions[term])
+	}
+	w.Remove(term)
+}
+
+// NGramTerm 返回汉字n-gram在子字段中的词条.
+func NGramTerm(gram string) string {
+	return NGramPrefix + gram
+}
+
+// IsNGramTerm 判断词条是否属于汉字n-gram子字段.
+func IsNGramTerm(term string) bool {
+	return strings.HasPrefix(term, NGramPrefix)
+}
+
+// IsHan 判断字符是否为汉字, 与中文分词保留的字符范围一致.
+func IsHan(r rune) bool {
+	return r >= '\u4E00' && r <= '\u9FA5'
+}
+
+// ContainsHan 判断字符串中是否含有汉字.
+func ContainsHan(s string) bool {
+	for _, r := range s {
+		if IsHan(r) {
+			return true
+		}
+	}
+	return false
+}
 
-// ConcordanceChannel 用于传输ConcordanceWrapper
-type ConcordanceChannel (chan *ConcordanceWrapper)
+// DetectLanguage 按字符构成检测文本的语种.
+// 不含汉字时为英文, 只含汉字 (不含拉丁字母与数字) 时为中文, 否则为中英文混合.
+func DetectLanguage(lines ...string) LanguageType {
+	var han, latin int
+	for _, line := range lines {
+		for _, r := range line {
+			if IsHan(r) {
+				han++
+			} else if unicode.IsDigit(r) || unicode.In(r, unicode.Latin) {
+				latin++
+			}
+		}
+	}
+	if han == 0 {
+		return LanguageTypeEnglish
+	}
+	if latin == 0 {
+		return LanguageTypeChinsese
+	}
+	return LanguageTypeMixed
+}
+
+// mergePositions 合并两个升序排列的位置列表.
+func mergePositions(a, b []uint32) []uint32 {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	ret := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] <= b[j] {
+			ret = append(ret, a[i])
+			i++
+		} else {
+			ret = append(ret, b[j])
+			j++
+		}
+	}
+	ret = append(ret, a[i:]...)
+	ret = append(ret, b[j:]...)
+	return ret
+}
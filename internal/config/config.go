@@ -8,11 +8,87 @@ type ServiceConfig struct {
 }
 
 // PipelineConfig 处理管道配置
+// Language为语料的语种, 可选chinese/english/mixed/auto, 为空时使用chinese, 同时决定查询语句的分析方式;
+// mixed时汉字按中文处理, 拉丁字母与数字按英文处理, auto时逐个文档以及查询语句检测语种.
+// Stages按顺序声明管道的各个阶段, 阶段名需已注册, 最后一个阶段必须为indexing;
+// 为空时使用 parse -> tokenize -> stopword -> stemming -> indexing.
 type PipelineConfig struct {
-	Kafka   *KafkaConfig   `json:"kafka"`
-	Minio   *MinioConfig   `json:"minio"`
-	MySQL   *MySQLConfig   `json:"mysql"`
-	Indexer *IndexerConfig `json:"indexer"`
+	Kafka      *KafkaConfig      `json:"kafka"`
+	Minio      *MinioConfig      `json:"minio"`
+	MySQL      *MySQLConfig      `json:"mysql"`
+	Indexer    *IndexerConfig    `json:"indexer"`
+	DeadLetter *DeadLetterConfig `json:"dead_letter"`
+	Language   string            `json:"language"`
+	Stages     []*StageConfig    `json:"stages"`
+	Sites      []*SiteConfig     `json:"sites"`
+	JSONDoc    *JSONDocConfig    `json:"json_doc"`
+	Tokenizer  *TokenizerConfig  `json:"tokenizer"`
+	StopWords  *StopWordsConfig  `json:"stopwords"`
+	Stemming   *StemmingConfig   `json:"stemming"`
+}
+
+// TokenizerConfig 分词配置, 文档与查询语句使用同一份配置
+// ChineseMode为中文分词方式, 可选dictionary/ngram/both, 为空时使用dictionary;
+// ngram时只将汉字串切分为n-gram, both时在词典分词之外以子字段的形式额外记录n-gram, 打分时两者合并.
+// NGramSizes为n-gram的长度, 为空时使用[2, 3].
+// Dictionary为sego的基础词典, 为空时使用dict/dictionary.txt; UserDictionaries为只读的用户词典,
+// EditableDictionary为可以通过管理接口增删词条的用户词典, 文件不存在时在第一次添加词条时创建.
+// 用户词典与基础词典格式相同, 每行为"词条 词频 [词性]", 同一词条以可编辑的用户词典、用户词典、基础词典的顺序优先.
+type TokenizerConfig struct {
+	ChineseMode        string   `json:"chinese_mode"`
+	NGramSizes         []int    `json:"ngram_sizes"`
+	Dictionary         string   `json:"dictionary"`
+	UserDictionaries   []string `json:"user_dictionaries"`
+	EditableDictionary string   `json:"editable_dictionary"`
+}
+
+// StopWordsConfig 停词表配置, 每个管道 (即一个语料集合) 使用各自的一组停词表
+// English/Chinese/Special为各语种停词表的文件, 每行一个停词, 多个文件取并集, 为空时使用内置的停词表;
+// Special中的停词对所有语种生效. Editable为通过管理接口增删停词的修改记录, 为空时不允许修改停词表.
+type StopWordsConfig struct {
+	English  []string `json:"english"`
+	Chinese  []string `json:"chinese"`
+	Special  []string `json:"special"`
+	Editable string   `json:"editable"`
+}
+
+// StemmingConfig 英文词条的归一化配置, 每个管道 (即一个语料集合) 使用各自的配置, 文档与查询语句使用同一份配置
+// Normalizers按顺序声明归一化方式, 前一个的输出作为后一个的输入, 可选porter/porter2/light/lemma/none, 为空时使用porter;
+// porter2即Snowball English, light只还原名词复数, lemma按词元词典还原词元, none不做任何处理.
+// Lemmas为lemma使用的词元词典文件, 每行为"词元 变形1 变形2 ...", 不在词典中的词条保持不变.
+type StemmingConfig struct {
+	Normalizers []string `json:"normalizers"`
+	Lemmas      []string `json:"lemmas"`
+}
+
+// StageConfig 管道阶段配置
+// Workers/QueueSize不大于0时使用默认值, Language为空时使用PipelineConfig.Language.
+type StageConfig struct {
+	Name      string `json:"name"`
+	Workers   int    `json:"workers"`
+	QueueSize int    `json:"queue_size"`
+	Language  string `json:"language"`
+}
+
+// SiteConfig 站点适配器配置, 按CSS选择器从网页中抽取正文、标题以及发布日期
+// Station为站点名, 与数据包的site (为空时为web_station的枚举名) 对应, 与内置站点同名时覆盖内置配置.
+// URLPattern为匹配文档地址的正则表达式, 数据包的site没有对应的适配器时按地址查找.
+// BodySelector必填, 每个匹配的元素作为正文的一段; TitleSelector/DateSelector为空时不抽取.
+type SiteConfig struct {
+	Station       string `json:"station"`
+	URLPattern    string `json:"url_pattern"`
+	BodySelector  string `json:"body_selector"`
+	TitleSelector string `json:"title_selector"`
+	DateSelector  string `json:"date_selector"`
+}
+
+// JSONDocConfig JSON格式文档配置, 为空时从content/title/date字段中抽取
+// TextFields为正文字段, 按顺序拼接; 字段支持以.分隔的嵌套路径, 字段值为字符串或字符串数组.
+// TitleField/DateField为空时不抽取.
+type JSONDocConfig struct {
+	TextFields []string `json:"text_fields"`
+	TitleField string   `json:"title_field"`
+	DateField  string   `json:"date_field"`
 }
 
 // KafkaConfig Kafka连接配置
@@ -24,6 +100,16 @@ type KafkaConfig struct {
 	FromOldest   bool     `json:"from_oldest"`
 }
 
+// DeadLetterConfig 死信配置
+// Sink为kafka时将死信写入Topic (复用KafkaConfig的集群配置), 为file时以JSONL格式追加写入Path,
+// 为空时不记录死信, 处理失败的消息的位移不会被提交.
+// 死信的重放: Sink为file时使用--replay-dead-letters=Path, 为kafka时使用--replay-dead-letter-topic直接消费Topic.
+type DeadLetterConfig struct {
+	Sink  string `json:"sink"`
+	Topic string `json:"topic"`
+	Path  string `json:"path"`
+}
+
 // MinioConfig Minio连接配置
 type MinioConfig struct {
 	Endpoint  string `json:"endpoint"`
@@ -44,7 +130,54 @@ type MySQLConfig struct {
 }
 
 // IndexerConfig 索引器配置
+// DocCapacity/VocabularyCapacity为0时表示不设上限.
+// RetainedSnapshots为保留的TF-IDF快照个数, 分页令牌只在其对应的快照被保留期间有效.
+// Ranking为默认排序函数, 可选tfidf/bm25/bm25f/lm_dirichlet, 为空时使用tfidf.
+// RetainedDumps为DumpPath下保留的索引dump代数, 为0时保留3代.
+// 服务运行期间每隔CheckpointIntervalSec秒, 或自上次dump以来写入的文档数达到CheckpointEveryDocs时,
+// 在后台dump一次索引, 两者为0时分别不生效.
+// 未开启预写日志时kafka位移在dump之后才提交, 因此EnableWAL为false时两者不能同时为0.
+// EnableWAL开启预写日志, 写入在修改索引之前先落盘到DumpPath/wal, 重启时在最新的dump之上重放.
+// Load为false时遗留的预写日志中有记录则拒绝启动, ResetWAL为true时丢弃这些记录;
+// Load为true时预写日志不能接续加载的dump (例如最新的dump损坏之后回退到更早的一代) 则拒绝启动, ResetWAL为true时跳过缺失的记录.
 type IndexerConfig struct {
-	Load     bool   `json:"load"`
-	DumpPath string `json:"dump_path"`
+	Load                  bool               `json:"load"`
+	DumpPath              string             `json:"dump_path"`
+	RetainedDumps         int                `json:"retained_dumps"`
+	CheckpointIntervalSec int                `json:"checkpoint_interval_sec"`
+	CheckpointEveryDocs   uint64             `json:"checkpoint_every_docs"`
+	EnableWAL             bool               `json:"enable_wal"`
+	ResetWAL              bool               `json:"reset_wal"`
+	DocCapacity           uint64             `json:"doc_capacity"`
+	VocabularyCapacity    uint64             `json:"vocabulary_capacity"`
+	RetainedSnapshots     int                `json:"retained_snapshots"`
+	Ranking               string             `json:"ranking"`
+	BM25                  *BM25Config        `json:"bm25"`
+	BM25F                 *BM25FConfig       `json:"bm25f"`
+	LMDirichlet           *LMDirichletConfig `json:"lm_dirichlet"`
+}
+
+// BM25Config BM25排序函数配置, 未声明的参数使用默认值
+type BM25Config struct {
+	K1 float64  `json:"k1"`
+	B  *float64 `json:"b"`
+}
+
+// BM25FConfig BM25F排序函数配置, Body为词典分词得到的正文字段, NGram为汉字n-gram子字段,
+// 未声明的参数使用默认值
+type BM25FConfig struct {
+	K1    float64           `json:"k1"`
+	Body  *BM25FFieldConfig `json:"body"`
+	NGram *BM25FFieldConfig `json:"ngram"`
+}
+
+// BM25FFieldConfig BM25F单个字段的配置
+type BM25FFieldConfig struct {
+	Weight float64  `json:"weight"`
+	B      *float64 `json:"b"`
+}
+
+// LMDirichletConfig Dirichlet平滑语言模型排序函数配置
+type LMDirichletConfig struct {
+	Mu float64 `json:"mu"`
 }
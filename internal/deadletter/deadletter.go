@@ -0,0 +1,288 @@
+package deadletter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/kafka"
+)
+
+const (
+	// SinkKafka 将死信写入Kafka主题
+	SinkKafka = "kafka"
+	// SinkFile 将死信写入本地JSONL文件
+	SinkFile = "file"
+)
+
+var (
+	// ErrUnknownSink 未知的死信去处错误
+	ErrUnknownSink = errors.New("unknown dead letter sink")
+	// ErrNoKafkaSink 未配置Kafka死信主题错误
+	ErrNoKafkaSink = errors.New("dead letter sink is not a kafka topic")
+)
+
+// Letter 死信, 记录处理失败的阶段、文档ID、错误以及原始消息
+type Letter struct {
+	Stage     string `json:"stage"`
+	DocID     string `json:"doc_id"`
+	Error     string `json:"error"`
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+	// 原始消息, 重放时原样写回其所在的主题
+	Packet    []byte `json:"packet"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Sink 死信去处
+type Sink interface {
+	// Send 写入一封死信, 返回之后死信已被持久化 (并发安全).
+	Send(letter *Letter) error
+	Close() error
+}
+
+// NewSink 根据配置新建死信去处, 未配置时返回nil.
+func NewSink(cfg *conf.DeadLetterConfig, kafkaCfg *conf.KafkaConfig) (Sink, error) {
+	if cfg == nil || cfg.Sink == "" {
+		return nil, nil
+	}
+	switch cfg.Sink {
+	case SinkKafka:
+		{
+			return NewKafkaSink(kafkaCfg, cfg.Topic)
+		}
+	case SinkFile:
+		{
+			return NewFileSink(cfg.Path)
+		}
+	default:
+		{
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSink, cfg.Sink)
+		}
+	}
+}
+
+// KafkaSink 将死信写入Kafka主题
+type KafkaSink struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewKafkaSink 新建Kafka死信去处.
+func NewKafkaSink(cfg *conf.KafkaConfig, topic string) (*KafkaSink, error) {
+	producer, err := kafka.NewSyncProducer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msgf("load dead letter sink, topic=%s", topic)
+	return &KafkaSink{producer: producer, topic: topic}, nil
+}
+
+// Send 写入一封死信, 以文档ID作为消息的键.
+func (s *KafkaSink) Send(letter *Letter) error {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	value, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: s.topic,
+		Key:   sarama.StringEncoder(letter.DocID),
+		Value: sarama.ByteEncoder(value),
+	})
+	return err
+}
+
+// Close 关闭生产者.
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
+
+// FileSink 将死信以JSONL格式追加写入本地文件
+type FileSink struct {
+	mu sync.Mutex
+	fw *os.File
+}
+
+// NewFileSink 新建文件死信去处.
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	fw, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msgf("load dead letter sink, path=%s", path)
+	return &FileSink{fw: fw}, nil
+}
+
+// Send 追加写入一封死信并落盘.
+func (s *FileSink) Send(letter *Letter) error {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.fw.Write(line); err != nil {
+		return err
+	}
+	return s.fw.Sync()
+}
+
+// Close 关闭文件.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fw.Close()
+}
+
+// ReadFile 读取JSONL格式的死信文件.
+func ReadFile(path string) ([]*Letter, error) {
+	fr, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.Close()
+
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	letters := make([]*Letter, 0)
+	scanner := bufio.NewScanner(fr)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		letter := new(Letter)
+		if err = json.Unmarshal(scanner.Bytes(), letter); err != nil {
+			return nil, fmt.Errorf("bad dead letter at line %d: %w", n, err)
+		}
+		letters = append(letters, letter)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// Replay 将死信中的原始消息写回其所在的主题, 主题为空时写入defaultTopic, 返回成功写回的条数.
+func Replay(producer sarama.SyncProducer, letters []*Letter, defaultTopic string) (int, error) {
+	for i, letter := range letters {
+		topic := letter.Topic
+		if topic == "" {
+			topic = defaultTopic
+		}
+		if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+			Topic: topic,
+			Value: sarama.ByteEncoder(letter.Packet),
+		}); err != nil {
+			return i, err
+		}
+		log.Info().Msgf("replay dead letter, stage=%s, doc_id=%s, topic=%s", letter.Stage, letter.DocID, topic)
+	}
+	return len(letters), nil
+}
+
+// ReplayTopic 将死信主题中尚未重放的死信写回其所在的主题, 主题为空时写入defaultTopic, 返回成功写回的条数.
+// 各分区读到开始重放时的末尾为止; 重放进度以group的名义提交, 再次执行时从上次的进度继续.
+func ReplayTopic(client sarama.Client, topic, group string, producer sarama.SyncProducer, defaultTopic string) (int, error) {
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return 0, err
+	}
+	om, err := sarama.NewOffsetManagerFromClient(group, client)
+	if err != nil {
+		return 0, err
+	}
+	// 关闭时提交已标记的位移
+	defer om.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, err
+	}
+	defer consumer.Close()
+
+	var n int
+	for _, partition := range partitions {
+		pom, err := om.ManagePartition(topic, partition)
+		if err != nil {
+			return n, err
+		}
+		start, _ := pom.NextOffset()
+		if start < 0 {
+			// 没有已提交的位移, 从最早的死信开始
+			if start, err = client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+				pom.Close() // nolint
+				return n, err
+			}
+		}
+		end, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			pom.Close() // nolint
+			return n, err
+		}
+		m, err := replayPartition(consumer, pom, topic, partition, start, end, producer, defaultTopic)
+		n += m
+		pom.Close() // nolint
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// offsetMarker 记录分区的重放进度, sarama.PartitionOffsetManager满足该接口
+type offsetMarker interface {
+	MarkOffset(offset int64, metadata string)
+}
+
+// replayPartition 重放单个分区中位移在[start, end)之间的死信, 每写回一封死信标记一次进度.
+func replayPartition(consumer sarama.Consumer, marker offsetMarker, topic string, partition int32,
+	start, end int64, producer sarama.SyncProducer, defaultTopic string) (int, error) {
+	if start >= end {
+		return 0, nil
+	}
+	pc, err := consumer.ConsumePartition(topic, partition, start)
+	if err != nil {
+		return 0, err
+	}
+	defer pc.Close()
+
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	var n int
+	for next := start; next < end; {
+		select {
+		case msg := <-pc.Messages():
+			{
+				letter := new(Letter)
+				if err = json.Unmarshal(msg.Value, letter); err != nil {
+					return n, fmt.Errorf("bad dead letter, partition=%d, offset=%d: %w", partition, msg.Offset, err)
+				}
+				if _, err = Replay(producer, []*Letter{letter}, defaultTopic); err != nil {
+					return n, err
+				}
+				marker.MarkOffset(msg.Offset+1, "")
+				next = msg.Offset + 1
+				n++
+			}
+		case cerr := <-pc.Errors():
+			{
+				return n, cerr
+			}
+		}
+	}
+	return n, nil
+}
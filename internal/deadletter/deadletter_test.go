@@ -0,0 +1,122 @@
+package deadletter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dead_letter")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "dead_letter.jsonl")
+
+	sink, err := NewSink(&conf.DeadLetterConfig{Sink: SinkFile, Path: path}, nil)
+	assert.Empty(t, err)
+	letters := []*Letter{
+		{Stage: "parse", DocID: "1", Error: "empty doc", Topic: "docs", Partition: 1, Offset: 7, Packet: []byte{0x0a, 0x01, 0x31}},
+		{Stage: "indexing", DocID: "2", Error: "doc capacity exceeded", Topic: "docs", Offset: 8, Packet: []byte{0x0a, 0x01, 0x32}},
+	}
+	for _, letter := range letters {
+		assert.Empty(t, sink.Send(letter))
+	}
+	assert.Empty(t, sink.Close())
+
+	// 重新打开时追加写入
+	sink, err = NewSink(&conf.DeadLetterConfig{Sink: SinkFile, Path: path}, nil)
+	assert.Empty(t, err)
+	extra := &Letter{Stage: "tokenize", DocID: "3", Error: "not found", Packet: []byte{0x0a, 0x01, 0x33}}
+	assert.Empty(t, sink.Send(extra))
+	assert.Empty(t, sink.Close())
+
+	read, err := ReadFile(path)
+	assert.Empty(t, err)
+	assert.Equal(t, append(letters, extra), read)
+
+	_, err = NewSink(&conf.DeadLetterConfig{Sink: "mysql"}, nil)
+	assert.NotEmpty(t, err)
+	sink, err = NewSink(&conf.DeadLetterConfig{}, nil)
+	assert.Empty(t, err)
+	assert.Nil(t, sink)
+}
+
+func TestReplay(t *testing.T) {
+	letters := []*Letter{
+		{Stage: "parse", DocID: "1", Topic: "docs", Packet: []byte{0x0a, 0x01, 0x31}},
+		{Stage: "parse", DocID: "2", Packet: []byte{0x0a, 0x01, 0x32}},
+	}
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+		assert.Equal(t, letters[0].Packet, value)
+		return nil
+	})
+	producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+		assert.Equal(t, letters[1].Packet, value)
+		return nil
+	})
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+
+	n, err := Replay(producer, letters, "default")
+	assert.Empty(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = Replay(producer, letters[:1], "default")
+	assert.Equal(t, sarama.ErrOutOfBrokers, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, producer.Close())
+}
+
+type fakeMarker struct {
+	offsets []int64
+}
+
+func (m *fakeMarker) MarkOffset(offset int64, metadata string) {
+	m.offsets = append(m.offsets, offset)
+}
+
+func TestReplayPartition(t *testing.T) {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	letters := []*Letter{
+		{Stage: "parse", DocID: "1", Topic: "docs", Packet: []byte{0x0a, 0x01, 0x31}},
+		{Stage: "tokenize", DocID: "2", Packet: []byte{0x0a, 0x01, 0x32}},
+	}
+	consumer := mocks.NewConsumer(t, nil)
+	pc := consumer.ExpectConsumePartition("dead_letter", 0, 1)
+	for _, letter := range letters {
+		value, err := json.Marshal(letter)
+		assert.Empty(t, err)
+		// 模拟的分区从位移1开始
+		pc.YieldMessage(&sarama.ConsumerMessage{Value: value})
+	}
+	producer := mocks.NewSyncProducer(t, nil)
+	for _, letter := range letters {
+		packet := letter.Packet
+		producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+			assert.Equal(t, packet, value)
+			return nil
+		})
+	}
+
+	// 读到开始重放时的末尾为止, 每写回一封死信标记一次进度
+	marker := &fakeMarker{}
+	n, err := replayPartition(consumer, marker, "dead_letter", 0, 1, 3, producer, "default")
+	assert.Empty(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{2, 3}, marker.offsets)
+
+	// 没有尚未重放的死信时不消费分区
+	n, err = replayPartition(consumer, marker, "dead_letter", 1, 3, 3, producer, "default")
+	assert.Empty(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, producer.Close())
+	assert.Empty(t, consumer.Close())
+}
@@ -0,0 +1,84 @@
+package indexing
+
+import (
+	"sort"
+)
+
+// analyzerState 随dump持久化的分析器状态
+type analyzerState struct {
+	// 按词典版本分组的文档ID
+	DictVersions []*dictVersionDocs `json:"dict_versions"`
+}
+
+type dictVersionDocs struct {
+	Version string   `json:"version"`
+	DocIDs  []string `json:"doc_ids"`
+}
+
+// analyzerState 收集各文档分词时使用的词典版本, 按版本与文档ID排序.
+func (p *PipeIndexProcessor) analyzerState() *analyzerState {
+	groups := make(map[string][]string)
+	p.docsMu.RLock()
+	for docID, entry := range p.docs {
+		if entry.dictVersion != "" {
+			groups[entry.dictVersion] = append(groups[entry.dictVersion], docID)
+		}
+	}
+	p.docsMu.RUnlock()
+
+	state := &analyzerState{DictVersions: make([]*dictVersionDocs, 0, len(groups))}
+	for version, docIDs := range groups {
+		sort.Strings(docIDs)
+		state.DictVersions = append(state.DictVersions, &dictVersionDocs{Version: version, DocIDs: docIDs})
+	}
+	sort.Slice(state.DictVersions, func(i, j int) bool {
+		return state.DictVersions[i].Version < state.DictVersions[j].Version
+	})
+	return state
+}
+
+// restoreAnalyzerState 将dump中的分析器状态恢复到正排索引, 需在正排索引重建之后调用.
+func (p *PipeIndexProcessor) restoreAnalyzerState(state *analyzerState) {
+	p.docsMu.Lock()
+	defer p.docsMu.Unlock()
+	for _, group := range state.DictVersions {
+		for _, docID := range group.DocIDs {
+			if entry, ok := p.docs[docID]; ok {
+				entry.dictVersion = group.Version
+			}
+		}
+	}
+}
+
+// DictVersions 返回各词典版本分词的文档数.
+func (p *PipeIndexProcessor) DictVersions() map[string]uint64 {
+	versions := make(map[string]uint64)
+	p.docsMu.RLock()
+	for _, entry := range p.docs {
+		if entry.dictVersion != "" {
+			versions[entry.dictVersion]++
+		}
+	}
+	p.docsMu.RUnlock()
+	return versions
+}
+
+// StaleDocs 返回使用version以外的词典版本分词的文档总数, 以及按文档ID排序的前limit个文档ID.
+// 这些文档需要重新索引才能反映词典的变化, 未使用词典分词的文档不计入.
+func (p *PipeIndexProcessor) StaleDocs(version string, limit int) ([]string, uint64) {
+	docIDs := make([]string, 0)
+	p.docsMu.RLock()
+	for docID, entry := range p.docs {
+		if entry.dictVersion != "" && entry.dictVersion != version {
+			docIDs = append(docIDs, docID)
+		}
+	}
+	p.docsMu.RUnlock()
+
+	sort.Strings(docIDs)
+	total := uint64(len(docIDs))
+	if limit >= 0 && len(docIDs) > limit {
+		docIDs = docIDs[:limit]
+	}
+	return docIDs, total
+}
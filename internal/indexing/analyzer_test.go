@@ -0,0 +1,57 @@
+package indexing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func TestDictVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analyzer")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	cfg := &conf.IndexerConfig{DumpPath: dir, EnableWAL: true}
+
+	index := func(p *PipeIndexProcessor, docID, text, version string, operation pb.DocOperation) {
+		wrapper := newTestWrapper(docID, text)
+		wrapper.Operation = operation
+		wrapper.DictVersion = version
+		assert.Empty(t, p.indexing(wrapper, nil))
+	}
+
+	p := newTestIndexer(cfg)
+	assert.Empty(t, p.Load())
+	index(p, "1", "收入 保险", "v1", pb.DocOperation_AddDoc)
+	index(p, "2", "粮食 作物", "v1", pb.DocOperation_AddDoc)
+	// 未使用词典分词的文档不计入
+	index(p, "3", "fiscal revenue", "", pb.DocOperation_AddDoc)
+	_, err = p.Dump()
+	assert.Empty(t, err)
+	// dump之后的写入只存在于日志中
+	index(p, "4", "农业 补贴", "v2", pb.DocOperation_AddDoc)
+	index(p, "2", "粮食 补贴", "v2", pb.DocOperation_UpsertDoc)
+	assert.Empty(t, p.CloseWAL())
+	assert.Equal(t, map[string]uint64{"v1": 1, "v2": 2}, p.DictVersions())
+
+	q := newTestIndexer(cfg)
+	assert.Empty(t, q.Load())
+	assert.Equal(t, p.DictVersions(), q.DictVersions())
+	docIDs, total := q.StaleDocs("v2", 10)
+	assert.Equal(t, []string{"1"}, docIDs)
+	assert.Equal(t, uint64(1), total)
+	docIDs, total = q.StaleDocs("v3", 2)
+	assert.Equal(t, []string{"1", "2"}, docIDs)
+	assert.Equal(t, uint64(3), total)
+
+	// 删除的文档不再需要重新索引
+	assert.Empty(t, q.indexing(&common.ConcordanceWrapper{DocID: "1", Operation: pb.DocOperation_DeleteDoc}, nil))
+	_, total = q.StaleDocs("v2", 10)
+	assert.Equal(t, uint64(0), total)
+	assert.Empty(t, q.CloseWAL())
+}
@@ -0,0 +1,323 @@
+package indexing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
+)
+
+// DumpPath下的目录结构:
+//
+//	gen-0000000001/index.seg
+//	gen-0000000001/analyzer.json
+//	gen-0000000001/MANIFEST
+//	gen-0000000002/...
+//
+// 每一代先完整写入以.tmp-开头的临时目录并fsync, 再原子地重命名为gen-N,
+// 因此进程在dump期间崩溃只会留下临时目录, 不会破坏已有的dump.
+const (
+	_SegmentFile          = "index.seg"
+	_AnalyzerFile         = "analyzer.json"
+	_ManifestFile         = "MANIFEST"
+	_GenerationPrefix     = "gen-"
+	_TempDirPrefix        = ".tmp-"
+	_DefaultRetainedDumps = 3
+)
+
+var (
+	// ErrNoValidDump 所有dump均已损坏错误
+	ErrNoValidDump = errors.New("no valid dump")
+)
+
+// manifest 记录一代dump中的文件及其校验和
+type manifest struct {
+	Generation uint64 `json:"generation"`
+	CreatedAt  int64  `json:"created_at"`
+	// dump已包含的预写日志的最大序列号
+	WALSequence uint64         `json:"wal_sequence,omitempty"`
+	Files       []manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Dump 将索引结构以段文件格式持久化为新的一代, 并淘汰超出保留代数的旧dump, 返回新的代数.
+// 只在获取一致视图期间短暂阻塞写入, 编码与写文件期间写入与查询均不受影响.
+func (p *PipeIndexProcessor) Dump() (uint64, error) {
+	p.dumpMu.Lock()
+	defer p.dumpMu.Unlock()
+
+	if err := os.MkdirAll(p.cfg.DumpPath, 0755); err != nil {
+		return 0, err
+	}
+	generations, err := p.listGenerations()
+	if err != nil {
+		return 0, err
+	}
+	var generation uint64 = 1
+	if len(generations) > 0 {
+		generation = generations[0] + 1
+	}
+
+	view := p.segmentView()
+	if err = p.writeGeneration(generation, view); err != nil {
+		// 本次dump包含的文档留待下一次dump确认
+		p.acksMu.Lock()
+		p.acks = append(view.acks, p.acks...)
+		p.acksMu.Unlock()
+		return 0, err
+	}
+	for _, ack := range view.acks {
+		ack.Done()
+	}
+
+	atomic.AddUint64(&(p.mutations), ^(view.mutations - 1))
+	if p.wal != nil {
+		if err = p.wal.truncate(view.walLSN); err != nil {
+			log.Warn().Err(err).Msg("cannot truncate wal")
+		}
+	}
+	p.pruneDumps(append([]uint64{generation}, generations...))
+	return generation, nil
+}
+
+// writeGeneration 将视图编码为段文件, 连同清单一起写入新的一代.
+func (p *PipeIndexProcessor) writeGeneration(generation uint64, view *segmentView) error {
+	data := encodeSegment(view)
+
+	tmp, err := ioutil.TempDir(p.cfg.DumpPath, _TempDirPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp) // nolint
+
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	aData, err := json.Marshal(view.analyzer)
+	if err != nil {
+		return err
+	}
+	m := &manifest{
+		Generation:  generation,
+		CreatedAt:   time.Now().Unix(),
+		WALSequence: view.walLSN,
+		Files:       make([]manifestFile, 0, 2),
+	}
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{_SegmentFile, data}, {_AnalyzerFile, aData}} {
+		if err = writeFileSync(filepath.Join(tmp, f.name), f.data); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(f.data)
+		m.Files = append(m.Files, manifestFile{Name: f.name, Size: int64(len(f.data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+	mData, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err = writeFileSync(filepath.Join(tmp, _ManifestFile), mData); err != nil {
+		return err
+	}
+	if err = syncDir(tmp); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, p.fGeneration(generation)); err != nil {
+		return err
+	}
+	if err = syncDir(p.cfg.DumpPath); err != nil {
+		return err
+	}
+	log.Info().Msgf("dump generation=%d to path=%s, size=%d", generation, p.fGeneration(generation), len(data))
+	return nil
+}
+
+// Load 从存储硬件加载最新的一代有效dump, 校验失败的代会被跳过.
+// 没有任何一代时依次尝试旧版本的段文件与JSON索引文件, 并将其迁移为新的一代.
+// 开启预写日志时, 在dump之上重放尚未被dump包含的记录, 之后的写入追加到新的日志文件.
+func (p *PipeIndexProcessor) Load() error {
+	lsn, err := p.loadDump()
+	if err != nil {
+		return err
+	}
+	if !p.cfg.EnableWAL {
+		return nil
+	}
+	return p.recoverWAL(lsn)
+}
+
+// loadDump 加载dump, 返回dump已包含的预写日志的最大序列号.
+func (p *PipeIndexProcessor) loadDump() (uint64, error) {
+	if !utils.FileExist(p.cfg.DumpPath) {
+		return 0, nil
+	}
+	generations, err := p.listGenerations()
+	if err != nil {
+		return 0, err
+	}
+	for _, generation := range generations {
+		lsn, err := p.loadGeneration(generation)
+		if err != nil {
+			log.Warn().Err(err).Msgf("skip broken dump, generation=%d", generation)
+			continue
+		}
+		log.Info().Msgf("load dump, generation=%d, wal_sequence=%d", generation, lsn)
+		return lsn, nil
+	}
+	if len(generations) > 0 {
+		return 0, fmt.Errorf("%w: path=%s", ErrNoValidDump, p.cfg.DumpPath)
+	}
+
+	if utils.FileExist(p.fLegacySegment()) {
+		if err = p.loadLegacySegment(); err != nil {
+			return 0, err
+		}
+		_, err = p.Dump()
+		return 0, err
+	}
+	if utils.FileExist(p.fMetadata()) {
+		return 0, p.MigrateJSONDump()
+	}
+	return 0, nil
+}
+
+// loadGeneration 校验并加载指定的一代dump, 返回其包含的预写日志的最大序列号.
+func (p *PipeIndexProcessor) loadGeneration(generation uint64) (uint64, error) {
+	dir := p.fGeneration(generation)
+	mData, err := ioutil.ReadFile(filepath.Join(dir, _ManifestFile))
+	if err != nil {
+		return 0, err
+	}
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	m := new(manifest)
+	if err = json.Unmarshal(mData, m); err != nil {
+		return 0, fmt.Errorf("bad manifest: %w", err)
+	}
+
+	var data, aData []byte
+	for _, f := range m.Files {
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name))
+		if err != nil {
+			return 0, err
+		}
+		sum := sha256.Sum256(content)
+		if int64(len(content)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return 0, fmt.Errorf("checksum mismatch, file=%s", f.Name)
+		}
+		switch f.Name {
+		case _SegmentFile:
+			data = content
+		case _AnalyzerFile:
+			aData = content
+		}
+	}
+	if data == nil {
+		return 0, fmt.Errorf("missing %s in manifest", _SegmentFile)
+	}
+	if err = p.decodeSegment(data); err != nil {
+		return 0, err
+	}
+	if aData != nil {
+		// 早期的dump不含分析器状态
+		state := new(analyzerState)
+		if err = json.Unmarshal(aData, state); err != nil {
+			return 0, fmt.Errorf("bad %s: %w", _AnalyzerFile, err)
+		}
+		p.restoreAnalyzerState(state)
+	}
+	return m.WALSequence, nil
+}
+
+// listGenerations 按代数降序列出DumpPath下的所有dump.
+func (p *PipeIndexProcessor) listGenerations() ([]uint64, error) {
+	entries, err := ioutil.ReadDir(p.cfg.DumpPath)
+	if err != nil {
+		return nil, err
+	}
+	generations := make([]uint64, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), _GenerationPrefix) {
+			continue
+		}
+		generation, err := strconv.ParseUint(strings.TrimPrefix(entry.Name(), _GenerationPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		generations = append(generations, generation)
+	}
+	sort.Slice(generations, func(i, j int) bool {
+		return generations[i] > generations[j]
+	})
+	return generations, nil
+}
+
+// pruneDumps 淘汰超出保留代数的旧dump, 并清理崩溃时遗留的临时目录.
+func (p *PipeIndexProcessor) pruneDumps(generations []uint64) {
+	retained := p.cfg.RetainedDumps
+	if retained <= 0 {
+		retained = _DefaultRetainedDumps
+	}
+	for i := retained; i < len(generations); i++ {
+		if err := os.RemoveAll(p.fGeneration(generations[i])); err != nil {
+			log.Warn().Err(err).Msgf("cannot remove dump, generation=%d", generations[i])
+		}
+	}
+
+	entries, err := ioutil.ReadDir(p.cfg.DumpPath)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), _TempDirPrefix) {
+			os.RemoveAll(filepath.Join(p.cfg.DumpPath, entry.Name())) // nolint
+		}
+	}
+}
+
+func (p *PipeIndexProcessor) fGeneration(generation uint64) string {
+	return filepath.Join(p.cfg.DumpPath, fmt.Sprintf("%s%010d", _GenerationPrefix, generation))
+}
+
+// writeFileSync 写入文件并落盘.
+func writeFileSync(fn string, data []byte) error {
+	fw, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write(data); err != nil {
+		fw.Close() // nolint
+		return err
+	}
+	if err = fw.Sync(); err != nil {
+		fw.Close() // nolint
+		return err
+	}
+	return fw.Close()
+}
+
+// syncDir 将目录项落盘, 保证新建或重命名的文件在崩溃后依然可见.
+func syncDir(dir string) error {
+	fd, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	return fd.Sync()
+}
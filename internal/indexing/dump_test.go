@@ -0,0 +1,114 @@
+package indexing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func TestDumpGenerations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	cfg := &conf.IndexerConfig{DumpPath: filepath.Join(dir, "indexing"), RetainedDumps: 2}
+
+	p := newTestIndexer(cfg)
+	for id := 1; id <= 3; id++ {
+		assert.Empty(t, p.indexing(newTestWrapper(fmt.Sprintf("%d", id), "收入 保险"), nil))
+		assert.Equal(t, uint64(1), p.DocsSinceDump())
+		generation, err := p.Dump()
+		assert.Empty(t, err)
+		assert.Equal(t, uint64(id), generation)
+		assert.Equal(t, uint64(0), p.DocsSinceDump())
+	}
+	// 模拟dump期间崩溃遗留的临时目录
+	assert.Empty(t, os.Mkdir(filepath.Join(cfg.DumpPath, ".tmp-crashed"), 0755))
+
+	generations, err := p.listGenerations()
+	assert.Empty(t, err)
+	assert.Equal(t, []uint64{3, 2}, generations)
+
+	q := newTestIndexer(cfg)
+	assert.Empty(t, q.Load())
+	assert.Equal(t, uint64(3), q.GetDoc())
+
+	// 最新一代损坏时回退到上一代
+	seg := filepath.Join(cfg.DumpPath, "gen-0000000003", "index.seg")
+	data, err := ioutil.ReadFile(seg)
+	assert.Empty(t, err)
+	data[len(data)-1] ^= 0xff
+	assert.Empty(t, ioutil.WriteFile(seg, data, 0644))
+	q = newTestIndexer(cfg)
+	assert.Empty(t, q.Load())
+	assert.Equal(t, uint64(2), q.GetDoc())
+
+	// 新的一代不会复用已有的代数, 遗留的临时目录被清理
+	_, err = q.Dump()
+	assert.Empty(t, err)
+	generations, err = q.listGenerations()
+	assert.Empty(t, err)
+	assert.Equal(t, []uint64{4, 3}, generations)
+	_, err = os.Stat(filepath.Join(cfg.DumpPath, ".tmp-crashed"))
+	assert.Equal(t, true, os.IsNotExist(err))
+
+	// 所有代均损坏时报错
+	assert.Empty(t, os.Remove(filepath.Join(cfg.DumpPath, "gen-0000000004", "MANIFEST")))
+	q = newTestIndexer(cfg)
+	err = q.Load()
+	assert.Equal(t, true, errors.Is(err, ErrNoValidDump))
+}
+
+func TestLoadWithoutDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+
+	p := newTestIndexer(&conf.IndexerConfig{DumpPath: filepath.Join(dir, "not-exist")})
+	assert.Empty(t, p.Load())
+	p = newTestIndexer(&conf.IndexerConfig{DumpPath: dir})
+	assert.Empty(t, p.Load())
+	assert.Equal(t, uint64(0), p.GetDoc())
+}
+
+func TestAckAfterDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+
+	var acked []string
+	newDoc := func(docID string) *common.Document {
+		return &common.Document{
+			Concordance: newTestWrapper(docID, "收入 保险"),
+			Ack:         common.NewAck(func() { acked = append(acked, docID) }, func(string, error) {}),
+		}
+	}
+
+	// 未开启预写日志时, 文档在dump之后才被确认
+	p := newTestIndexer(&conf.IndexerConfig{DumpPath: filepath.Join(dir, "dump")})
+	_, err = p.Process(context.Background(), newDoc("1"))
+	assert.Empty(t, err)
+	_, err = p.Process(context.Background(), newDoc("2"))
+	assert.Empty(t, err)
+	assert.Empty(t, acked)
+	_, err = p.Dump()
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"1", "2"}, acked)
+
+	// 开启预写日志时, 文档写入之后立即被确认
+	acked = nil
+	q := newTestIndexer(&conf.IndexerConfig{DumpPath: filepath.Join(dir, "wal"), EnableWAL: true})
+	assert.Empty(t, q.Load())
+	_, err = q.Process(context.Background(), newDoc("3"))
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"3"}, acked)
+	assert.Empty(t, q.CloseWAL())
+}
@@ -1,47 +1,84 @@
 package indexing
 
 import (
-	"container/heap"
+	"context"
+	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"math"
-	"path/filepath"
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	jsoniter "github.com/json-iterator/go"
 	"github.com/rs/zerolog/log"
 
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
 	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stopword"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
-	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
 )
 
 const (
-	// 支持的文档总量上限
-	_DocCapacity uint64 = 1e4
-	// 支持的词汇总量上限
-	_VocabularyCapacity uint64 = 1e5
-
 	_Shards = 32
 
-	_BitPerWord uint64 = 64
-	_Shift      uint64 = 6
-	_Mask       uint64 = 0x3f
+	// 默认保留的快照个数
+	_DefaultRetainedSnapshots = 4
+
+	_Shift uint64 = 6
+	_Mask  uint64 = 0x3f
+)
+
+var (
+	// ErrInvalidDocID 文档ID不合法错误
+	ErrInvalidDocID = errors.New("invalid doc id")
+	// ErrDocCapacityExceeded 文档总量超出上限错误
+	ErrDocCapacityExceeded = errors.New("doc capacity exceeded")
+	// ErrVocabularyCapacityExceeded 词汇总量超出上限错误
+	ErrVocabularyCapacityExceeded = errors.New("vocabulary capacity exceeded")
+	// ErrSnapshotExpired 快照已被淘汰错误
+	ErrSnapshotExpired = errors.New("snapshot expired")
 )
 
 // PipeIndexProcessor 索引器
-// 目前规划最大支持10万词汇量, 1万文档.
+// 文档总量与词汇总量的上限由IndexerConfig决定, 为0时不设上限.
 type PipeIndexProcessor struct {
-	cfg         *conf.IndexerConfig
-	tokenBucket chan struct{}
-	indexer     *InvertedIndex
-	tfidf       *TFIDF
-	storage     storage.Persister
-	available   int32
+	cfg     *conf.IndexerConfig
+	indexer *InvertedIndex
+	// 已发布的TF-IDF快照, 查询只访问快照, 与写入互不干扰
+	snapshot   atomic.Value
+	generation uint64
+	// 进程启动时随机生成的纪元, 快照代数只在同一纪元内唯一
+	epoch uint64
+	// 最近发布的若干份快照, 用于分页查询时定位令牌对应的快照
+	historyMu sync.RWMutex
+	history   []*TFIDF
+	// 写入单个文档时持读锁, 冻结快照时持写锁, 保证快照不会看到写了一半的文档
+	commitMu sync.RWMutex
+	// 按文档ID分段的锁, 保证同一文档的写入、替换与删除串行执行
+	docMu [_Shards]sync.Mutex
+	// 正排索引, 记录每个文档的序号及其词条, 用于删除文档时定位倒排记录
+	docsMu sync.RWMutex
+	docs   map[string]*docEntry
+	// 保证同一时刻只有一个dump在进行
+	dumpMu sync.Mutex
+	// 自上次dump以来成功执行的写入次数 (新增、替换与删除文档)
+	mutations uint64
+	// 预写日志, 未开启时为nil
+	wal *writeAheadLog
+	// 已写入倒排索引但尚未持久化的文档的确认句柄, 在下一次dump成功之后确认
+	acksMu sync.Mutex
+	acks   []*common.Ack
+	// 处理文档使用的停词表, 未设置时为nil
+	stopWords *stopword.Lists
+	// 最近写入的文档去停词时使用的停词表版本, 构造快照时按该版本记录停词表
+	stopWordsVersion atomic.Value
+	// 已注册的排序函数
+	scorers       map[string]Scorer
+	defaultScorer string
+	storage       storage.Persister
+	available     int32
 }
 
 // InvertedIndex 倒排索引数据结构
@@ -51,11 +88,15 @@ type InvertedIndex struct {
 }
 
 // Metadata 倒排索引数据结构的元数据
+// Doc与Vocabulary为当前的文档总量与词汇总量, LastDocIdx与LastTermID为已分配的最大序号,
+// 删除文档或词条之后序号不会被复用.
 type Metadata struct {
 	Doc              uint64           `json:"doc"`
 	DocStore         *DocStore        `json:"doc_store"`
+	LastDocIdx       uint64           `json:"last_doc_idx"`
 	Vocabulary       uint64           `json:"vocabulary"`
 	VocabularyStore  *VocabularyStore `json:"vocabulary_store"`
+	LastTermID       uint64           `json:"last_term_id"`
 	MaxTermFrequency uint64           `json:"max_term_frequency"`
 }
 
@@ -63,12 +104,16 @@ type Metadata struct {
 type Shard struct {
 	mu      sync.RWMutex
 	Backend map[string]*PostingList `json:"backend"`
+	// 最近一次冻结的只读副本, 分段被修改之后置为nil
+	frozen map[string]*TermPostings
 }
 
-// DocStore 用于存储文档记录
+// DocStore 用于存储文档记录.
+// 文档ID可以是任意的uint64, 因此按文档ID稀疏存储, 内存占用只与文档总量有关,
+// 文档在倒排索引中使用的是稠密分配的文档序号.
 type DocStore struct {
-	mu     sync.RWMutex
-	BitSet [][]byte `json:"bit_set"`
+	mu   sync.RWMutex
+	docs map[string]struct{}
 }
 
 // VocabularyStore 用于存储词汇量记录
@@ -82,49 +127,36 @@ type PostingList struct {
 	TermID       string   `json:"term_id"`
 	DocFrequency uint64   `json:"doc_frequency"`
 	Postings     *Posting `json:"postings"`
+	// 最近一次冻结的只读副本, 倒排列表被修改之后置为nil
+	frozen *TermPostings
 }
 
 // Posting 信息单元
 type Posting struct {
-	TermFrequency uint64   `json:"term_frequency"`
-	DocIdx        uint64   `json:"doc_idx"`
-	DocID         string   `json:"doc_id"`
-	Next          *Posting `json:"next"`
-}
-
-// TFIDF TF-IDF数据结构
-type TFIDF struct {
-	Vectors []*DocVector
-}
-
-// DocVector 文档向量
-type DocVector struct {
-	DocID string
-	Space []float32
-}
-
-// QueryVector 查询向量
-type QueryVector struct {
-	Space []float32
+	TermFrequency uint64 `json:"term_frequency"`
+	DocIdx        uint64 `json:"doc_idx"`
+	DocID         string `json:"doc_id"`
+	// 词条在文档中出现的位置, 升序排列
+	Positions []uint32 `json:"positions,omitempty"`
+	Next      *Posting `json:"next"`
 }
 
-// SimilarObject 相似文档记录
-type SimilarObject struct {
-	DocID      string
-	Similarity float64
-	Index      int
+// docEntry 正排索引记录
+type docEntry struct {
+	idx   uint64
+	terms []string
+	// 文档分词时使用的词典版本
+	dictVersion string
 }
 
-// PriorityQueue 用于筛选TopK文档的优先队列
-type PriorityQueue []*SimilarObject
-
 // NewPipeIndexProcessor 新建索引器.
 func NewPipeIndexProcessor(cfg *conf.IndexerConfig, storage storage.Persister) *PipeIndexProcessor {
 	p := &PipeIndexProcessor{
-		cfg:         cfg,
-		tokenBucket: make(chan struct{}, 20),
-		storage:     storage,
-		available:   1,
+		cfg:       cfg,
+		storage:   storage,
+		available: 1,
+		docs:      make(map[string]*docEntry),
+		epoch:     newEpoch(),
 	}
 	p.indexer = &InvertedIndex{}
 	p.indexer.Metadata = &Metadata{
@@ -132,179 +164,321 @@ func NewPipeIndexProcessor(cfg *conf.IndexerConfig, storage storage.Persister) *
 		Vocabulary:       0,
 		MaxTermFrequency: 0,
 	}
-	p.indexer.Metadata.DocStore = &DocStore{BitSet: make([][]byte, uint64(_DocCapacity/_BitPerWord)+1)}
-	for idx := range p.indexer.Metadata.DocStore.BitSet {
-		p.indexer.Metadata.DocStore.BitSet[idx] = make([]byte, 8)
-	}
-	p.indexer.Metadata.VocabularyStore = &VocabularyStore{BitSet: make([][]byte, uint64(_VocabularyCapacity/_BitPerWord)+1)}
-	for idx := range p.indexer.Metadata.VocabularyStore.BitSet {
-		p.indexer.Metadata.VocabularyStore.BitSet[idx] = make([]byte, 8)
-	}
+	// 词汇位图按需扩容, 这里只做初始分配
+	p.indexer.Metadata.DocStore = newDocStore()
+	p.indexer.Metadata.VocabularyStore = &VocabularyStore{BitSet: make([][]byte, 0)}
 	p.indexer.Dict = make([]*Shard, _Shards)
 	for idx := 0; idx < _Shards; idx++ {
 		p.indexer.Dict[idx] = &Shard{
 			Backend: make(map[string]*PostingList),
 		}
 	}
+	p.publish(newEmptyTFIDF())
+	p.scorers = make(map[string]Scorer)
+	for _, name := range []string{RankingTFIDF, RankingBM25, RankingBM25F, RankingLMDirichlet} {
+		scorer, err := NewScorer(name, cfg)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("invalid ranking function config, ranking=%s", name)
+		}
+		p.scorers[name] = scorer
+	}
+	p.defaultScorer = _DefaultRankingFunction
+	if cfg.Ranking != "" {
+		if _, ok := p.scorers[cfg.Ranking]; !ok {
+			log.Fatal().Err(ErrUnknownRanking).Msgf("invalid default ranking function, ranking=%s", cfg.Ranking)
+		}
+		p.defaultScorer = cfg.Ranking
+	}
 	log.Info().Msg("load PipeIndexProcessor plugin")
 	return p
 }
 
-// TermsIndexing 为词条建立索引结构.
-func (p *PipeIndexProcessor) TermsIndexing(pGroup *sync.WaitGroup, input common.ConcordanceChannel) {
-	pGroup.Add(1)
-LOOP_LABEL:
-	for {
-		select {
-		case packet, ok := <-input:
-			{
-				if !ok {
-					break LOOP_LABEL
-				}
-				go p.indexing(packet)
-			}
-		default:
-			{
+// UseStopWords 设置处理文档使用的停词表, 之后构造的快照记录快照中的文档去停词时使用的停词表,
+// 查询语句按其移除停词. 需在构造快照之前调用.
+func (p *PipeIndexProcessor) UseStopWords(lists *stopword.Lists) {
+	p.stopWords = lists
+}
 
-			}
-		}
+// newEpoch 随机生成纪元, 取不到随机数时退化为当前时间.
+func newEpoch() uint64 {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return uint64(time.Now().UnixNano())
 	}
-	pGroup.Done()
-	log.Info().Msg("unload PipeIndexProcessor plugin")
+	return binary.BigEndian.Uint64(buf)
 }
 
-func (p *PipeIndexProcessor) indexing(packet *common.ConcordanceWrapper) {
-	p.tokenBucket <- struct{}{}
+// Process 为文档的词条建立索引结构, 文档不再交给下游 (并发安全).
+func (p *PipeIndexProcessor) Process(ctx context.Context, doc *common.Document) (*common.Document, error) {
+	return nil, p.indexing(doc.Concordance, doc.Ack)
+}
+
+// indexing 为文档建立索引, 文档持久化之后通过ack确认, ack可以为nil.
+func (p *PipeIndexProcessor) indexing(packet *common.ConcordanceWrapper, ack *common.Ack) error {
+	p.commitMu.RLock()
+	defer p.commitMu.RUnlock()
+
+	if err := validateDocID(packet.DocID); err != nil {
+		return err
+	}
+
+	docMu := &(p.docMu[fnv_1a_32(packet.DocID)&0x1f])
+	docMu.Lock()
+	defer docMu.Unlock()
+
+	// 先写预写日志再修改倒排索引
+	if p.wal != nil {
+		if _, err := p.wal.append(packet); err != nil {
+			return fmt.Errorf("cannot append wal: %w", err)
+		}
+	}
+	if err := p.apply(packet); err != nil {
+		return err
+	}
+	p.commitAck(ack)
+	return nil
+}
 
-	if p.indexer.Metadata.DocStore.exist(packet.DocID) {
+// commitAck 在文档持久化之后确认: 开启预写日志时写入即已落盘, 否则等待下一次dump.
+// 调用方需持有commitMu的读锁, 保证句柄不会被早于该文档的视图取走.
+func (p *PipeIndexProcessor) commitAck(ack *common.Ack) {
+	if ack == nil {
+		return
+	}
+	if p.wal != nil {
+		ack.Done()
 		return
 	}
-	p.indexer.Metadata.DocStore.set(packet.DocID)
-	atomic.AddUint64(&(p.indexer.Metadata.Doc), 1)
+	p.acksMu.Lock()
+	p.acks = append(p.acks, ack)
+	p.acksMu.Unlock()
+}
+
+// validateDocID 校验文档ID, 文档ID需为uint64范围内的十进制整数.
+func validateDocID(docID string) error {
+	if _, err := strconv.ParseUint(docID, 10, 64); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDocID, docID)
+	}
+	return nil
+}
+
+// apply 将一次写入作用于倒排索引.
+// 调用方需持有commitMu的读锁以及文档对应的docMu, 或保证没有并发的写入.
+func (p *PipeIndexProcessor) apply(packet *common.ConcordanceWrapper) error {
+	if err := validateDocID(packet.DocID); err != nil {
+		return err
+	}
+	if packet.Operation == pb.DocOperation_DeleteDoc {
+		if !p.deleteDoc(packet.DocID) {
+			log.Warn().Msgf("doc to delete not found, doc_id=%s", packet.DocID)
+			return nil
+		}
+		atomic.AddUint64(&(p.mutations), 1)
+		return nil
+	}
+	if p.cfg.VocabularyCapacity > 0 &&
+		p.GetVocabulary()+p.countNewTerms(packet.Concordance) > p.cfg.VocabularyCapacity {
+		return fmt.Errorf("%w: capacity=%d", ErrVocabularyCapacityExceeded, p.cfg.VocabularyCapacity)
+	}
+	var docIdx uint64
+	if packet.Operation == pb.DocOperation_UpsertDoc && p.indexer.Metadata.DocStore.exist(packet.DocID) {
+		// 替换文档时文档总量不变, 先占用新的文档序号, 再删除旧的倒排记录并按新内容重新建立索引,
+		// 从而不会因为容量上限而丢失旧的文档
+		docIdx = p.reserveDoc(true)
+		p.deleteDoc(packet.DocID)
+		p.indexer.Metadata.DocStore.testAndSet(packet.DocID)
+	} else {
+		if !p.indexer.Metadata.DocStore.testAndSet(packet.DocID) {
+			return nil
+		}
+		if !p.admitDoc() {
+			p.indexer.Metadata.DocStore.clear(packet.DocID)
+			return fmt.Errorf("%w: capacity=%d", ErrDocCapacityExceeded, p.cfg.DocCapacity)
+		}
+		docIdx = p.reserveDoc(false)
+	}
 
+	terms := make([]string, 0, len(packet.Concordance))
 	for term, freq := range packet.Concordance {
 		shard := p.indexer.Dict[fnv_1a_32(term)&0x1f]
 		shard.mu.Lock()
+		shard.frozen = nil
 
-		docIdx := p.GetDoc()
-
-		_, ok := shard.Backend[term]
-		if ok {
-			cur := shard.Backend[term].Postings
-			inserted := false
-			for ; cur.Next != nil; cur = cur.Next {
-				if freq > cur.Next.TermFrequency {
-					tmp := cur.Next
-					cur.Next = &Posting{
-						TermFrequency: freq,
-						DocIdx:        docIdx,
-						DocID:         packet.DocID,
-						Next:          nil,
-					}
-					cur.Next.Next = tmp
-					inserted = true
-					break
-				}
+		if pl, ok := shard.Backend[term]; ok {
+			pl.frozen = nil
+			// 按词频降序插入
+			cur := pl.Postings
+			for cur.Next != nil && cur.Next.TermFrequency >= freq {
+				cur = cur.Next
 			}
-			if !inserted {
-				cur.Next = &Posting{
-					TermFrequency: freq,
-					DocIdx:        docIdx,
-					DocID:         packet.DocID,
-					Next:          nil,
-				}
+			cur.Next = &Posting{
+				TermFrequency: freq,
+				DocIdx:        docIdx,
+				DocID:         packet.DocID,
+				Positions:     packet.Positions[term],
+				Next:          cur.Next,
 			}
-			shard.Backend[term].Postings = cur
-			shard.Backend[term].DocFrequency++
+			pl.DocFrequency++
 		} else {
-			atomic.AddUint64(&(p.indexer.Metadata.Vocabulary), 1)
-			termID := fmt.Sprintf("%010d", p.GetVocabulary())
+			termIdx, err := p.reserveVocabulary()
+			if err != nil {
+				// 并发写入时可能越过预检查, 此时只丢弃新词条
+				shard.mu.Unlock()
+				log.Warn().Err(err).Msgf("drop term of doc, doc_id=%s", packet.DocID)
+				continue
+			}
+			termID := fmt.Sprintf("%010d", termIdx)
 			p.indexer.Metadata.VocabularyStore.set(termID)
 
 			shard.Backend[term] = &PostingList{
 				TermID:       termID,
 				DocFrequency: 1,
 				Postings: &Posting{
-					Next: nil,
+					Next: &Posting{
+						TermFrequency: freq,
+						DocIdx:        docIdx,
+						DocID:         packet.DocID,
+						Positions:     packet.Positions[term],
+						Next:          nil,
+					},
 				},
 			}
-			shard.Backend[term].Postings.Next = &Posting{
-				TermFrequency: freq,
-				DocIdx:        docIdx,
-				DocID:         packet.DocID,
-				Next:          nil,
-			}
 		}
 
 		shard.mu.Unlock()
-		p.indexer.Dict[fnv_1a_32(term)&0x1f] = shard
+		terms = append(terms, term)
 	}
 
-	<-p.tokenBucket
+	p.docsMu.Lock()
+	p.docs[packet.DocID] = &docEntry{idx: docIdx, terms: terms, dictVersion: packet.DictVersion}
+	p.docsMu.Unlock()
+	if packet.StopWordsVersion != "" {
+		p.stopWordsVersion.Store(packet.StopWordsVersion)
+	}
+	atomic.AddUint64(&(p.mutations), 1)
+
+	return nil
 }
 
-// Dump 将索引结构持久化到存储硬件.
-func (p *PipeIndexProcessor) Dump() {
-	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+// deleteDoc 删除文档的全部倒排记录, 倒排列表为空的词条一并删除. 文档不存在时返回false.
+// 调用方需持有commitMu的读锁以及文档对应的docMu.
+func (p *PipeIndexProcessor) deleteDoc(docID string) bool {
+	if !p.indexer.Metadata.DocStore.exist(docID) {
+		return false
+	}
 
-	// dump metadata
-	metadata, err := json.Marshal(p.indexer.Metadata)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot dump metadata")
+	p.docsMu.Lock()
+	entry, ok := p.docs[docID]
+	delete(p.docs, docID)
+	p.docsMu.Unlock()
+
+	if ok {
+		for _, term := range entry.terms {
+			shard := p.indexer.Dict[fnv_1a_32(term)&0x1f]
+			shard.mu.Lock()
+			if pl, ok := shard.Backend[term]; ok {
+				shard.frozen, pl.frozen = nil, nil
+				for cur := pl.Postings; cur.Next != nil; cur = cur.Next {
+					if cur.Next.DocIdx == entry.idx {
+						cur.Next = cur.Next.Next
+						pl.DocFrequency--
+						break
+					}
+				}
+				if pl.DocFrequency == 0 {
+					delete(shard.Backend, term)
+					p.indexer.Metadata.VocabularyStore.clear(pl.TermID)
+					atomic.AddUint64(&(p.indexer.Metadata.Vocabulary), ^uint64(0))
+				}
+			}
+			shard.mu.Unlock()
+		}
 	}
-	if err = ioutil.WriteFile(p.fMetadata(), metadata, 0644); err != nil {
-		log.Fatal().Err(err).Msg("cannot dump metadata")
+
+	p.indexer.Metadata.DocStore.clear(docID)
+	atomic.AddUint64(&(p.indexer.Metadata.Doc), ^uint64(0))
+	return true
+}
+
+// countNewTerms 统计concordance中尚未被索引的词条数.
+func (p *PipeIndexProcessor) countNewTerms(concordance map[string]uint64) uint64 {
+	var n uint64
+	for term := range concordance {
+		shard := p.indexer.Dict[fnv_1a_32(term)&0x1f]
+		shard.mu.RLock()
+		if _, ok := shard.Backend[term]; !ok {
+			n++
+		}
+		shard.mu.RUnlock()
 	}
-	log.Info().Msgf("dump metadata to file=%s", p.fMetadata())
+	return n
+}
 
-	// 分段dump dict
-	for idx, shard := range p.indexer.Dict {
-		pDict, err := json.Marshal(shard)
-		if err != nil {
-			log.Fatal().Err(err).Msgf("cannot dump %d-term-indexing", idx)
+// admitDoc 在文档总量未达到上限时计入一个文档, 达到上限时返回false.
+func (p *PipeIndexProcessor) admitDoc() bool {
+	for {
+		n := p.GetDoc()
+		if p.cfg.DocCapacity > 0 && n >= p.cfg.DocCapacity {
+			return false
 		}
-		if err = ioutil.WriteFile(p.fPartialDict(idx), pDict, 0644); err != nil {
-			log.Fatal().Err(err).Msgf("cannot dump %d-term-indexing", idx)
+		if atomic.CompareAndSwapUint64(&(p.indexer.Metadata.Doc), n, n+1) {
+			return true
 		}
-		log.Info().Msgf("dump %d-term-indexing to file=%s", idx, p.fMetadata())
 	}
 }
 
-// Load 从存储硬件加载索引结构.
-func (p *PipeIndexProcessor) Load() {
-	if utils.FileExist(p.cfg.DumpPath) {
-		var json = jsoniter.ConfigCompatibleWithStandardLibrary
+// reserveDoc 占用一个文档序号. replacing为true时文档替换同ID的旧文档, 不受容量上限约束,
+// 文档总量在删除旧文档时扣回; 否则调用方需先通过admitDoc计入文档总量.
+func (p *PipeIndexProcessor) reserveDoc(replacing bool) uint64 {
+	if replacing {
+		atomic.AddUint64(&(p.indexer.Metadata.Doc), 1)
+	}
+	return atomic.AddUint64(&(p.indexer.Metadata.LastDocIdx), 1)
+}
 
-		// load metadata
-		metadata, err := ioutil.ReadFile(p.fMetadata())
-		if err != nil {
-			log.Fatal().Err(err).Msg("cannot load metadata")
+// reserveVocabulary 占用一个词条序号, 超出上限时返回ErrVocabularyCapacityExceeded.
+func (p *PipeIndexProcessor) reserveVocabulary() (uint64, error) {
+	for {
+		n := p.GetVocabulary()
+		if p.cfg.VocabularyCapacity > 0 && n >= p.cfg.VocabularyCapacity {
+			return 0, fmt.Errorf("%w: capacity=%d", ErrVocabularyCapacityExceeded, p.cfg.VocabularyCapacity)
 		}
-		if err = json.Unmarshal(metadata, p.indexer.Metadata); err != nil {
-			log.Fatal().Err(err).Msg("cannot load metadata")
+		if atomic.CompareAndSwapUint64(&(p.indexer.Metadata.Vocabulary), n, n+1) {
+			return atomic.AddUint64(&(p.indexer.Metadata.LastTermID), 1), nil
 		}
-		log.Info().Msgf("load metadata from file=%s", p.fMetadata())
+	}
+}
 
-		// 分段load metadata
-		for idx := range p.indexer.Dict {
-			pDict, err := ioutil.ReadFile(p.fPartialDict(idx))
-			if err != nil {
-				log.Fatal().Err(err).Msgf("cannot load %d-term-indexing", idx)
-			}
-			if err = json.Unmarshal(pDict, p.indexer.Dict[idx]); err != nil {
-				log.Fatal().Err(err).Msgf("cannot load %d-term-indexing", idx)
+// rebuildDocs 根据倒排索引重建正排索引以及文档记录, docIDs为文档序号到文档ID的映射,
+// 用于恢复没有任何词条的文档, 可以为nil.
+func (p *PipeIndexProcessor) rebuildDocs(docIDs map[uint64]string) {
+	p.docsMu.Lock()
+	defer p.docsMu.Unlock()
+
+	p.docs = make(map[string]*docEntry, len(docIDs))
+	for docIdx, docID := range docIDs {
+		p.docs[docID] = &docEntry{idx: docIdx, terms: make([]string, 0)}
+	}
+	for _, shard := range p.indexer.Dict {
+		shard.mu.RLock()
+		for term, pl := range shard.Backend {
+			for cur := pl.Postings.Next; cur != nil; cur = cur.Next {
+				entry, ok := p.docs[cur.DocID]
+				if !ok {
+					entry = &docEntry{idx: cur.DocIdx, terms: make([]string, 0)}
+					p.docs[cur.DocID] = entry
+				}
+				entry.terms = append(entry.terms, term)
 			}
-			log.Info().Msgf("load %d-term-indexing from file=%s", idx, p.fMetadata())
 		}
+		shard.mu.RUnlock()
 	}
-}
-
-func (p *PipeIndexProcessor) fMetadata() string {
-	return filepath.Join(p.cfg.DumpPath, "metadata.json")
-}
 
-func (p *PipeIndexProcessor) fPartialDict(i int) string {
-	return filepath.Join(p.cfg.DumpPath, fmt.Sprintf("term-indexing-%d.json", i))
+	store := newDocStore()
+	for docID := range p.docs {
+		store.docs[docID] = struct{}{}
+	}
+	p.indexer.Metadata.DocStore = store
 }
 
 // MarkServiceAvailable 将服务标记为可用.
@@ -322,115 +496,26 @@ func (p *PipeIndexProcessor) ServiceAvailable() bool {
 	return atomic.LoadInt32(&(p.available)) == 1
 }
 
-// BuildTFIDF 构造TF-IDF数据结构.
-func (p *PipeIndexProcessor) BuildTFIDF() {
-	log.Info().Msg("start to build tf-idf ...")
-	p.tfidf = &TFIDF{
-		Vectors: make([]*DocVector, p.GetDoc()),
+// Scorer 根据名称返回排序函数, 名称为空时返回默认排序函数.
+func (p *PipeIndexProcessor) Scorer(name string) (Scorer, error) {
+	if name == "" {
+		name = p.defaultScorer
 	}
-	var i uint64
-	for i = 0; i < p.GetDoc(); i++ {
-		p.tfidf.Vectors[i] = &DocVector{
-			Space: make([]float32, p.GetVocabulary()),
-		}
+	scorer, ok := p.scorers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRanking, name)
 	}
-	D := p.GetDoc()
-	for _, shard := range p.indexer.Dict {
-		shard.mu.RLock()
-		for _, pl := range shard.Backend {
-			termIdx, _ := strconv.ParseUint(pl.TermID, 10, 64)
-			for cur := pl.Postings.Next; cur != nil; cur = cur.Next {
-				p.tfidf.Vectors[cur.DocIdx-1].DocID = cur.DocID
-				p.tfidf.Vectors[cur.DocIdx-1].Space[termIdx-1] = float32(cur.TermFrequency) * float32(math.Log2(float64(D)/float64(pl.DocFrequency)))
-			}
-			if cur := pl.Postings.Next; cur != nil {
-				if cur.TermFrequency > p.indexer.Metadata.MaxTermFrequency {
-					p.indexer.Metadata.MaxTermFrequency = cur.TermFrequency
-				}
-			}
-		}
-		shard.mu.RUnlock()
-	}
-	log.Info().Msg("tf-idf has been builded")
+	return scorer, nil
 }
 
-// BuildQueryVector 构造查询向量.
-func (p *PipeIndexProcessor) BuildQueryVector(concordance map[string]uint64) *QueryVector {
-	q := &QueryVector{
-		Space: make([]float32, p.GetVocabulary()),
-	}
-	D := p.GetDoc()
-	for term, freq := range concordance {
-		shard := p.indexer.Dict[fnv_1a_32(term)&0x1f]
-		shard.mu.RLock()
-		if pl, ok := shard.Backend[term]; ok {
-			termIdx, _ := strconv.ParseUint(pl.TermID, 10, 64)
-			q.Space[termIdx-1] = (0.5 + (0.5*float32(freq))/float32(p.indexer.Metadata.MaxTermFrequency)) * float32(math.Log2(float64(D)/float64(pl.DocFrequency)))
-		}
-		shard.mu.RUnlock()
-	}
-	return q
+// DocsSinceDump 返回自上次dump以来成功写入的文档数.
+func (p *PipeIndexProcessor) DocsSinceDump() uint64 {
+	return atomic.LoadUint64(&(p.mutations))
 }
 
-// TopK 计算查询向量与文档向量集合中各个向量的相似度，并返回最相似的k个文档
-func (p *PipeIndexProcessor) TopK(k uint32, q *QueryVector) []string {
-	ret := make([]string, 0, k)
-
-	var qMagnitude float64
-	for _, x := range q.Space {
-		qMagnitude += float64(x) * float64(x)
-	}
-	qMagnitude = math.Sqrt(qMagnitude)
-	if qMagnitude == 0.0 {
-		return ret
-	}
-
-	h := new(PriorityQueue)
-	heap.Init(h)
-
-	var dot float64
-	var dMagnitude float64
-	var similarity float64
-	for _, v := range p.tfidf.Vectors {
-		dot = 0.0
-		dMagnitude = 0.0
-		for i := range v.Space {
-			dot += float64(v.Space[i]) * float64(q.Space[i])
-			dMagnitude += float64(v.Space[i]) * float64(v.Space[i])
-		}
-		dMagnitude = math.Sqrt(dMagnitude)
-		if dMagnitude == 0.0 {
-			continue
-		}
-
-		similarity = dot / (dMagnitude * qMagnitude)
-		if similarity == 0.0 {
-			continue
-		}
-
-		y := &SimilarObject{DocID: v.DocID, Similarity: similarity}
-		if uint32(h.Len()) >= k {
-			x := heap.Pop(h).(*SimilarObject)
-			if x.Similarity < similarity {
-				heap.Push(h, y)
-			} else {
-				heap.Push(h, x)
-			}
-		} else {
-			heap.Push(h, y)
-		}
-	}
-
-	for h.Len() > 0 {
-		ret = append(ret, heap.Pop(h).(*SimilarObject).DocID)
-	}
-
-	return ret
-}
-
-// GetDocCapacity 返回文档总量上限.
+// GetDocCapacity 返回文档总量上限, 0表示不设上限.
 func (p *PipeIndexProcessor) GetDocCapacity() uint64 {
-	return _DocCapacity
+	return p.cfg.DocCapacity
 }
 
 // GetDoc 返回文档总量.
@@ -438,9 +523,9 @@ func (p *PipeIndexProcessor) GetDoc() uint64 {
 	return atomic.LoadUint64(&(p.indexer.Metadata.Doc))
 }
 
-// GetVocabularyCapacity 返回词汇总量上限.
+// GetVocabularyCapacity 返回词汇总量上限, 0表示不设上限.
 func (p *PipeIndexProcessor) GetVocabularyCapacity() uint64 {
-	return _VocabularyCapacity
+	return p.cfg.VocabularyCapacity
 }
 
 // GetVocabulary 返回词汇总量.
@@ -448,74 +533,39 @@ func (p *PipeIndexProcessor) GetVocabulary() uint64 {
 	return atomic.LoadUint64(&(p.indexer.Metadata.Vocabulary))
 }
 
-func (pq PriorityQueue) Len() int {
-	return len(pq)
-}
-
-func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].Similarity < pq[j].Similarity
-}
-
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i].DocID, pq[j].DocID = pq[j].DocID, pq[i].DocID
-	pq[i].Similarity, pq[j].Similarity = pq[j].Similarity, pq[i].Similarity
+func newDocStore() *DocStore {
+	return &DocStore{docs: make(map[string]struct{})}
 }
 
-func (pq *PriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*SimilarObject)
-	item.Index = n
-	*pq = append(*pq, item)
-}
-
-func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	item.Index = -1
-	*pq = old[0 : n-1]
-	return item
-}
-
-func (m *DocStore) set(docID string) {
+// testAndSet 记录文档, 文档此前未被记录时返回true.
+func (m *DocStore) testAndSet(docID string) bool {
 	m.mu.Lock()
-	buf := make([]byte, 8)
-	id, _ := strconv.ParseUint(docID, 10, 64)
-	binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
-	for i := 0; i < 8; i++ {
-		m.BitSet[id>>_Shift][i] = m.BitSet[id>>_Shift][i] | buf[i]
+	defer m.mu.Unlock()
+	if _, ok := m.docs[docID]; ok {
+		return false
 	}
-	m.mu.Unlock()
+	m.docs[docID] = struct{}{}
+	return true
 }
 
-func (m *DocStore) clear(docID string) { // nolint
+func (m *DocStore) clear(docID string) {
 	m.mu.Lock()
-	buf := make([]byte, 8)
-	id, _ := strconv.ParseUint(docID, 10, 64)
-	binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
-	for i := 0; i < 8; i++ {
-		m.BitSet[id>>_Shift][i] = m.BitSet[id>>_Shift][i] & ^(buf[i])
-	}
+	delete(m.docs, docID)
 	m.mu.Unlock()
 }
 
 func (m *DocStore) exist(docID string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	buf := make([]byte, 8)
-	id, _ := strconv.ParseUint(docID, 10, 64)
-	binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
-	var exist bool
-	for i := 0; i < 8; i++ {
-		exist = exist || (m.BitSet[id>>_Shift][i]&buf[i] != 0)
-	}
-	return exist
+	_, ok := m.docs[docID]
+	return ok
 }
 
 func (m *VocabularyStore) set(termID string) {
 	m.mu.Lock()
 	buf := make([]byte, 8)
 	id, _ := strconv.ParseUint(termID, 10, 64)
+	m.BitSet = growBitSet(m.BitSet, id)
 	binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
 	for i := 0; i < 8; i++ {
 		m.BitSet[id>>_Shift][i] = m.BitSet[id>>_Shift][i] | buf[i]
@@ -523,13 +573,15 @@ func (m *VocabularyStore) set(termID string) {
 	m.mu.Unlock()
 }
 
-func (m *VocabularyStore) clear(termID string) { // nolint
+func (m *VocabularyStore) clear(termID string) {
 	m.mu.Lock()
 	buf := make([]byte, 8)
 	id, _ := strconv.ParseUint(termID, 10, 64)
-	binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
-	for i := 0; i < 8; i++ {
-		m.BitSet[id>>_Shift][i] = m.BitSet[id>>_Shift][i] & ^(buf[i])
+	if id>>_Shift < uint64(len(m.BitSet)) {
+		binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
+		for i := 0; i < 8; i++ {
+			m.BitSet[id>>_Shift][i] = m.BitSet[id>>_Shift][i] & ^(buf[i])
+		}
 	}
 	m.mu.Unlock()
 }
@@ -539,6 +591,9 @@ func (m *VocabularyStore) exist(termID string) bool { // nolint
 	defer m.mu.RUnlock()
 	buf := make([]byte, 8)
 	id, _ := strconv.ParseUint(termID, 10, 64)
+	if id>>_Shift >= uint64(len(m.BitSet)) {
+		return false
+	}
 	binary.BigEndian.PutUint64(buf, 1<<(id&_Mask))
 	var exist bool
 	for i := 0; i < 8; i++ {
@@ -546,3 +601,11 @@ func (m *VocabularyStore) exist(termID string) bool { // nolint
 	}
 	return exist
 }
+
+// growBitSet 按需扩容位图, 保证第id位可寻址.
+func growBitSet(bitSet [][]byte, id uint64) [][]byte {
+	for uint64(len(bitSet)) <= id>>_Shift {
+		bitSet = append(bitSet, make([]byte, 8))
+	}
+	return bitSet
+}
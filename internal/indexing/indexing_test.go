@@ -0,0 +1,393 @@
+package indexing
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stopword"
+)
+
+func newTestIndexer(cfg *conf.IndexerConfig) *PipeIndexProcessor {
+	return NewPipeIndexProcessor(cfg, nil)
+}
+
+// termOf 返回快照中词条的倒排列表.
+func termOf(t *TFIDF, term string) *TermPostings {
+	tp, _ := t.Terms.Get(term)
+	return tp
+}
+
+func docIDs(objects []*SimilarObject) []string {
+	ids := make([]string, len(objects))
+	for i, obj := range objects {
+		ids[i] = obj.DocID
+	}
+	return ids
+}
+
+func TestIndexingBeyondLegacyCapacity(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+
+	for _, id := range []uint64{1, 10001, 250000} {
+		err := p.indexing(&common.ConcordanceWrapper{
+			DocID:       fmt.Sprintf("%d", id),
+			Concordance: map[string]uint64{"粮食": 1, fmt.Sprintf("term-%d", id): 2},
+		}, nil)
+		assert.Empty(t, err)
+	}
+	assert.Equal(t, uint64(3), p.GetDoc())
+	assert.Equal(t, uint64(4), p.GetVocabulary())
+	assert.Equal(t, true, p.indexer.Metadata.DocStore.exist("250000"))
+	assert.Equal(t, false, p.indexer.Metadata.DocStore.exist("250001"))
+
+	// 文档记录的内存占用与文档ID的数值无关
+	for _, docID := range []string{"1000000000000000", "18446744073709551615"} {
+		assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: docID, Concordance: map[string]uint64{"粮食": 1}}, nil))
+		assert.Equal(t, true, p.indexer.Metadata.DocStore.exist(docID))
+	}
+	assert.Equal(t, uint64(5), p.GetDoc())
+	assert.Equal(t, uint64(5), p.indexer.Metadata.LastDocIdx)
+
+	// 重复的文档会被忽略
+	err := p.indexing(&common.ConcordanceWrapper{
+		DocID:       "10001",
+		Concordance: map[string]uint64{"粮食": 1},
+	}, nil)
+	assert.Empty(t, err)
+	assert.Equal(t, uint64(5), p.GetDoc())
+	assert.Equal(t, uint64(5), p.indexer.Dict[fnv_1a_32("粮食")&0x1f].Backend["粮食"].DocFrequency)
+}
+
+func TestIndexingRejectsDocs(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{DocCapacity: 1, VocabularyCapacity: 2})
+
+	// 非数字以及超出uint64范围的文档ID在分配任何资源之前被拒绝
+	for _, docID := range []string{"abc", "", "-1", "18446744073709551616"} {
+		err := p.indexing(&common.ConcordanceWrapper{DocID: docID, Concordance: map[string]uint64{"a": 1}}, nil)
+		assert.Equal(t, true, errors.Is(err, ErrInvalidDocID), docID)
+	}
+	assert.Equal(t, uint64(0), p.GetDoc())
+
+	err := p.indexing(&common.ConcordanceWrapper{DocID: "1", Concordance: map[string]uint64{"a": 1, "b": 1, "c": 1}}, nil)
+	assert.Equal(t, true, errors.Is(err, ErrVocabularyCapacityExceeded))
+
+	err = p.indexing(&common.ConcordanceWrapper{DocID: "1", Concordance: map[string]uint64{"a": 1}}, nil)
+	assert.Empty(t, err)
+
+	err = p.indexing(&common.ConcordanceWrapper{DocID: "2", Concordance: map[string]uint64{"a": 1}}, nil)
+	assert.Equal(t, true, errors.Is(err, ErrDocCapacityExceeded))
+	assert.Equal(t, false, p.indexer.Metadata.DocStore.exist("2"))
+	assert.Equal(t, uint64(1), p.GetDoc())
+
+	// 达到容量上限时仍然可以替换已有的文档, 替换不会丢失旧文档
+	err = p.indexing(&common.ConcordanceWrapper{DocID: "1", Operation: pb.DocOperation_UpsertDoc, Concordance: map[string]uint64{"b": 1}}, nil)
+	assert.Empty(t, err)
+	assert.Equal(t, uint64(1), p.GetDoc())
+	assert.Equal(t, true, p.indexer.Metadata.DocStore.exist("1"))
+	assert.Equal(t, uint64(1), p.indexer.Dict[fnv_1a_32("b")&0x1f].Backend["b"].DocFrequency)
+	_, ok := p.indexer.Dict[fnv_1a_32("a")&0x1f].Backend["a"]
+	assert.Equal(t, false, ok)
+	// 新文档的upsert与新增一样受容量上限约束
+	err = p.indexing(&common.ConcordanceWrapper{DocID: "2", Operation: pb.DocOperation_UpsertDoc, Concordance: map[string]uint64{"b": 1}}, nil)
+	assert.Equal(t, true, errors.Is(err, ErrDocCapacityExceeded))
+	assert.Equal(t, uint64(1), p.GetDoc())
+}
+
+func TestTopK(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	docs := map[string]map[string]uint64{
+		"1": {"粮食": 3, "保险": 1},
+		"2": {"保险": 2, "试点": 1},
+		"3": {"财政": 4, "预算": 2},
+	}
+	for id, concordance := range docs {
+		assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: id, Concordance: concordance}, nil))
+	}
+	p.BuildTFIDF()
+
+	for _, v := range p.Snapshot().Vectors {
+		assert.NotEqual(t, 0.0, v.Norm)
+		for i := 1; i < len(v.Entries); i++ {
+			assert.Equal(t, true, v.Entries[i-1].TermIdx < v.Entries[i].TermIdx)
+		}
+	}
+
+	scorer := &TFIDFScorer{}
+	q := p.Snapshot().BuildQueryVector(map[string]uint64{"粮食": 1, "不存在": 1})
+	assert.Equal(t, 1, len(q.Entries))
+	assert.Equal(t, []string{"1"}, docIDs(p.Snapshot().TopK(10, scorer, map[string]uint64{"粮食": 1, "不存在": 1})))
+
+	assert.ElementsMatch(t, []string{"1", "2"}, docIDs(p.Snapshot().TopK(10, scorer, map[string]uint64{"保险": 1})))
+	assert.Equal(t, 1, len(p.Snapshot().TopK(1, scorer, map[string]uint64{"保险": 1})))
+}
+
+// sparseDot 计算两个稀疏向量的点积, 作为全量扫描的参照实现.
+func sparseDot(a, b []VectorEntry) float64 {
+	var sum float64
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].TermIdx < b[j].TermIdx:
+			i++
+		case a[i].TermIdx > b[j].TermIdx:
+			j++
+		default:
+			sum += float64(a[i].Weight) * float64(b[j].Weight)
+			i++
+			j++
+		}
+	}
+	return sum
+}
+
+func TestTopKMatchesFullScan(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	terms := []string{"粮食", "保险", "试点", "财政", "预算", "农业", "补贴"}
+	for id := 1; id <= 50; id++ {
+		concordance := make(map[string]uint64)
+		for i, term := range terms {
+			if (id+i)%(i+2) == 0 {
+				concordance[term] = uint64(id%(i+3) + 1)
+			}
+		}
+		assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: fmt.Sprintf("%d", id), Concordance: concordance}, nil))
+	}
+	p.BuildTFIDF()
+
+	concordance := map[string]uint64{"粮食": 2, "补贴": 1}
+	q := p.Snapshot().BuildQueryVector(concordance)
+	expected := make(map[string]float64)
+	for _, v := range p.Snapshot().Vectors {
+		if v.Norm == 0.0 {
+			continue
+		}
+		if similarity := sparseDot(v.Entries, q.Entries) / (v.Norm * q.Norm); similarity != 0.0 {
+			expected[v.DocID] = similarity
+		}
+	}
+
+	scores := (&TFIDFScorer{}).Score(p.Snapshot(), concordance)
+	assert.Equal(t, len(expected), len(scores))
+	for docIdx, score := range scores {
+		assert.InDelta(t, expected[p.Snapshot().Vectors[docIdx-1].DocID], score, 1e-9)
+	}
+
+	docs := p.Snapshot().TopK(uint32(len(expected)), &TFIDFScorer{}, concordance)
+	assert.Equal(t, len(expected), len(docs))
+	for i, obj := range docs {
+		_, ok := expected[obj.DocID]
+		assert.Equal(t, true, ok)
+		if i > 0 {
+			// 按得分降序排列
+			assert.Equal(t, true, docs[i-1].Similarity >= obj.Similarity)
+		}
+	}
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	assert.Equal(t, uint64(0), p.Snapshot().Generation)
+
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1", Concordance: map[string]uint64{"粮食": 1, "保险": 1}}, nil))
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "2", Concordance: map[string]uint64{"财政": 1}}, nil))
+	p.BuildTFIDF()
+	old := p.Snapshot()
+	assert.Equal(t, uint64(1), old.Generation)
+
+	// 写入新文档不影响已发布的快照
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "3", Concordance: map[string]uint64{"粮食": 2}}, nil))
+	assert.Equal(t, uint64(2), old.Doc)
+	assert.Equal(t, uint64(1), termOf(old, "粮食").DocFrequency)
+	assert.Equal(t, []string{"1"}, docIDs(old.TopK(10, &TFIDFScorer{}, map[string]uint64{"粮食": 1})))
+
+	p.BuildTFIDF()
+	cur := p.Snapshot()
+	assert.Equal(t, uint64(2), cur.Generation)
+	assert.Equal(t, uint64(3), cur.Doc)
+	assert.ElementsMatch(t, []string{"1", "3"}, docIDs(cur.TopK(10, &TFIDFScorer{}, map[string]uint64{"粮食": 1})))
+
+	// 未被修改的倒排列表与分段在快照之间共享, 被修改的倒排列表重新冻结
+	assert.Equal(t, true, termOf(old, "财政") == termOf(cur, "财政"))
+	assert.Equal(t, true, termOf(old, "保险") == termOf(cur, "保险"))
+	assert.Equal(t, false, termOf(old, "粮食") == termOf(cur, "粮食"))
+	assert.Equal(t, uint64(3), termOf(cur, "粮食").CollectionFrequency)
+	shard := fnv_1a_32("财政") & 0x1f
+	if shard != fnv_1a_32("粮食")&0x1f {
+		assert.Equal(t, fmt.Sprintf("%p", old.Terms[shard]), fmt.Sprintf("%p", cur.Terms[shard]))
+	}
+
+	// 删除文档之后, 被删除文档的倒排列表重新冻结
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "2", Operation: pb.DocOperation_DeleteDoc}, nil))
+	p.BuildTFIDF()
+	_, ok := p.Snapshot().Terms.Get("财政")
+	assert.Equal(t, false, ok)
+	_, ok = cur.Terms.Get("财政")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, termOf(cur, "粮食") == termOf(p.Snapshot(), "粮食"))
+}
+
+func TestWindow(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{RetainedSnapshots: 2})
+	for id := 1; id <= 25; id++ {
+		// 每5个文档得分相同, 用于检查同分文档的排序是否稳定
+		assert.Empty(t, p.indexing(&common.ConcordanceWrapper{
+			DocID:       fmt.Sprintf("%d", id),
+			Concordance: map[string]uint64{"粮食": uint64(id/5 + 1), "其他": 1},
+		}, nil))
+	}
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "26", Concordance: map[string]uint64{"其他": 1}}, nil))
+	p.BuildTFIDF()
+	s := p.Snapshot()
+	scorer := &BM25Scorer{K1: 1.2, B: 0.75}
+	q := &Query{Concordance: map[string]uint64{"粮食": 1}}
+
+	all, total := s.Window(0, 100, scorer, q)
+	assert.Equal(t, uint64(25), total)
+	assert.Equal(t, 25, len(all))
+
+	seen := make(map[string]struct{})
+	for offset := uint32(0); offset < 25; offset += 10 {
+		page, total := s.Window(offset, 10, scorer, q)
+		assert.Equal(t, uint64(25), total)
+		for i, obj := range page {
+			assert.Equal(t, all[int(offset)+i].DocID, obj.DocID)
+			seen[obj.DocID] = struct{}{}
+		}
+	}
+	assert.Equal(t, 25, len(seen))
+
+	page, total := s.Window(30, 10, scorer, q)
+	assert.Equal(t, 0, len(page))
+	assert.Equal(t, uint64(25), total)
+
+	// 超出保留个数的快照会被淘汰
+	p.BuildTFIDF()
+	p.BuildTFIDF()
+	_, err := p.SnapshotAt(s.Epoch, s.Generation)
+	assert.Equal(t, true, errors.Is(err, ErrSnapshotExpired))
+	_, err = p.SnapshotAt(s.Epoch, s.Generation+1)
+	assert.Empty(t, err)
+
+	// 重启之后快照代数从头计数, 重启之前的快照不会与代数相同的新快照混淆
+	r := newTestIndexer(&conf.IndexerConfig{})
+	for r.Snapshot().Generation < s.Generation+1 {
+		r.BuildTFIDF()
+	}
+	assert.NotEqual(t, s.Epoch, r.Snapshot().Epoch)
+	_, err = r.SnapshotAt(s.Epoch, s.Generation+1)
+	assert.Equal(t, true, errors.Is(err, ErrSnapshotExpired))
+	_, err = r.SnapshotAt(r.Snapshot().Epoch, s.Generation+1)
+	assert.Empty(t, err)
+}
+
+func TestSnapshotStopWords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stopwords")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	lists, err := stopword.NewLists(&conf.StopWordsConfig{Editable: filepath.Join(dir, "stopwords.json")})
+	assert.Empty(t, err)
+
+	p := newTestIndexer(&conf.IndexerConfig{})
+	assert.Nil(t, p.Snapshot().StopWords)
+	p.UseStopWords(lists)
+	p.BuildTFIDF()
+	s := p.Snapshot()
+	assert.Equal(t, lists.Current(), s.StopWords)
+
+	// 修改停词表之前处理、之后冻结的文档, 快照记录处理文档时的停词表
+	v1 := lists.Current()
+	wrapper := newTestWrapper("1", "收入 保险")
+	wrapper.StopWordsVersion = v1.Version
+	assert.Empty(t, p.indexing(wrapper, nil))
+	set, err := lists.Add(stopword.ListChinese, []string{"财政"})
+	assert.Empty(t, err)
+	p.BuildTFIDF()
+	assert.Equal(t, v1, p.Snapshot().StopWords)
+
+	// 按新的停词表处理的文档写入之后, 新的快照使用新的停词表, 翻页访问的旧快照不受影响
+	wrapper = newTestWrapper("2", "粮食 保险")
+	wrapper.StopWordsVersion = set.Version
+	assert.Empty(t, p.indexing(wrapper, nil))
+	p.BuildTFIDF()
+	assert.Equal(t, set, p.Snapshot().StopWords)
+	old, err := p.SnapshotAt(s.Epoch, s.Generation+1)
+	assert.Empty(t, err)
+	assert.Equal(t, v1, old.StopWords)
+}
+
+func TestDeleteAndUpsert(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{DocCapacity: 2})
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1", Concordance: map[string]uint64{"粮食": 2, "保险": 1}}, nil))
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "2", Concordance: map[string]uint64{"粮食": 1, "试点": 1}}, nil))
+	p.BuildTFIDF()
+	before := p.Snapshot()
+
+	// 删除文档会同时修正文档频率、文档总量以及词汇总量
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1", Operation: pb.DocOperation_DeleteDoc}, nil))
+	assert.Equal(t, uint64(1), p.GetDoc())
+	assert.Equal(t, uint64(2), p.GetVocabulary())
+	assert.Equal(t, false, p.indexer.Metadata.DocStore.exist("1"))
+	assert.Equal(t, uint64(1), p.indexer.Dict[fnv_1a_32("粮食")&0x1f].Backend["粮食"].DocFrequency)
+	_, ok := p.indexer.Dict[fnv_1a_32("保险")&0x1f].Backend["保险"]
+	assert.Equal(t, false, ok)
+
+	// 重复删除不报错
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1", Operation: pb.DocOperation_DeleteDoc}, nil))
+	assert.Equal(t, uint64(1), p.GetDoc())
+
+	p.BuildTFIDF()
+	s := p.Snapshot()
+	assert.Equal(t, uint64(1), s.Doc)
+	assert.Equal(t, 0, len(s.TopK(10, &TFIDFScorer{}, map[string]uint64{"保险": 1})))
+	assert.Equal(t, []string{"2"}, docIDs(s.TopK(10, &BM25Scorer{K1: 1.2, B: 0.75}, map[string]uint64{"粮食": 1})))
+	// 已发布的快照不受影响
+	assert.Equal(t, []string{"1"}, docIDs(before.TopK(10, &TFIDFScorer{}, map[string]uint64{"保险": 1})))
+
+	// 文档删除之后释放容量
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "3", Concordance: map[string]uint64{"保险": 1}}, nil))
+	assert.Equal(t, uint64(2), p.GetDoc())
+
+	// 重复的新增操作被忽略, 替换操作以新内容替换旧的倒排记录
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "2", Concordance: map[string]uint64{"财政": 1}}, nil))
+	assert.Equal(t, uint64(1), p.indexer.Dict[fnv_1a_32("试点")&0x1f].Backend["试点"].DocFrequency)
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{
+		DocID:       "2",
+		Operation:   pb.DocOperation_UpsertDoc,
+		Concordance: map[string]uint64{"财政": 1, "保险": 3},
+	}, nil))
+	assert.Equal(t, uint64(2), p.GetDoc())
+	assert.Equal(t, uint64(2), p.GetVocabulary())
+	p.BuildTFIDF()
+	s = p.Snapshot()
+	assert.Equal(t, 0, len(s.TopK(10, &TFIDFScorer{}, map[string]uint64{"试点": 1})))
+	assert.Equal(t, []string{"2", "3"}, docIDs(s.TopK(10, &BM25Scorer{K1: 1.2, B: 0.75}, map[string]uint64{"保险": 1})))
+	assert.Equal(t, uint64(2), termOf(s, "保险").DocFrequency)
+}
+
+func TestDeleteAfterRebuildDocs(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1", Concordance: map[string]uint64{"粮食": 1}}, nil))
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "2", Concordance: map[string]uint64{"粮食": 1, "保险": 1}}, nil))
+
+	// 加载索引文件之后正排索引由倒排索引重建
+	p.docs = make(map[string]*docEntry)
+	p.rebuildDocs(nil)
+	assert.ElementsMatch(t, []string{"粮食", "保险"}, p.docs["2"].terms)
+
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "2", Operation: pb.DocOperation_DeleteDoc}, nil))
+	assert.Equal(t, uint64(1), p.GetDoc())
+	assert.Equal(t, uint64(1), p.GetVocabulary())
+	// 删除之后分配的序号不与已有序号冲突
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "3", Concordance: map[string]uint64{"试点": 1}}, nil))
+	assert.Equal(t, uint64(3), p.indexer.Metadata.LastDocIdx)
+	assert.Equal(t, "0000000003", p.indexer.Dict[fnv_1a_32("试点")&0x1f].Backend["试点"].TermID)
+}
@@ -0,0 +1,90 @@
+package indexing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+)
+
+// MigrateJSONDump 将旧版本的JSON索引文件 (metadata.json以及term-indexing-N.json) 加载到内存,
+// 并以段文件格式dump为新的一代. 旧文件保持不变, 确认迁移成功之后可以手动删除.
+func (p *PipeIndexProcessor) MigrateJSONDump() error {
+	if err := p.loadJSONDump(); err != nil {
+		return err
+	}
+	if _, err := p.Dump(); err != nil {
+		return err
+	}
+	log.Info().Msgf("migrate json dump under path=%s", p.cfg.DumpPath)
+	return nil
+}
+
+// loadLegacySegment 加载直接位于DumpPath下的段文件, 该文件由不分代的旧版本写入.
+func (p *PipeIndexProcessor) loadLegacySegment() error {
+	data, err := ioutil.ReadFile(p.fLegacySegment())
+	if err != nil {
+		return err
+	}
+	return p.decodeSegment(data)
+}
+
+// loadJSONDump 加载旧版本的JSON索引文件, 加载失败时倒排索引保持不变.
+func (p *PipeIndexProcessor) loadJSONDump() error {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	// load metadata
+	metadata, err := ioutil.ReadFile(p.fMetadata())
+	if err != nil {
+		return err
+	}
+	m := new(Metadata)
+	if err = json.Unmarshal(metadata, m); err != nil {
+		return err
+	}
+	log.Info().Msgf("load metadata from file=%s", p.fMetadata())
+
+	// 分段load dict
+	dict := make([]*Shard, _Shards)
+	for idx := range dict {
+		pDict, err := ioutil.ReadFile(p.fPartialDict(idx))
+		if err != nil {
+			return err
+		}
+		dict[idx] = &Shard{Backend: make(map[string]*PostingList)}
+		if err = json.Unmarshal(pDict, dict[idx]); err != nil {
+			return err
+		}
+		log.Info().Msgf("load %d-term-indexing from file=%s", idx, p.fPartialDict(idx))
+	}
+
+	// 旧版本的索引文件没有记录已分配的最大序号, 此时序号是连续分配的
+	if m.LastDocIdx == 0 {
+		m.LastDocIdx = m.Doc
+	}
+	if m.LastTermID == 0 {
+		m.LastTermID = m.Vocabulary
+	}
+	if m.VocabularyStore == nil {
+		m.VocabularyStore = &VocabularyStore{BitSet: make([][]byte, 0)}
+	}
+	p.indexer.Metadata = m
+	p.indexer.Dict = dict
+	// 文档记录根据倒排索引重建
+	p.rebuildDocs(nil)
+	return nil
+}
+
+func (p *PipeIndexProcessor) fLegacySegment() string {
+	return filepath.Join(p.cfg.DumpPath, _SegmentFile)
+}
+
+func (p *PipeIndexProcessor) fMetadata() string {
+	return filepath.Join(p.cfg.DumpPath, "metadata.json")
+}
+
+func (p *PipeIndexProcessor) fPartialDict(i int) string {
+	return filepath.Join(p.cfg.DumpPath, fmt.Sprintf("term-indexing-%d.json", i))
+}
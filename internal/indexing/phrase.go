@@ -0,0 +1,264 @@
+package indexing
+
+import (
+	"math"
+	"sort"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+)
+
+const (
+	// 短语命中时的得分提升系数
+	_PhraseBoost = 0.5
+)
+
+// Query 查询, 由参与打分的词条集合、若干短语以及候选文档集合组成
+type Query struct {
+	Concordance map[string]uint64
+	// 文档必须满足全部短语
+	Phrases []*PhraseQuery
+	// 候选文档序号到短语命中次数的映射, 非nil时只保留其中的文档, 并按命中次数提升得分
+	Candidates map[uint64]int
+}
+
+// PhraseQuery 短语查询或邻近查询
+type PhraseQuery struct {
+	Terms []string
+	// 词条在短语中的相对位置, 与Terms一一对应, 停词留下的空位会被保留
+	Offsets []uint32
+	// Proximity为false时, 词条必须按Offsets依次出现;
+	// 为true时, 词条以任意顺序出现在跨度不超过 len(Terms)-1+Slop 的窗口内即可.
+	Proximity bool
+	Slop      uint32
+	// 同一短语在汉字n-gram子字段上的查询, 非nil时文档命中任一字段即满足短语
+	NGram *PhraseQuery
+}
+
+// NewPhraseQuery 根据短语的分析结果构造短语查询, 词条按其在短语中的位置排列.
+// 词典分词与汉字n-gram子字段分别编号位置, 两者同时存在时n-gram子字段上的短语查询记录在NGram中.
+func NewPhraseQuery(phrase *common.ConcordanceWrapper, proximity bool, slop uint32) *PhraseQuery {
+	q := newFieldPhraseQuery(phrase, false, proximity, slop)
+	ngram := newFieldPhraseQuery(phrase, true, proximity, slop)
+	if len(q.Terms) == 0 {
+		return ngram
+	}
+	if len(ngram.Terms) > 0 {
+		q.NGram = ngram
+	}
+	return q
+}
+
+// newFieldPhraseQuery 用词典分词或n-gram子字段的词条构造短语查询.
+func newFieldPhraseQuery(phrase *common.ConcordanceWrapper, ngram bool, proximity bool, slop uint32) *PhraseQuery {
+	type occurrence struct {
+		term     string
+		position uint32
+	}
+	occurrences := make([]occurrence, 0, len(phrase.Positions))
+	for term, positions := range phrase.Positions {
+		if common.IsNGramTerm(term) != ngram {
+			continue
+		}
+		for _, position := range positions {
+			occurrences = append(occurrences, occurrence{term: term, position: position})
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].position < occurrences[j].position
+	})
+
+	q := &PhraseQuery{
+		Terms:     make([]string, len(occurrences)),
+		Offsets:   make([]uint32, len(occurrences)),
+		Proximity: proximity,
+		Slop:      slop,
+	}
+	for i, o := range occurrences {
+		q.Terms[i] = o.term
+		q.Offsets[i] = o.position - occurrences[0].position
+	}
+	return q
+}
+
+// filter 剔除不在候选集合中或不满足短语的文档, 并按短语命中次数提升其余文档的得分.
+func (t *TFIDF) filter(scores map[uint64]float64, q *Query) {
+	hits := make(map[uint64]int, len(scores))
+	if q.Candidates != nil {
+		for docIdx := range scores {
+			n, ok := q.Candidates[docIdx]
+			if !ok {
+				delete(scores, docIdx)
+				continue
+			}
+			hits[docIdx] = n
+		}
+	}
+	for _, phrase := range q.Phrases {
+		matches := t.MatchPhrase(phrase)
+		for docIdx := range scores {
+			n, ok := matches[docIdx]
+			if !ok {
+				delete(scores, docIdx)
+				continue
+			}
+			hits[docIdx] += n
+		}
+	}
+	for docIdx, score := range scores {
+		scores[docIdx] = score + math.Abs(score)*_PhraseBoost*math.Log(1+float64(hits[docIdx]))
+	}
+}
+
+// MatchPhrase 返回满足短语的文档序号到命中次数的映射, 两个字段都命中时取命中次数较多者.
+func (t *TFIDF) MatchPhrase(phrase *PhraseQuery) map[uint64]int {
+	matches := t.matchField(phrase)
+	if phrase.NGram != nil {
+		for docIdx, n := range t.matchField(phrase.NGram) {
+			if n > matches[docIdx] {
+				matches[docIdx] = n
+			}
+		}
+	}
+	return matches
+}
+
+// matchField 在单个字段上匹配短语.
+func (t *TFIDF) matchField(phrase *PhraseQuery) map[uint64]int {
+	matches := make(map[uint64]int)
+	if len(phrase.Terms) == 0 {
+		return matches
+	}
+
+	// 每个词条在各文档中的位置列表
+	lists := make([]map[uint64][]uint32, len(phrase.Terms))
+	shortest := 0
+	for i, term := range phrase.Terms {
+		tp, ok := t.Terms.Get(term)
+		if !ok {
+			return matches
+		}
+		lists[i] = make(map[uint64][]uint32, len(tp.Postings))
+		for _, posting := range tp.Postings {
+			lists[i][posting.DocIdx] = posting.Positions
+		}
+		if len(lists[i]) < len(lists[shortest]) {
+			shortest = i
+		}
+	}
+
+	// 邻近查询按不同的词条统计, 重复出现的词条在窗口内需出现相应的次数
+	var distinct, need []int
+	if phrase.Proximity {
+		seen := make(map[string]int, len(phrase.Terms))
+		for i, term := range phrase.Terms {
+			if j, ok := seen[term]; ok {
+				need[j]++
+				continue
+			}
+			seen[term] = len(distinct)
+			distinct = append(distinct, i)
+			need = append(need, 1)
+		}
+	}
+
+	positions := make([][]uint32, len(lists))
+	for docIdx := range lists[shortest] {
+		found := true
+		for i := range lists {
+			if positions[i], found = lists[i][docIdx]; !found {
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		var n int
+		if phrase.Proximity {
+			termPositions := make([][]uint32, len(distinct))
+			for j, i := range distinct {
+				termPositions[j] = positions[i]
+			}
+			n = countWindows(termPositions, need, uint32(len(positions)-1)+phrase.Slop)
+		} else {
+			n = countExact(positions, phrase.Offsets)
+		}
+		if n > 0 {
+			matches[docIdx] = n
+		}
+	}
+	return matches
+}
+
+// countExact 统计词条按offsets依次出现的次数.
+func countExact(positions [][]uint32, offsets []uint32) int {
+	var n int
+	for _, first := range positions[0] {
+		if first < offsets[0] {
+			continue
+		}
+		start := first - offsets[0]
+		matched := true
+		for i := 1; i < len(positions); i++ {
+			if !contains(positions[i], start+offsets[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			n++
+		}
+	}
+	return n
+}
+
+// countWindows 统计第i个词条至少出现need[i]次、跨度不超过span的窗口个数, 各次命中互不重叠.
+// 每个位置只能满足一次出现, 因此重复的词条需要出现在不同的位置上.
+func countWindows(positions [][]uint32, need []int, span uint32) int {
+	type occurrence struct {
+		position uint32
+		term     int
+	}
+	merged := make([]occurrence, 0)
+	for term, list := range positions {
+		for _, position := range list {
+			merged = append(merged, occurrence{position: position, term: term})
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].position < merged[j].position
+	})
+
+	var n, left int
+	counts := make([]int, len(positions))
+	missing := len(positions)
+	for right, o := range merged {
+		if counts[o.term]++; counts[o.term] == need[o.term] {
+			missing--
+		}
+		if missing > 0 {
+			continue
+		}
+		// 去掉左端多余的出现, 得到以right结尾的最短窗口
+		for counts[merged[left].term] > need[merged[left].term] {
+			counts[merged[left].term]--
+			left++
+		}
+		if o.position-merged[left].position > span {
+			continue
+		}
+		n++
+		for i := range counts {
+			counts[i] = 0
+		}
+		missing = len(positions)
+		left = right + 1
+	}
+	return n
+}
+
+// contains 在升序排列的位置列表中查找指定位置.
+func contains(positions []uint32, position uint32) bool {
+	i := sort.Search(len(positions), func(i int) bool { return positions[i] >= position })
+	return i < len(positions) && positions[i] == position
+}
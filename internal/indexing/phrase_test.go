@@ -0,0 +1,118 @@
+package indexing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+// newTestWrapper 按空格切分文本, 构造带位置信息的ConcordanceWrapper.
+func newTestWrapper(docID, text string) *common.ConcordanceWrapper {
+	w := common.NewConcordanceWrapper(docID)
+	for position, term := range strings.Fields(text) {
+		w.Add(term, uint32(position))
+	}
+	return w
+}
+
+func buildPhraseFixture(t *testing.T) *TFIDF {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	docs := []string{
+		"收入 保险 试点 工作",
+		"保险 收入 试点 工作",
+		"收入 农业 保险 试点",
+		"收入 保险 收入 保险 试点",
+		"保险 工作 收入 试点 农业 补贴 预算",
+		"财政 预算",
+		"税 财政 预算",
+		"税 工作 税 税",
+	}
+	for i, text := range docs {
+		assert.Empty(t, p.indexing(newTestWrapper(fmt.Sprintf("%d", i+1), text), nil))
+	}
+	p.BuildTFIDF()
+	return p.Snapshot()
+}
+
+func TestPhraseQuery(t *testing.T) {
+	s := buildPhraseFixture(t)
+
+	q := NewPhraseQuery(newTestWrapper("", "收入 保险"), false, 0)
+	assert.Equal(t, []string{"收入", "保险"}, q.Terms)
+	assert.Equal(t, []uint32{0, 1}, q.Offsets)
+	assert.Equal(t, map[uint64]int{1: 1, 4: 2}, s.MatchPhrase(q))
+
+	// 邻近查询不要求顺序
+	q = NewPhraseQuery(newTestWrapper("", "收入 保险"), true, 0)
+	assert.Equal(t, map[uint64]int{1: 1, 2: 1, 4: 2}, s.MatchPhrase(q))
+	q = NewPhraseQuery(newTestWrapper("", "收入 保险"), true, 1)
+	assert.Equal(t, map[uint64]int{1: 1, 2: 1, 3: 1, 4: 2, 5: 1}, s.MatchPhrase(q))
+
+	// 停词留下的空位: 位置0与位置2
+	w := common.NewConcordanceWrapper("")
+	w.Add("收入", 3)
+	w.Add("保险", 5)
+	q = NewPhraseQuery(w, false, 0)
+	assert.Equal(t, []uint32{0, 2}, q.Offsets)
+	assert.Equal(t, map[uint64]int{3: 1}, s.MatchPhrase(q))
+
+	// 邻近查询中重复的词条需要出现在不同的位置上
+	// 只出现一次的词条不会同时满足两次出现, 已命中的位置不会被再次使用
+	q = NewPhraseQuery(newTestWrapper("", "税 税"), true, 0)
+	assert.Equal(t, map[uint64]int{8: 1}, s.MatchPhrase(q))
+	q = NewPhraseQuery(newTestWrapper("", "税 税"), true, 5)
+	assert.Equal(t, map[uint64]int{8: 1}, s.MatchPhrase(q))
+	q = NewPhraseQuery(newTestWrapper("", "税 工作 税"), true, 0)
+	assert.Equal(t, map[uint64]int{8: 1}, s.MatchPhrase(q))
+	q = NewPhraseQuery(newTestWrapper("", "税 税 税"), true, 1)
+	assert.Equal(t, map[uint64]int{8: 1}, s.MatchPhrase(q))
+	q = NewPhraseQuery(newTestWrapper("", "税 税 税 税"), true, 10)
+	assert.Equal(t, 0, len(s.MatchPhrase(q)))
+	q = NewPhraseQuery(newTestWrapper("", "税 税"), false, 0)
+	assert.Equal(t, map[uint64]int{8: 1}, s.MatchPhrase(q))
+
+	q = NewPhraseQuery(newTestWrapper("", "收入 不存在"), false, 0)
+	assert.Equal(t, 0, len(s.MatchPhrase(q)))
+}
+
+func TestWindowWithPhrases(t *testing.T) {
+	s := buildPhraseFixture(t)
+	scorer := &BM25Scorer{K1: 1.2, B: 0.75}
+	concordance := map[string]uint64{"收入": 1, "保险": 1}
+
+	all, total := s.Window(0, 10, scorer, &Query{Concordance: concordance})
+	assert.Equal(t, uint64(5), total)
+	assert.Equal(t, 5, len(all))
+
+	phrase := NewPhraseQuery(newTestWrapper("", "收入 保险"), false, 0)
+	hits, total := s.Window(0, 10, scorer, &Query{Concordance: concordance, Phrases: []*PhraseQuery{phrase}})
+	assert.Equal(t, uint64(2), total)
+	assert.ElementsMatch(t, []string{"1", "4"}, docIDs(hits))
+	for _, hit := range hits {
+		for _, obj := range all {
+			if obj.DocID == hit.DocID {
+				// 命中短语的文档得分得到提升
+				assert.Equal(t, true, hit.Similarity > obj.Similarity)
+			}
+		}
+	}
+
+	// 文档需同时满足全部短语
+	other := NewPhraseQuery(newTestWrapper("", "试点 工作"), false, 0)
+	hits, total = s.Window(0, 10, scorer, &Query{Concordance: concordance, Phrases: []*PhraseQuery{phrase, other}})
+	assert.Equal(t, uint64(1), total)
+	assert.Equal(t, []string{"1"}, docIDs(hits))
+
+	// 只保留候选文档
+	hits, total = s.Window(0, 10, scorer, &Query{Concordance: concordance, Candidates: map[uint64]int{2: 0, 6: 0}})
+	assert.Equal(t, uint64(1), total)
+	assert.Equal(t, []string{"2"}, docIDs(hits))
+	hits, total = s.Window(0, 10, scorer, &Query{Concordance: concordance, Candidates: map[uint64]int{}})
+	assert.Equal(t, uint64(0), total)
+	assert.Equal(t, 0, len(hits))
+}
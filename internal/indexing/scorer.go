@@ -0,0 +1,270 @@
+package indexing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+const (
+	// RankingTFIDF TF-IDF余弦相似度
+	RankingTFIDF = "tfidf"
+	// RankingBM25 Okapi BM25
+	RankingBM25 = "bm25"
+	// RankingBM25F 按字段分别做长度归一化的BM25F
+	RankingBM25F = "bm25f"
+	// RankingLMDirichlet 基于Dirichlet平滑的查询似然语言模型
+	RankingLMDirichlet = "lm_dirichlet"
+
+	_DefaultBM25K1          = 1.2
+	_DefaultBM25B           = 0.75
+	_DefaultBM25FBodyWeight = 1.0
+	// n-gram子字段用于提升未登录词的召回, 默认权重低于正文字段
+	_DefaultBM25FNGramWeight = 0.5
+	_DefaultLMDirichletMu    = 2000.0
+	_DefaultRankingFunction  = RankingTFIDF
+)
+
+var (
+	// ErrUnknownRanking 未知排序函数错误
+	ErrUnknownRanking = errors.New("unknown ranking function")
+	// ErrBadRankingConfig 排序函数参数错误
+	ErrBadRankingConfig = errors.New("bad ranking config")
+)
+
+// Scorer 排序函数接口
+type Scorer interface {
+	// Name 返回排序函数名.
+	Name() string
+	// Score 为包含至少一个查询词条的文档打分, 返回文档序号到得分的映射.
+	// 实现只应遍历查询词条对应的倒排列表.
+	Score(t *TFIDF, concordance map[string]uint64) map[uint64]float64
+}
+
+// NewScorer 根据名称新建排序函数.
+func NewScorer(name string, cfg *conf.IndexerConfig) (Scorer, error) {
+	switch name {
+	case RankingTFIDF:
+		{
+			return &TFIDFScorer{}, nil
+		}
+	case RankingBM25:
+		{
+			s := &BM25Scorer{K1: _DefaultBM25K1, B: _DefaultBM25B}
+			if cfg.BM25 != nil {
+				if cfg.BM25.K1 > 0 {
+					s.K1 = cfg.BM25.K1
+				}
+				if cfg.BM25.B != nil {
+					if *cfg.BM25.B < 0 || *cfg.BM25.B > 1 {
+						return nil, fmt.Errorf("%w: bm25.b=%v not in [0, 1]", ErrBadRankingConfig, *cfg.BM25.B)
+					}
+					s.B = *cfg.BM25.B
+				}
+			}
+			return s, nil
+		}
+	case RankingBM25F:
+		{
+			s := &BM25FScorer{
+				K1:      _DefaultBM25K1,
+				Weights: [NumFields]float64{FieldBody: _DefaultBM25FBodyWeight, FieldNGram: _DefaultBM25FNGramWeight},
+				B:       [NumFields]float64{FieldBody: _DefaultBM25B, FieldNGram: _DefaultBM25B},
+			}
+			if cfg.BM25F == nil {
+				return s, nil
+			}
+			if cfg.BM25F.K1 > 0 {
+				s.K1 = cfg.BM25F.K1
+			}
+			for field, fc := range map[int]*conf.BM25FFieldConfig{FieldBody: cfg.BM25F.Body, FieldNGram: cfg.BM25F.NGram} {
+				if fc == nil {
+					continue
+				}
+				if fc.Weight < 0 {
+					return nil, fmt.Errorf("%w: bm25f.%s.weight=%v is negative", ErrBadRankingConfig, FieldNames[field], fc.Weight)
+				}
+				if fc.Weight > 0 {
+					s.Weights[field] = fc.Weight
+				}
+				if fc.B != nil {
+					if *fc.B < 0 || *fc.B > 1 {
+						return nil, fmt.Errorf("%w: bm25f.%s.b=%v not in [0, 1]", ErrBadRankingConfig, FieldNames[field], *fc.B)
+					}
+					s.B[field] = *fc.B
+				}
+			}
+			return s, nil
+		}
+	case RankingLMDirichlet:
+		{
+			s := &LMDirichletScorer{Mu: _DefaultLMDirichletMu}
+			if cfg.LMDirichlet != nil && cfg.LMDirichlet.Mu > 0 {
+				s.Mu = cfg.LMDirichlet.Mu
+			}
+			return s, nil
+		}
+	default:
+		{
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRanking, name)
+		}
+	}
+}
+
+// TFIDFScorer 查询向量与文档向量的余弦相似度.
+// 文档权重为 tf * log2(D/df), 查询权重为增强词频 (0.5 + 0.5*tf/max_tf) * log2(D/df).
+type TFIDFScorer struct{}
+
+// Name 返回排序函数名.
+func (s *TFIDFScorer) Name() string {
+	return RankingTFIDF
+}
+
+// Score 计算余弦相似度, 相似度为0的文档不会被返回.
+func (s *TFIDFScorer) Score(t *TFIDF, concordance map[string]uint64) map[uint64]float64 {
+	scores := make(map[uint64]float64)
+
+	q := t.BuildQueryVector(concordance)
+	if q.Norm == 0.0 {
+		return scores
+	}
+
+	for i, term := range q.Terms {
+		tp, _ := t.Terms.Get(term)
+		idf := float32(math.Log2(float64(t.Doc) / float64(tp.DocFrequency)))
+		for _, posting := range tp.Postings {
+			w := float32(posting.TermFrequency) * idf
+			scores[posting.DocIdx] += float64(w) * float64(q.Entries[i].Weight)
+		}
+	}
+
+	for docIdx, score := range scores {
+		v := t.Vectors[docIdx-1]
+		if v.Norm == 0.0 || score == 0.0 {
+			delete(scores, docIdx)
+			continue
+		}
+		scores[docIdx] = score / (v.Norm * q.Norm)
+	}
+	return scores
+}
+
+// BM25Scorer Okapi BM25.
+// score = Σ qtf * idf * tf*(k1+1) / (tf + k1*(1-b+b*dl/avgdl)),
+// 其中 idf = ln(1 + (D-df+0.5)/(df+0.5)).
+type BM25Scorer struct {
+	K1 float64
+	B  float64
+}
+
+// Name 返回排序函数名.
+func (s *BM25Scorer) Name() string {
+	return RankingBM25
+}
+
+// Score 计算BM25得分.
+func (s *BM25Scorer) Score(t *TFIDF, concordance map[string]uint64) map[uint64]float64 {
+	scores := make(map[uint64]float64)
+	if t.AvgDocLength == 0.0 {
+		return scores
+	}
+
+	for term, qtf := range concordance {
+		tp, ok := t.Terms.Get(term)
+		if !ok {
+			continue
+		}
+		df := float64(tp.DocFrequency)
+		idf := math.Log(1 + (float64(t.Doc)-df+0.5)/(df+0.5))
+		for _, posting := range tp.Postings {
+			tf := float64(posting.TermFrequency)
+			dl := float64(t.Vectors[posting.DocIdx-1].Length)
+			scores[posting.DocIdx] += float64(qtf) * idf * tf * (s.K1 + 1) / (tf + s.K1*(1-s.B+s.B*dl/t.AvgDocLength))
+		}
+	}
+	return scores
+}
+
+// BM25FScorer 按字段分别做长度归一化的BM25F.
+// 每个字段的词频先按字段长度归一化并乘以字段权重, 同一词条在各字段上的结果累加之后再做饱和:
+// tf' = Σ w_f * tf_f / (1-b_f+b_f*dl_f/avgdl_f), score = Σ qtf * idf * tf'*(k1+1) / (tf' + k1),
+// 其中 idf = ln(1 + (D-df+0.5)/(df+0.5)). 汉字n-gram子字段的词条与正文字段的词条互不相交,
+// 因此每个词条只在其所属的字段上累加.
+type BM25FScorer struct {
+	K1      float64
+	Weights [NumFields]float64
+	B       [NumFields]float64
+}
+
+// Name 返回排序函数名.
+func (s *BM25FScorer) Name() string {
+	return RankingBM25F
+}
+
+// Score 计算BM25F得分.
+func (s *BM25FScorer) Score(t *TFIDF, concordance map[string]uint64) map[uint64]float64 {
+	scores := make(map[uint64]float64)
+
+	for term, qtf := range concordance {
+		tp, ok := t.Terms.Get(term)
+		if !ok {
+			continue
+		}
+		field := FieldOf(term)
+		avgdl := t.AvgFieldLength[field]
+		if avgdl == 0.0 || s.Weights[field] == 0.0 {
+			continue
+		}
+		df := float64(tp.DocFrequency)
+		idf := math.Log(1 + (float64(t.Doc)-df+0.5)/(df+0.5))
+		b := s.B[field]
+		for _, posting := range tp.Postings {
+			dl := float64(t.Vectors[posting.DocIdx-1].FieldLengths[field])
+			tf := s.Weights[field] * float64(posting.TermFrequency) / (1 - b + b*dl/avgdl)
+			scores[posting.DocIdx] += float64(qtf) * idf * tf * (s.K1 + 1) / (tf + s.K1)
+		}
+	}
+	return scores
+}
+
+// LMDirichletScorer 基于Dirichlet平滑的查询似然语言模型.
+// 采用与对数似然保序的形式:
+// score = Σ qtf * ln(1 + tf/(mu*P(t|C))) + |q| * ln(mu/(dl+mu)),
+// 其中 P(t|C) = cf/|C|, 求和只针对同时出现在查询与文档中的词条.
+type LMDirichletScorer struct {
+	Mu float64
+}
+
+// Name 返回排序函数名.
+func (s *LMDirichletScorer) Name() string {
+	return RankingLMDirichlet
+}
+
+// Score 计算查询似然得分.
+func (s *LMDirichletScorer) Score(t *TFIDF, concordance map[string]uint64) map[uint64]float64 {
+	scores := make(map[uint64]float64)
+	if t.TotalDocLength == 0 {
+		return scores
+	}
+
+	var qLength float64
+	for term, qtf := range concordance {
+		tp, ok := t.Terms.Get(term)
+		if !ok {
+			continue
+		}
+		qLength += float64(qtf)
+		pc := float64(tp.CollectionFrequency) / float64(t.TotalDocLength)
+		for _, posting := range tp.Postings {
+			scores[posting.DocIdx] += float64(qtf) * math.Log(1+float64(posting.TermFrequency)/(s.Mu*pc))
+		}
+	}
+
+	for docIdx := range scores {
+		dl := float64(t.Vectors[docIdx-1].Length)
+		scores[docIdx] += qLength * math.Log(s.Mu/(dl+s.Mu))
+	}
+	return scores
+}
@@ -0,0 +1,152 @@
+package indexing
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func buildScorerFixture(t *testing.T) *PipeIndexProcessor {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	docs := map[string]map[string]uint64{
+		"1": {"粮食": 3, "保险": 1},
+		"2": {"粮食": 1, "保险": 2, "试点": 5, "农业": 4},
+		"3": {"财政": 4, "预算": 2},
+		"4": {"财政": 1, "补贴": 1},
+	}
+	for id, concordance := range docs {
+		assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: id, Concordance: concordance}, nil))
+	}
+	p.BuildTFIDF()
+	return p
+}
+
+func TestBM25Scorer(t *testing.T) {
+	p := buildScorerFixture(t)
+	s := p.Snapshot()
+	assert.Equal(t, uint64(24), s.TotalDocLength)
+	assert.Equal(t, 6.0, s.AvgDocLength)
+
+	b := 0.75
+	scorer, err := NewScorer(RankingBM25, &conf.IndexerConfig{BM25: &conf.BM25Config{K1: 1.2, B: &b}})
+	assert.Empty(t, err)
+	scores := scorer.Score(s, map[string]uint64{"粮食": 1})
+	assert.Equal(t, 2, len(scores))
+
+	idf := math.Log(1 + (4-2+0.5)/(2+0.5))
+	for docIdx, score := range scores {
+		v := s.Vectors[docIdx-1]
+		var tf float64
+		if v.DocID == "1" {
+			tf = 3
+		} else {
+			tf = 1
+		}
+		expected := idf * tf * 2.2 / (tf + 1.2*(0.25+0.75*float64(v.Length)/6.0))
+		assert.InDelta(t, expected, score, 1e-9)
+	}
+	assert.Equal(t, []string{"1", "2"}, docIDs(s.TopK(2, scorer, map[string]uint64{"粮食": 1})))
+}
+
+func TestBM25Config(t *testing.T) {
+	// 未声明b时使用默认值, 而不是关闭长度归一化
+	scorer, err := NewScorer(RankingBM25, &conf.IndexerConfig{BM25: &conf.BM25Config{K1: 1.5}})
+	assert.Empty(t, err)
+	assert.Equal(t, &BM25Scorer{K1: 1.5, B: _DefaultBM25B}, scorer)
+
+	b := 0.0
+	scorer, err = NewScorer(RankingBM25, &conf.IndexerConfig{BM25: &conf.BM25Config{B: &b}})
+	assert.Empty(t, err)
+	assert.Equal(t, &BM25Scorer{K1: _DefaultBM25K1, B: 0}, scorer)
+
+	b = 1.5
+	_, err = NewScorer(RankingBM25, &conf.IndexerConfig{BM25: &conf.BM25Config{B: &b}})
+	assert.Equal(t, true, errors.Is(err, ErrBadRankingConfig))
+	_, err = NewScorer(RankingBM25F, &conf.IndexerConfig{BM25F: &conf.BM25FConfig{NGram: &conf.BM25FFieldConfig{B: &b}}})
+	assert.Equal(t, true, errors.Is(err, ErrBadRankingConfig))
+	_, err = NewScorer(RankingBM25F, &conf.IndexerConfig{BM25F: &conf.BM25FConfig{Body: &conf.BM25FFieldConfig{Weight: -1}}})
+	assert.Equal(t, true, errors.Is(err, ErrBadRankingConfig))
+}
+
+func TestBM25FScorer(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	ngram := func(gram string) string { return common.NGramTerm(gram) }
+	docs := map[string]map[string]uint64{
+		"1": {"粮食": 1, ngram("粮食"): 1, ngram("粮"): 1, ngram("食"): 1},
+		"2": {"粮食": 1, "保险": 3, ngram("粮食"): 1, ngram("保险"): 3, ngram("粮"): 1, ngram("食"): 1, ngram("保"): 3, ngram("险"): 3},
+		"3": {"财政": 2, ngram("财政"): 2},
+	}
+	for id, concordance := range docs {
+		assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: id, Concordance: concordance}, nil))
+	}
+	p.BuildTFIDF()
+	s := p.Snapshot()
+	assert.Equal(t, [NumFields]float64{7.0 / 3, 17.0 / 3}, s.AvgFieldLength)
+
+	b := 0.5
+	scorer, err := NewScorer(RankingBM25F, &conf.IndexerConfig{BM25F: &conf.BM25FConfig{
+		K1:    1.2,
+		NGram: &conf.BM25FFieldConfig{Weight: 0.2, B: &b},
+	}})
+	assert.Empty(t, err)
+	assert.Equal(t, RankingBM25F, scorer.Name())
+	scores := scorer.Score(s, map[string]uint64{"粮食": 1, ngram("粮食"): 1})
+	assert.Equal(t, 2, len(scores))
+
+	// 两个字段分别按各自的平均长度归一化
+	idf := math.Log(1 + (3-2+0.5)/(2+0.5))
+	bm25f := func(tf, dl, avgdl, w, b float64) float64 {
+		tf = w * tf / (1 - b + b*dl/avgdl)
+		return idf * tf * 2.2 / (tf + 1.2)
+	}
+	for docIdx, score := range scores {
+		v := s.Vectors[docIdx-1]
+		expected := bm25f(1, float64(v.FieldLengths[FieldBody]), 7.0/3, 1.0, 0.75) +
+			bm25f(1, float64(v.FieldLengths[FieldNGram]), 17.0/3, 0.2, 0.5)
+		assert.InDelta(t, expected, score, 1e-9)
+	}
+	assert.Equal(t, []string{"1", "2"}, docIDs(s.TopK(2, scorer, map[string]uint64{"粮食": 1, ngram("粮食"): 1})))
+}
+
+func TestLMDirichletScorer(t *testing.T) {
+	p := buildScorerFixture(t)
+	s := p.Snapshot()
+
+	scorer, err := NewScorer(RankingLMDirichlet, &conf.IndexerConfig{LMDirichlet: &conf.LMDirichletConfig{Mu: 10}})
+	assert.Empty(t, err)
+	scores := scorer.Score(s, map[string]uint64{"财政": 1})
+	assert.Equal(t, 2, len(scores))
+
+	pc := 5.0 / 24.0
+	for docIdx, score := range scores {
+		v := s.Vectors[docIdx-1]
+		var tf float64
+		if v.DocID == "3" {
+			tf = 4
+		} else {
+			tf = 1
+		}
+		expected := math.Log(1+tf/(10*pc)) + math.Log(10/(float64(v.Length)+10))
+		assert.InDelta(t, expected, score, 1e-9)
+	}
+}
+
+func TestScorerLookup(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{Ranking: RankingBM25})
+
+	scorer, err := p.Scorer("")
+	assert.Empty(t, err)
+	assert.Equal(t, RankingBM25, scorer.Name())
+
+	scorer, err = p.Scorer(RankingTFIDF)
+	assert.Empty(t, err)
+	assert.Equal(t, RankingTFIDF, scorer.Name())
+
+	_, err = p.Scorer("pagerank")
+	assert.Equal(t, true, errors.Is(err, ErrUnknownRanking))
+}
@@ -0,0 +1,395 @@
+package indexing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+)
+
+// 段文件格式 (版本1):
+//
+//	header  | magic "PDVS" (4B) | version (2B) | reserved (2B) | crc32c of body (4B) | body length (8B) |
+//	body    | metadata | doc store | doc table | term dictionary | postings |
+//
+// 定长字段采用大端序, 其余整数均为uvarint编码.
+//
+//	metadata        Doc, LastDocIdx, Vocabulary, LastTermID, MaxTermFrequency
+//	doc store       位图字数, 以及逐字节写出的位图; 早期版本按文档ID记录文档的位图,
+//	                现已不再使用, 写入时位图字数为0, 读取时跳过
+//	doc table       文档数, 每个文档为 (文档序号增量, 文档ID长度, 文档ID), 按文档序号升序排列,
+//	                文档记录由doc table重建
+//	term dictionary 词条数, 每个词条为 (与前一词条的公共前缀长度, 后缀长度, 后缀, 词条序号, 文档频率,
+//	                倒排列表在postings中的偏移, 倒排列表长度), 按词条字典序排列
+//	postings        总长度, 以及所有倒排列表; 每个倒排列表按文档序号升序排列,
+//	                每条记录为 (文档序号增量, 词频, 位置个数, 位置增量...)
+const (
+	_SegmentMagic      = "PDVS"
+	_SegmentVersion    = 1
+	_SegmentHeaderSize = 20
+)
+
+var (
+	// ErrBadSegment 段文件损坏或格式不受支持错误
+	ErrBadSegment = errors.New("bad segment")
+
+	_CRC32Table = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// segmentTerm 段文件中的词条
+type segmentTerm struct {
+	term     string
+	termIdx  uint64
+	postings []*Posting
+}
+
+// segmentView 倒排索引的一致视图, 用于在不持锁的情况下编码段文件.
+// 倒排记录写入之后除Next之外不再修改, 因此视图只需引用倒排记录, 无需复制.
+type segmentView struct {
+	metadata Metadata
+	// 文档序号到文档ID的映射, 包含没有任何词条的文档
+	docIDs map[uint64]string
+	terms  []*segmentTerm
+	// 视图对应的自上次dump以来的写入次数
+	mutations uint64
+	// 视图已包含的预写日志的最大序列号
+	walLSN uint64
+	// 视图已包含的文档的确认句柄
+	acks []*common.Ack
+	// 文档分词时使用的词典版本
+	analyzer *analyzerState
+}
+
+// segmentView 在commitMu的写锁保护下获取倒排索引的一致视图, 只在收集引用期间阻塞写入.
+func (p *PipeIndexProcessor) segmentView() *segmentView {
+	p.commitMu.Lock()
+	defer p.commitMu.Unlock()
+
+	m := p.indexer.Metadata
+	view := &segmentView{
+		metadata: Metadata{
+			Doc:              m.Doc,
+			LastDocIdx:       m.LastDocIdx,
+			Vocabulary:       m.Vocabulary,
+			LastTermID:       m.LastTermID,
+			MaxTermFrequency: atomic.LoadUint64(&(m.MaxTermFrequency)),
+		},
+		terms:     make([]*segmentTerm, 0, m.Vocabulary),
+		mutations: atomic.LoadUint64(&(p.mutations)),
+	}
+	p.acksMu.Lock()
+	view.acks, p.acks = p.acks, nil
+	p.acksMu.Unlock()
+	if p.wal != nil {
+		// 持写锁期间没有进行中的写入, 已写入日志的记录均已作用于倒排索引
+		lsn, err := p.wal.rotate()
+		if err != nil {
+			log.Warn().Err(err).Msg("cannot rotate wal")
+		}
+		view.walLSN = lsn
+	}
+	view.analyzer = p.analyzerState()
+	p.docsMu.RLock()
+	view.docIDs = make(map[uint64]string, len(p.docs))
+	for docID, entry := range p.docs {
+		view.docIDs[entry.idx] = docID
+	}
+	p.docsMu.RUnlock()
+
+	for _, shard := range p.indexer.Dict {
+		shard.mu.RLock()
+		for term, pl := range shard.Backend {
+			termIdx, _ := strconv.ParseUint(pl.TermID, 10, 64)
+			st := &segmentTerm{term: term, termIdx: termIdx, postings: make([]*Posting, 0, pl.DocFrequency)}
+			for cur := pl.Postings.Next; cur != nil; cur = cur.Next {
+				st.postings = append(st.postings, cur)
+			}
+			view.terms = append(view.terms, st)
+		}
+		shard.mu.RUnlock()
+	}
+	return view
+}
+
+// encodeSegment 将倒排索引的视图编码为段文件.
+func encodeSegment(view *segmentView) []byte {
+	terms := view.terms
+	docIDs := view.docIDs
+	for _, st := range terms {
+		postings := st.postings
+		sort.Slice(postings, func(i, j int) bool {
+			return postings[i].DocIdx < postings[j].DocIdx
+		})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return terms[i].term < terms[j].term
+	})
+
+	m := &(view.metadata)
+	body := new(bytes.Buffer)
+	putUvarint(body, m.Doc)
+	putUvarint(body, m.LastDocIdx)
+	putUvarint(body, m.Vocabulary)
+	putUvarint(body, m.LastTermID)
+	putUvarint(body, m.MaxTermFrequency)
+
+	putUvarint(body, 0)
+
+	docIdxs := make([]uint64, 0, len(docIDs))
+	for docIdx := range docIDs {
+		docIdxs = append(docIdxs, docIdx)
+	}
+	sort.Slice(docIdxs, func(i, j int) bool {
+		return docIdxs[i] < docIdxs[j]
+	})
+	putUvarint(body, uint64(len(docIdxs)))
+	var prev uint64
+	for _, docIdx := range docIdxs {
+		putUvarint(body, docIdx-prev)
+		putString(body, docIDs[docIdx])
+		prev = docIdx
+	}
+
+	postings := new(bytes.Buffer)
+	putUvarint(body, uint64(len(terms)))
+	var prevTerm string
+	for _, st := range terms {
+		offset := postings.Len()
+		prev = 0
+		for _, posting := range st.postings {
+			putUvarint(postings, posting.DocIdx-prev)
+			putUvarint(postings, posting.TermFrequency)
+			putUvarint(postings, uint64(len(posting.Positions)))
+			var prevPosition uint32
+			for _, position := range posting.Positions {
+				putUvarint(postings, uint64(position-prevPosition))
+				prevPosition = position
+			}
+			prev = posting.DocIdx
+		}
+
+		shared := commonPrefix(prevTerm, st.term)
+		putUvarint(body, uint64(shared))
+		putString(body, st.term[shared:])
+		putUvarint(body, st.termIdx)
+		putUvarint(body, uint64(len(st.postings)))
+		putUvarint(body, uint64(offset))
+		putUvarint(body, uint64(postings.Len()-offset))
+		prevTerm = st.term
+	}
+	putUvarint(body, uint64(postings.Len()))
+	body.Write(postings.Bytes())
+
+	header := make([]byte, _SegmentHeaderSize)
+	copy(header[0:4], _SegmentMagic)
+	binary.BigEndian.PutUint16(header[4:6], _SegmentVersion)
+	binary.BigEndian.PutUint32(header[8:12], crc32.Checksum(body.Bytes(), _CRC32Table))
+	binary.BigEndian.PutUint64(header[12:20], uint64(body.Len()))
+	return append(header, body.Bytes()...)
+}
+
+// decodeSegment 解码段文件并替换当前的倒排索引, 解码失败时倒排索引保持不变.
+func (p *PipeIndexProcessor) decodeSegment(data []byte) error {
+	if len(data) < _SegmentHeaderSize {
+		return fmt.Errorf("%w: truncated header", ErrBadSegment)
+	}
+	if string(data[0:4]) != _SegmentMagic {
+		return fmt.Errorf("%w: bad magic", ErrBadSegment)
+	}
+	if version := binary.BigEndian.Uint16(data[4:6]); version != _SegmentVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrBadSegment, version)
+	}
+	body := data[_SegmentHeaderSize:]
+	if uint64(len(body)) != binary.BigEndian.Uint64(data[12:20]) {
+		return fmt.Errorf("%w: truncated body", ErrBadSegment)
+	}
+	if crc32.Checksum(body, _CRC32Table) != binary.BigEndian.Uint32(data[8:12]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrBadSegment)
+	}
+
+	r := &segmentReader{buf: body}
+	m := &Metadata{
+		Doc:              r.uvarint(),
+		LastDocIdx:       r.uvarint(),
+		Vocabulary:       r.uvarint(),
+		LastTermID:       r.uvarint(),
+		MaxTermFrequency: r.uvarint(),
+		VocabularyStore:  &VocabularyStore{BitSet: make([][]byte, 0)},
+	}
+
+	// 跳过早期版本写入的文档位图
+	r.bytes(8 * r.count(8))
+
+	n := r.count(2)
+	docIDs := make(map[uint64]string, n)
+	var docIdx uint64
+	for i := 0; i < n; i++ {
+		docIdx += r.uvarint()
+		docIDs[docIdx] = r.string()
+	}
+
+	dict := make([]*Shard, _Shards)
+	for idx := range dict {
+		dict[idx] = &Shard{Backend: make(map[string]*PostingList)}
+	}
+	type termEntry struct {
+		term     string
+		pl       *PostingList
+		offset   int
+		length   int
+		postings uint64
+	}
+	entries := make([]*termEntry, r.count(6))
+	var prevTerm string
+	for i := range entries {
+		shared := int(r.uvarint())
+		if shared > len(prevTerm) {
+			return fmt.Errorf("%w: bad term prefix", ErrBadSegment)
+		}
+		term := prevTerm[:shared] + r.string()
+		termIdx := r.uvarint()
+		entries[i] = &termEntry{
+			term: term,
+			pl: &PostingList{
+				TermID:   fmt.Sprintf("%010d", termIdx),
+				Postings: &Posting{},
+			},
+			postings: r.uvarint(),
+			offset:   int(r.uvarint()),
+			length:   int(r.uvarint()),
+		}
+		prevTerm = term
+	}
+	postings := r.bytes(r.count(1))
+	if r.err != nil {
+		return r.err
+	}
+	if r.pos != len(r.buf) {
+		return fmt.Errorf("%w: trailing bytes", ErrBadSegment)
+	}
+
+	for _, e := range entries {
+		if e.offset < 0 || e.length < 0 || e.offset+e.length > len(postings) {
+			return fmt.Errorf("%w: bad postings offset, term=%s", ErrBadSegment, e.term)
+		}
+		pr := &segmentReader{buf: postings[e.offset : e.offset+e.length]}
+		list := make([]*Posting, 0, e.postings)
+		docIdx = 0
+		for i := uint64(0); i < e.postings && pr.err == nil; i++ {
+			docIdx += pr.uvarint()
+			posting := &Posting{
+				DocIdx:        docIdx,
+				TermFrequency: pr.uvarint(),
+			}
+			if npos := pr.count(1); npos > 0 {
+				posting.Positions = make([]uint32, npos)
+				var position uint32
+				for j := range posting.Positions {
+					position += uint32(pr.uvarint())
+					posting.Positions[j] = position
+				}
+			}
+			var ok bool
+			if posting.DocID, ok = docIDs[docIdx]; !ok {
+				return fmt.Errorf("%w: unknown doc, doc_idx=%d", ErrBadSegment, docIdx)
+			}
+			list = append(list, posting)
+		}
+		if pr.err != nil {
+			return fmt.Errorf("%w, term=%s", pr.err, e.term)
+		}
+
+		// 恢复按词频降序排列的链表, 词频相同的文档按写入顺序即文档序号升序排列
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].TermFrequency > list[j].TermFrequency
+		})
+		cur := e.pl.Postings
+		for _, posting := range list {
+			cur.Next = posting
+			cur = posting
+		}
+		e.pl.DocFrequency = uint64(len(list))
+		m.VocabularyStore.set(e.pl.TermID)
+		dict[fnv_1a_32(e.term)&0x1f].Backend[e.term] = e.pl
+	}
+
+	p.indexer.Metadata = m
+	p.indexer.Dict = dict
+	p.rebuildDocs(docIDs)
+	return nil
+}
+
+// segmentReader 段文件读取器, 出错之后的读取均返回零值
+type segmentReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *segmentReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.err = fmt.Errorf("%w: bad varint at offset %d", ErrBadSegment, r.pos)
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+// count 读取元素个数, 每个元素至少占用size字节, 个数超出剩余字节数时视为损坏.
+func (r *segmentReader) count(size int) int {
+	v := r.uvarint()
+	if r.err == nil && v > uint64((len(r.buf)-r.pos)/size) {
+		r.err = fmt.Errorf("%w: bad length at offset %d", ErrBadSegment, r.pos)
+		return 0
+	}
+	return int(v)
+}
+
+func (r *segmentReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.buf)-r.pos {
+		r.err = fmt.Errorf("%w: truncated at offset %d", ErrBadSegment, r.pos)
+		return nil
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *segmentReader) string() string {
+	return string(r.bytes(r.count(1)))
+}
+
+func putUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
+
+func putString(buf *bytes.Buffer, s string) {
+	putUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// commonPrefix 返回两个词条公共前缀的字节数.
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,163 @@
+package indexing
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+// postingsOf 按链表顺序列出词条的倒排记录.
+func postingsOf(p *PipeIndexProcessor, term string) []Posting {
+	ret := make([]Posting, 0)
+	pl, ok := p.indexer.Dict[fnv_1a_32(term)&0x1f].Backend[term]
+	if !ok {
+		return ret
+	}
+	for cur := pl.Postings.Next; cur != nil; cur = cur.Next {
+		ret = append(ret, Posting{TermFrequency: cur.TermFrequency, DocIdx: cur.DocIdx, DocID: cur.DocID, Positions: cur.Positions})
+	}
+	return ret
+}
+
+func TestSegmentRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+
+	p := newTestIndexer(&conf.IndexerConfig{DumpPath: dir})
+	texts := []string{
+		"收入 保险 试点 工作 保险",
+		"粮食 作物 保险",
+		"财政 预算",
+		"农业 保险 补贴 保险 保险",
+	}
+	for i, text := range texts {
+		assert.Empty(t, p.indexing(newTestWrapper(fmt.Sprintf("%d", i+1), text), nil))
+	}
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "3", Operation: pb.DocOperation_DeleteDoc}, nil))
+	// 没有任何词条的文档
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1000000000000000"}, nil))
+	p.BuildTFIDF()
+	_, err = p.Dump()
+	assert.Empty(t, err)
+
+	q := newTestIndexer(&conf.IndexerConfig{DumpPath: dir})
+	assert.Empty(t, q.Load())
+	assert.Equal(t, p.GetDoc(), q.GetDoc())
+	assert.Equal(t, p.GetVocabulary(), q.GetVocabulary())
+	assert.Equal(t, p.indexer.Metadata.LastDocIdx, q.indexer.Metadata.LastDocIdx)
+	assert.Equal(t, p.indexer.Metadata.LastTermID, q.indexer.Metadata.LastTermID)
+	assert.Equal(t, p.indexer.Metadata.MaxTermFrequency, q.indexer.Metadata.MaxTermFrequency)
+	assert.Equal(t, true, q.indexer.Metadata.DocStore.exist("4"))
+	assert.Equal(t, false, q.indexer.Metadata.DocStore.exist("3"))
+	assert.Equal(t, true, q.indexer.Metadata.DocStore.exist("1000000000000000"))
+	for _, term := range []string{"保险", "收入", "作物", "补贴", "财政"} {
+		assert.Equal(t, postingsOf(p, term), postingsOf(q, term), term)
+		if pl, ok := q.indexer.Dict[fnv_1a_32(term)&0x1f].Backend[term]; ok {
+			assert.Equal(t, p.indexer.Dict[fnv_1a_32(term)&0x1f].Backend[term].TermID, pl.TermID)
+			assert.Equal(t, true, q.indexer.Metadata.VocabularyStore.exist(pl.TermID))
+		}
+	}
+
+	q.BuildTFIDF()
+	concordance := map[string]uint64{"保险": 1, "作物": 1}
+	assert.Equal(t, docIDs(p.Snapshot().TopK(10, &TFIDFScorer{}, concordance)), docIDs(q.Snapshot().TopK(10, &TFIDFScorer{}, concordance)))
+
+	// 加载之后可以继续删除与写入
+	assert.Empty(t, q.indexing(&common.ConcordanceWrapper{DocID: "1", Operation: pb.DocOperation_DeleteDoc}, nil))
+	assert.Equal(t, 0, len(postingsOf(q, "收入")))
+	assert.Empty(t, q.indexing(newTestWrapper("5", "收入 预算"), nil))
+	assert.Equal(t, uint64(6), q.indexer.Metadata.LastDocIdx)
+	assert.Empty(t, q.indexing(&common.ConcordanceWrapper{DocID: "1000000000000000", Operation: pb.DocOperation_DeleteDoc}, nil))
+	assert.Equal(t, false, q.indexer.Metadata.DocStore.exist("1000000000000000"))
+}
+
+func TestSegmentCorruption(t *testing.T) {
+	p := newTestIndexer(&conf.IndexerConfig{})
+	assert.Empty(t, p.indexing(newTestWrapper("1", "收入 保险 试点"), nil))
+	data := encodeSegment(p.segmentView())
+
+	corrupt := func(f func(b []byte) []byte) error {
+		b := f(append([]byte(nil), data...))
+		return newTestIndexer(&conf.IndexerConfig{}).decodeSegment(b)
+	}
+	assert.Empty(t, corrupt(func(b []byte) []byte { return b }))
+	for name, f := range map[string]func(b []byte) []byte{
+		"magic":     func(b []byte) []byte { b[0] = 'X'; return b },
+		"version":   func(b []byte) []byte { b[5] = 9; return b },
+		"checksum":  func(b []byte) []byte { b[len(b)-1] ^= 0xff; return b },
+		"truncated": func(b []byte) []byte { return b[:len(b)-3] },
+		"header":    func(b []byte) []byte { return b[:10] },
+	} {
+		err := corrupt(f)
+		assert.Equal(t, true, errors.Is(err, ErrBadSegment), name)
+	}
+
+	// 解码失败时倒排索引保持不变
+	assert.NotEmpty(t, p.decodeSegment(data[:len(data)-1]))
+	assert.Equal(t, 3, len(postingsOf(p, "收入"))+len(postingsOf(p, "保险"))+len(postingsOf(p, "试点")))
+}
+
+func TestMigrateJSONDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+
+	// 旧版本的索引文件: 两篇文档, 两个词条, 没有记录已分配的最大序号
+	metadata := `{"doc":2,"doc_store":{"bit_set":[[0,0,0,0,0,0,0,6]]},"vocabulary":2,` +
+		`"vocabulary_store":{"bit_set":[[0,0,0,0,0,0,0,6]]},"max_term_frequency":3}`
+	assert.Empty(t, ioutil.WriteFile(filepath.Join(dir, "metadata.json"), []byte(metadata), 0644))
+	for idx := 0; idx < _Shards; idx++ {
+		backend := make([]string, 0)
+		for _, term := range []string{"粮食", "保险"} {
+			if int(fnv_1a_32(term)&0x1f) != idx {
+				continue
+			}
+			if term == "粮食" {
+				backend = append(backend, `"粮食":{"term_id":"0000000001","doc_frequency":2,"postings":{"term_frequency":0,"doc_idx":0,"doc_id":"","next":`+
+					`{"term_frequency":3,"doc_idx":2,"doc_id":"2","next":{"term_frequency":1,"doc_idx":1,"doc_id":"1","next":null}}}}`)
+			} else {
+				backend = append(backend, `"保险":{"term_id":"0000000002","doc_frequency":1,"postings":{"term_frequency":0,"doc_idx":0,"doc_id":"","next":`+
+					`{"term_frequency":2,"doc_idx":1,"doc_id":"1","next":null}}}`)
+			}
+		}
+		shard := `{"backend":{`
+		for i, b := range backend {
+			if i > 0 {
+				shard += ","
+			}
+			shard += b
+		}
+		shard += `}}`
+		assert.Empty(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("term-indexing-%d.json", idx)), []byte(shard), 0644))
+	}
+
+	p := newTestIndexer(&conf.IndexerConfig{DumpPath: dir})
+	assert.Empty(t, p.Load())
+	_, err = os.Stat(filepath.Join(dir, "gen-0000000001", "index.seg"))
+	assert.Empty(t, err)
+	assert.Equal(t, uint64(2), p.indexer.Metadata.LastDocIdx)
+	assert.Equal(t, uint64(2), p.indexer.Metadata.LastTermID)
+
+	// 再次加载时直接读取段文件
+	assert.Empty(t, os.Remove(filepath.Join(dir, "metadata.json")))
+	q := newTestIndexer(&conf.IndexerConfig{DumpPath: dir})
+	assert.Empty(t, q.Load())
+	assert.Equal(t, []Posting{
+		{TermFrequency: 3, DocIdx: 2, DocID: "2"},
+		{TermFrequency: 1, DocIdx: 1, DocID: "1"},
+	}, postingsOf(q, "粮食"))
+	assert.Equal(t, uint64(2), q.GetDoc())
+	assert.Equal(t, uint64(2), q.GetVocabulary())
+	assert.Equal(t, true, q.indexer.Metadata.DocStore.exist("2"))
+	assert.ElementsMatch(t, []string{"粮食", "保险"}, q.docs["1"].terms)
+}
@@ -0,0 +1,441 @@
+package indexing
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stopword"
+)
+
+// 文档字段, 汉字n-gram子字段的词条带有common.NGramPrefix前缀, 与正文字段的词条互不相交
+const (
+	// FieldBody 词典分词得到的正文字段
+	FieldBody = iota
+	// FieldNGram 汉字n-gram子字段
+	FieldNGram
+	// NumFields 字段个数
+	NumFields
+)
+
+// FieldNames 字段名, 下标为字段
+var FieldNames = [NumFields]string{FieldBody: "body", FieldNGram: "ngram"}
+
+// FieldOf 返回词条所属的字段.
+func FieldOf(term string) int {
+	if common.IsNGramTerm(term) {
+		return FieldNGram
+	}
+	return FieldBody
+}
+
+// TFIDF TF-IDF数据结构
+// 每次构造都会生成一份只读快照, 发布之后不再修改, 查询全程只访问同一份快照.
+type TFIDF struct {
+	// 发布快照的索引器的纪元, 重启之后纪元随之改变
+	Epoch uint64
+	// 快照代数, 在同一纪元内每发布一次递增
+	Generation uint64
+	// 构造时的文档总量, 不包括已删除的文档
+	Doc              uint64
+	MaxTermFrequency uint64
+	// 所有文档长度之和, 即语料中的词条总数
+	TotalDocLength uint64
+	AvgDocLength   float64
+	// 各字段的平均长度, 分母为文档总量
+	AvgFieldLength [NumFields]float64
+	// 下标为文档序号减1
+	Vectors []*DocVector
+	// 冻结的倒排列表, 与倒排索引的后续写入相互隔离
+	Terms TermDict
+	// 构造快照时处理文档使用的停词表, 访问该快照的查询语句按其移除停词; 未设置停词表时为nil
+	StopWords *stopword.Set
+}
+
+// TermDict 快照中的倒排列表, 与倒排索引采用相同的分段方式.
+// 冻结之后的分段与倒排列表只读, 自上一份快照以来未被修改的分段与倒排列表在快照之间共享.
+type TermDict []map[string]*TermPostings
+
+// NewTermDict 新建空的TermDict.
+func NewTermDict() TermDict {
+	d := make(TermDict, _Shards)
+	for i := range d {
+		d[i] = make(map[string]*TermPostings)
+	}
+	return d
+}
+
+// Get 返回词条的倒排列表.
+func (d TermDict) Get(term string) (*TermPostings, bool) {
+	tp, ok := d[fnv_1a_32(term)&0x1f][term]
+	return tp, ok
+}
+
+// Add 添加词条的倒排列表, 只用于构造尚未发布的快照.
+func (d TermDict) Add(term string, tp *TermPostings) {
+	d[fnv_1a_32(term)&0x1f][term] = tp
+}
+
+// TermPostings 快照中的倒排列表, 冻结之后不再修改
+type TermPostings struct {
+	TermIdx      uint64
+	DocFrequency uint64
+	// 词条在整个语料中出现的总次数
+	CollectionFrequency uint64
+	Postings            []FrozenPosting
+}
+
+// FrozenPosting 快照中的信息单元
+type FrozenPosting struct {
+	DocIdx        uint64
+	TermFrequency uint64
+	// 位置列表在写入后不再修改, 快照与倒排索引共享同一份数据
+	Positions []uint32
+}
+
+// VectorEntry 稀疏向量的非零分量
+type VectorEntry struct {
+	TermIdx uint64
+	Weight  float32
+}
+
+// DocVector 文档向量, 采用稀疏表示, 分量按词条序号升序排列
+type DocVector struct {
+	DocID   string
+	Entries []VectorEntry
+	Norm    float64
+	// 文档长度, 即文档内所有词条的词频之和
+	Length uint64
+	// 各字段的长度, 之和为Length
+	FieldLengths [NumFields]uint64
+}
+
+// QueryVector 查询向量, 采用稀疏表示, 分量按词条序号升序排列
+type QueryVector struct {
+	Entries []VectorEntry
+	// 与Entries一一对应的词条, 用于定位倒排列表
+	Terms []string
+	Norm  float64
+}
+
+// SimilarObject 相似文档记录
+type SimilarObject struct {
+	DocID      string
+	DocIdx     uint64
+	Similarity float64
+	Index      int
+}
+
+// PriorityQueue 用于筛选TopK文档的优先队列
+type PriorityQueue []*SimilarObject
+
+func newEmptyTFIDF() *TFIDF {
+	return &TFIDF{
+		Vectors: make([]*DocVector, 0),
+		Terms:   NewTermDict(),
+	}
+}
+
+// Snapshot 返回最近一次发布的TF-IDF快照.
+func (p *PipeIndexProcessor) Snapshot() *TFIDF {
+	return p.snapshot.Load().(*TFIDF)
+}
+
+// BuildTFIDF 基于当前倒排索引构造新的TF-IDF快照, 并原子地替换已发布的快照.
+// 只在冻结倒排索引期间短暂阻塞写入, 构造文档向量期间索引可以继续写入, 查询继续访问旧快照.
+func (p *PipeIndexProcessor) BuildTFIDF() {
+	log.Info().Msg("start to build tf-idf ...")
+	tfidf, docIDs := p.freeze()
+	for i, docID := range docIDs {
+		tfidf.Vectors[i] = &DocVector{
+			DocID:   docID,
+			Entries: make([]VectorEntry, 0),
+		}
+	}
+	D := tfidf.Doc
+	var fieldLengths [NumFields]uint64
+	for _, shard := range tfidf.Terms {
+		for term, tp := range shard {
+			field := FieldOf(term)
+			idf := math.Log2(float64(D) / float64(tp.DocFrequency))
+			for _, posting := range tp.Postings {
+				v := tfidf.Vectors[posting.DocIdx-1]
+				v.Entries = append(v.Entries, VectorEntry{
+					TermIdx: tp.TermIdx,
+					Weight:  float32(posting.TermFrequency) * float32(idf),
+				})
+				v.Length += posting.TermFrequency
+				v.FieldLengths[field] += posting.TermFrequency
+				if posting.TermFrequency > tfidf.MaxTermFrequency {
+					tfidf.MaxTermFrequency = posting.TermFrequency
+				}
+			}
+			tfidf.TotalDocLength += tp.CollectionFrequency
+			fieldLengths[field] += tp.CollectionFrequency
+		}
+	}
+	for _, v := range tfidf.Vectors {
+		sortEntries(v.Entries)
+		v.Norm = norm(v.Entries)
+	}
+	if D > 0 {
+		tfidf.AvgDocLength = float64(tfidf.TotalDocLength) / float64(D)
+		for field, length := range fieldLengths {
+			tfidf.AvgFieldLength[field] = float64(length) / float64(D)
+		}
+	}
+	atomic.StoreUint64(&(p.indexer.Metadata.MaxTermFrequency), tfidf.MaxTermFrequency)
+	tfidf.Generation = atomic.AddUint64(&(p.generation), 1)
+	p.publish(tfidf)
+	log.Info().Msgf("tf-idf has been builded, generation=%d", tfidf.Generation)
+}
+
+// snapshotStopWords 返回最近写入的文档去停词时使用的停词表, 而不是冻结时的停词表,
+// 从而修改停词表之前写入、之后冻结的文档仍与查询语句一致. 没有记录版本的文档 (例如从dump或预写日志恢复的文档)
+// 按当前的停词表处理. 未设置停词表时返回nil.
+func (p *PipeIndexProcessor) snapshotStopWords() *stopword.Set {
+	if p.stopWords == nil {
+		return nil
+	}
+	if version, ok := p.stopWordsVersion.Load().(string); ok {
+		if set, ok := p.stopWords.Lookup(version); ok {
+			return set
+		}
+	}
+	return p.stopWords.Current()
+}
+
+// publish 发布快照, 并淘汰超出保留个数的旧快照.
+func (p *PipeIndexProcessor) publish(tfidf *TFIDF) {
+	retained := p.cfg.RetainedSnapshots
+	if retained <= 0 {
+		retained = _DefaultRetainedSnapshots
+	}
+
+	tfidf.Epoch = p.epoch
+	p.historyMu.Lock()
+	p.history = append(p.history, tfidf)
+	if len(p.history) > retained {
+		p.history = p.history[len(p.history)-retained:]
+	}
+	p.snapshot.Store(tfidf)
+	p.historyMu.Unlock()
+}
+
+// SnapshotAt 返回指定纪元与代数的快照, 快照已被淘汰或属于其他纪元 (例如重启之前) 时返回ErrSnapshotExpired.
+func (p *PipeIndexProcessor) SnapshotAt(epoch, generation uint64) (*TFIDF, error) {
+	if epoch != p.epoch {
+		return nil, fmt.Errorf("%w: epoch=%x, generation=%d", ErrSnapshotExpired, epoch, generation)
+	}
+	p.historyMu.RLock()
+	defer p.historyMu.RUnlock()
+	for _, tfidf := range p.history {
+		if tfidf.Generation == generation {
+			return tfidf, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: generation=%d", ErrSnapshotExpired, generation)
+}
+
+// freeze 在写锁保护下获取倒排索引的一致只读视图, 返回尚未构造文档向量的快照以及按文档序号排列的文档ID.
+// 自上一次冻结以来未被修改的分段与倒排列表直接复用已冻结的副本, 只重新复制被修改过的倒排列表,
+// 因此持锁时间以及保留的多份快照占用的内存都只与两次冻结之间的修改量相关, 而与索引规模无关.
+func (p *PipeIndexProcessor) freeze() (*TFIDF, []string) {
+	p.commitMu.Lock()
+	defer p.commitMu.Unlock()
+
+	// 文档向量按文档序号寻址, 已删除文档的文档ID为空
+	slots := atomic.LoadUint64(&(p.indexer.Metadata.LastDocIdx))
+	tfidf := &TFIDF{
+		Doc:     p.GetDoc(),
+		Vectors: make([]*DocVector, slots),
+		Terms:   make(TermDict, len(p.indexer.Dict)),
+	}
+	tfidf.StopWords = p.snapshotStopWords()
+	docIDs := make([]string, slots)
+	p.docsMu.RLock()
+	for docID, entry := range p.docs {
+		docIDs[entry.idx-1] = docID
+	}
+	p.docsMu.RUnlock()
+
+	for i, shard := range p.indexer.Dict {
+		shard.mu.Lock()
+		if shard.frozen == nil {
+			frozen := make(map[string]*TermPostings, len(shard.Backend))
+			for term, pl := range shard.Backend {
+				if pl.frozen == nil {
+					pl.frozen = pl.freeze()
+				}
+				frozen[term] = pl.frozen
+			}
+			shard.frozen = frozen
+		}
+		tfidf.Terms[i] = shard.frozen
+		shard.mu.Unlock()
+	}
+	return tfidf, docIDs
+}
+
+// freeze 复制倒排列表, 位置列表在写入后不再修改, 直接共享.
+func (pl *PostingList) freeze() *TermPostings {
+	termIdx, _ := strconv.ParseUint(pl.TermID, 10, 64)
+	tp := &TermPostings{
+		TermIdx:      termIdx,
+		DocFrequency: pl.DocFrequency,
+		Postings:     make([]FrozenPosting, 0, pl.DocFrequency),
+	}
+	for cur := pl.Postings.Next; cur != nil; cur = cur.Next {
+		tp.Postings = append(tp.Postings, FrozenPosting{
+			DocIdx:        cur.DocIdx,
+			TermFrequency: cur.TermFrequency,
+			Positions:     cur.Positions,
+		})
+		tp.CollectionFrequency += cur.TermFrequency
+	}
+	return tp
+}
+
+// BuildQueryVector 构造查询向量.
+func (t *TFIDF) BuildQueryVector(concordance map[string]uint64) *QueryVector {
+	q := &QueryVector{
+		Entries: make([]VectorEntry, 0, len(concordance)),
+		Terms:   make([]string, 0, len(concordance)),
+	}
+	for term, freq := range concordance {
+		if tp, ok := t.Terms.Get(term); ok {
+			q.Entries = append(q.Entries, VectorEntry{
+				TermIdx: tp.TermIdx,
+				Weight:  (0.5 + (0.5*float32(freq))/float32(t.MaxTermFrequency)) * float32(math.Log2(float64(t.Doc)/float64(tp.DocFrequency))),
+			})
+			q.Terms = append(q.Terms, term)
+		}
+	}
+	sort.Sort(q)
+	q.Norm = norm(q.Entries)
+	return q
+}
+
+// TopK 用指定的排序函数为文档打分, 并按得分降序返回得分最高的k个文档.
+// 排序函数只遍历查询词条对应的倒排列表, 查询耗时与倒排列表长度相关, 而与文档总量无关.
+func (t *TFIDF) TopK(k uint32, scorer Scorer, concordance map[string]uint64) []*SimilarObject {
+	ret, _ := t.Window(0, k, scorer, &Query{Concordance: concordance})
+	return ret
+}
+
+// Window 返回按得分降序排列的第[offset, offset+limit)个文档, 以及命中文档总数.
+// 得分相同的文档按文档序号升序排列, 保证同一快照上的分页结果稳定且互不重叠.
+// 查询带有短语或候选文档集合时, 只保留满足条件的文档, 并按短语命中次数提升得分.
+func (t *TFIDF) Window(offset, limit uint32, scorer Scorer, q *Query) ([]*SimilarObject, uint64) {
+	scores := scorer.Score(t, q.Concordance)
+	if q.Candidates != nil || len(q.Phrases) > 0 {
+		t.filter(scores, q)
+	}
+	total := uint64(len(scores))
+
+	k := uint64(offset) + uint64(limit)
+	if limit == 0 || uint64(offset) >= total {
+		return make([]*SimilarObject, 0), total
+	}
+
+	h := new(PriorityQueue)
+	heap.Init(h)
+
+	for docIdx, score := range scores {
+		y := &SimilarObject{DocID: t.Vectors[docIdx-1].DocID, DocIdx: docIdx, Similarity: score}
+		if uint64(h.Len()) >= k {
+			if worse(h.Peek(), y) {
+				(*h)[0] = y
+				heap.Fix(h, 0)
+			}
+		} else {
+			heap.Push(h, y)
+		}
+	}
+
+	// 小顶堆依次弹出的是升序序列, 逆序填充得到降序结果
+	ret := make([]*SimilarObject, h.Len())
+	for i := len(ret) - 1; i >= 0; i-- {
+		ret[i] = heap.Pop(h).(*SimilarObject)
+	}
+
+	return ret[offset:], total
+}
+
+func (q *QueryVector) Len() int {
+	return len(q.Entries)
+}
+
+func (q *QueryVector) Less(i, j int) bool {
+	return q.Entries[i].TermIdx < q.Entries[j].TermIdx
+}
+
+func (q *QueryVector) Swap(i, j int) {
+	q.Entries[i], q.Entries[j] = q.Entries[j], q.Entries[i]
+	q.Terms[i], q.Terms[j] = q.Terms[j], q.Terms[i]
+}
+
+// sortEntries 将稀疏向量的分量按词条序号升序排列.
+func sortEntries(entries []VectorEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TermIdx < entries[j].TermIdx
+	})
+}
+
+// norm 计算稀疏向量的模.
+func norm(entries []VectorEntry) float64 {
+	var sum float64
+	for _, e := range entries {
+		sum += float64(e.Weight) * float64(e.Weight)
+	}
+	return math.Sqrt(sum)
+}
+
+func (pq PriorityQueue) Len() int {
+	return len(pq)
+}
+
+func (pq PriorityQueue) Less(i, j int) bool {
+	return worse(pq[i], pq[j])
+}
+
+func (pq PriorityQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].Index = i
+	pq[j].Index = j
+}
+
+// Peek 返回堆顶, 即当前最差的文档.
+func (pq PriorityQueue) Peek() *SimilarObject {
+	return pq[0]
+}
+
+func (pq *PriorityQueue) Push(x interface{}) {
+	n := len(*pq)
+	item := x.(*SimilarObject)
+	item.Index = n
+	*pq = append(*pq, item)
+}
+
+func (pq *PriorityQueue) Pop() interface{} {
+	old := *pq
+	n := len(old)
+	item := old[n-1]
+	item.Index = -1
+	*pq = old[0 : n-1]
+	return item
+}
+
+// worse 判断文档a的排名是否在文档b之后: 得分更低, 或得分相同但文档序号更大.
+func worse(a, b *SimilarObject) bool {
+	if a.Similarity != b.Similarity {
+		return a.Similarity < b.Similarity
+	}
+	return a.DocIdx > b.DocIdx
+}
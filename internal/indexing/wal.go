@@ -0,0 +1,392 @@
+package indexing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+)
+
+// 预写日志位于DumpPath/wal下, 由若干个以首条记录序列号命名的文件组成:
+//
+//	wal/00000000000000000001.log
+//	wal/00000000000000000731.log
+//
+// 每条记录的格式为:
+//
+//	| body length (4B) | crc32c of body (4B) | body |
+//
+// body依次为 (序列号, 操作类型, 文档ID长度, 文档ID, 词条数,
+// 每个词条为 (词条长度, 词条, 词频, 位置个数, 位置增量...)), 整数均为uvarint编码.
+//
+// 每次dump获取视图时切换到新的文件, dump成功之后删除已被快照覆盖的文件;
+// 加载时在快照之上按序重放序列号大于快照序列号的记录.
+const (
+	_WALDir             = "wal"
+	_WALFileSuffix      = ".log"
+	_WALRecordHeaderLen = 8
+)
+
+var (
+	// ErrBadWALRecord 预写日志记录损坏错误
+	ErrBadWALRecord = errors.New("bad wal record")
+	// ErrStaleWAL 不加载dump时遗留了尚未重放的预写日志错误
+	ErrStaleWAL = errors.New("stale wal")
+	// ErrWALGap 预写日志的序列号不连续错误, 缺失的记录已被确认, 无法再次消费
+	ErrWALGap = errors.New("wal sequence gap")
+)
+
+// writeAheadLog 索引操作的预写日志
+type writeAheadLog struct {
+	mu  sync.Mutex
+	dir string
+	// 已分配的最大序列号
+	lsn uint64
+	fw  *os.File
+}
+
+// openWAL 在dir下新建以lsn+1命名的文件用于追加记录.
+func openWAL(dir string, lsn uint64) (*writeAheadLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	w := &writeAheadLog{dir: dir, lsn: lsn}
+	if err := w.rotateLocked(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// append 写入一条记录并落盘, 返回记录的序列号.
+func (w *writeAheadLog) append(packet *common.ConcordanceWrapper) (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fw == nil {
+		return 0, os.ErrClosed
+	}
+	lsn := w.lsn + 1
+	body := encodeWALRecord(lsn, packet)
+	record := make([]byte, _WALRecordHeaderLen+len(body))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(body)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(body, _CRC32Table))
+	copy(record[_WALRecordHeaderLen:], body)
+
+	if _, err := w.fw.Write(record); err != nil {
+		return 0, err
+	}
+	if err := w.fw.Sync(); err != nil {
+		return 0, err
+	}
+	w.lsn = lsn
+	return lsn, nil
+}
+
+// rotate 关闭当前文件并新建文件, 返回切换前已分配的最大序列号.
+// 调用方需保证切换期间没有进行中的写入.
+func (w *writeAheadLog) rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.lsn, w.rotateLocked()
+}
+
+func (w *writeAheadLog) rotateLocked() error {
+	fw, err := os.OpenFile(fWALFile(w.dir, w.lsn+1), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if err = syncDir(w.dir); err != nil {
+		fw.Close() // nolint
+		return err
+	}
+	if w.fw != nil {
+		w.fw.Close() // nolint
+	}
+	w.fw = fw
+	return nil
+}
+
+// truncate 删除所有记录的序列号均不大于lsn的文件.
+func (w *writeAheadLog) truncate(lsn uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	files, err := listWALFiles(w.dir)
+	if err != nil {
+		return err
+	}
+	// 文件中最大的序列号为下一个文件的首条序列号减一, 当前文件不会被删除
+	for i := 0; i+1 < len(files); i++ {
+		if files[i+1]-1 > lsn {
+			break
+		}
+		if err = os.Remove(fWALFile(w.dir, files[i])); err != nil {
+			return err
+		}
+	}
+	return syncDir(w.dir)
+}
+
+func (w *writeAheadLog) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fw == nil {
+		return nil
+	}
+	err := w.fw.Close()
+	w.fw = nil
+	return err
+}
+
+// replayWAL 按序读取dir下序列号大于lsn的记录并交给fn处理, 返回读到的最大序列号.
+// 末尾写了一半的记录会被截掉, 其余位置的损坏视为错误.
+// 序列号不连续 (例如最新的dump损坏, 回退到更早的一代时其后的日志已被删除) 时返回ErrWALGap,
+// skipGap为true时只记录告警并跳过缺失的记录.
+func replayWAL(dir string, lsn uint64, skipGap bool, fn func(lsn uint64, packet *common.ConcordanceWrapper)) (uint64, error) {
+	files, err := listWALFiles(dir)
+	if err != nil {
+		return lsn, err
+	}
+	last := lsn
+	for i, first := range files {
+		if i+1 < len(files) && files[i+1]-1 <= lsn {
+			continue
+		}
+		path := fWALFile(dir, first)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return last, err
+		}
+
+		pos := 0
+		for pos < len(data) {
+			recLSN, packet, n, err := decodeWALRecord(data[pos:])
+			if err != nil {
+				if i+1 < len(files) {
+					return last, fmt.Errorf("%w: file=%s, offset=%d", err, path, pos)
+				}
+				log.Warn().Err(err).Msgf("truncate torn wal tail, file=%s, offset=%d", path, pos)
+				if err = os.Truncate(path, int64(pos)); err != nil {
+					return last, err
+				}
+				break
+			}
+			pos += n
+			if recLSN <= last {
+				continue
+			}
+			if recLSN != last+1 {
+				if !skipGap {
+					return last, fmt.Errorf("%w: expected=%d, got=%d, file=%s, set indexer.reset_wal to skip the missing records",
+						ErrWALGap, last+1, recLSN, path)
+				}
+				log.Warn().Msgf("skip wal sequence gap, expected=%d, got=%d", last+1, recLSN)
+			}
+			fn(recLSN, packet)
+			last = recLSN
+		}
+	}
+	return last, nil
+}
+
+// listWALFiles 按首条序列号升序列出dir下的日志文件.
+func listWALFiles(dir string) ([]uint64, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	files := make([]uint64, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), _WALFileSuffix) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), _WALFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, first)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i] < files[j]
+	})
+	return files, nil
+}
+
+func fWALFile(dir string, first uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", first, _WALFileSuffix))
+}
+
+func encodeWALRecord(lsn uint64, packet *common.ConcordanceWrapper) []byte {
+	body := new(bytes.Buffer)
+	putUvarint(body, lsn)
+	putUvarint(body, uint64(packet.Operation))
+	putString(body, packet.DocID)
+
+	terms := make([]string, 0, len(packet.Concordance))
+	for term := range packet.Concordance {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	putUvarint(body, uint64(len(terms)))
+	for _, term := range terms {
+		putString(body, term)
+		putUvarint(body, packet.Concordance[term])
+		positions := packet.Positions[term]
+		putUvarint(body, uint64(len(positions)))
+		var prev uint32
+		for _, pos := range positions {
+			putUvarint(body, uint64(pos-prev))
+			prev = pos
+		}
+	}
+	putString(body, packet.DictVersion)
+	return body.Bytes()
+}
+
+// decodeWALRecord 解析data开头的一条记录, 返回其序列号、索引操作以及占用的字节数.
+func decodeWALRecord(data []byte) (uint64, *common.ConcordanceWrapper, int, error) {
+	if len(data) < _WALRecordHeaderLen {
+		return 0, nil, 0, fmt.Errorf("%w: truncated header", ErrBadWALRecord)
+	}
+	length := int(binary.BigEndian.Uint32(data[0:4]))
+	if length > len(data)-_WALRecordHeaderLen {
+		return 0, nil, 0, fmt.Errorf("%w: %v", ErrBadWALRecord, io.ErrUnexpectedEOF)
+	}
+	body := data[_WALRecordHeaderLen : _WALRecordHeaderLen+length]
+	if crc32.Checksum(body, _CRC32Table) != binary.BigEndian.Uint32(data[4:8]) {
+		return 0, nil, 0, fmt.Errorf("%w: checksum mismatch", ErrBadWALRecord)
+	}
+
+	r := &segmentReader{buf: body}
+	lsn := r.uvarint()
+	operation := pb.DocOperation(r.uvarint())
+	packet := common.NewConcordanceWrapper(r.string())
+	packet.Operation = operation
+	n := r.count(3)
+	for i := 0; i < n && r.err == nil; i++ {
+		term := r.string()
+		packet.Concordance[term] = r.uvarint()
+		npos := r.count(1)
+		if npos == 0 {
+			continue
+		}
+		positions := make([]uint32, npos)
+		var prev uint32
+		for j := range positions {
+			prev += uint32(r.uvarint())
+			positions[j] = prev
+		}
+		packet.Positions[term] = positions
+	}
+	if r.err == nil && r.pos < len(r.buf) {
+		// 早期的记录不含词典版本
+		packet.DictVersion = r.string()
+	}
+	if r.err != nil {
+		return 0, nil, 0, fmt.Errorf("%w: %v", ErrBadWALRecord, r.err)
+	}
+	return lsn, packet, _WALRecordHeaderLen + length, nil
+}
+
+// recoverWAL 在已加载的dump之上重放序列号大于lsn的记录, 之后的写入追加到新的日志文件.
+// 日志不能接续dump时拒绝启动, 只有配置了ResetWAL时才跳过缺失的记录.
+func (p *PipeIndexProcessor) recoverWAL(lsn uint64) error {
+	dir := p.fWALDir()
+	var replayed uint64
+	last, err := replayWAL(dir, lsn, p.cfg.ResetWAL, func(_ uint64, packet *common.ConcordanceWrapper) {
+		// 加载期间没有并发的写入, 无需加锁
+		if err := p.apply(packet); err != nil {
+			log.Warn().Err(err).Msgf("cannot replay wal record, doc_id=%s", packet.DocID)
+		}
+		replayed++
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("replay wal, from=%d, to=%d, records=%d", lsn, last, replayed)
+
+	w, err := openWAL(dir, last)
+	if err != nil {
+		return err
+	}
+	p.wal = w
+	return nil
+}
+
+// OpenWAL 从空索引开始记录预写日志, 用于不加载dump的场景. 未开启预写日志时什么也不做.
+// 日志中的文档在写入时即已被确认, 不会再被消费, 因此遗留的日志中有记录时拒绝启动并返回ErrStaleWAL,
+// 只有配置了ResetWAL时才丢弃遗留的日志.
+func (p *PipeIndexProcessor) OpenWAL() error {
+	if !p.cfg.EnableWAL {
+		return nil
+	}
+	dir := p.fWALDir()
+	stale, err := hasWALRecords(dir)
+	if err != nil {
+		return err
+	}
+	if stale {
+		if !p.cfg.ResetWAL {
+			return fmt.Errorf("%w: path=%s, set indexer.load to replay it or indexer.reset_wal to discard it", ErrStaleWAL, dir)
+		}
+		log.Warn().Msgf("discard stale wal, path=%s", dir)
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return err
+	}
+	w, err := openWAL(dir, 0)
+	if err != nil {
+		return err
+	}
+	p.wal = w
+	return nil
+}
+
+// hasWALRecords 判断dir下的日志文件中是否有记录.
+func hasWALRecords(dir string) (bool, error) {
+	files, err := listWALFiles(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, first := range files {
+		info, err := os.Stat(fWALFile(dir, first))
+		if err != nil {
+			return false, err
+		}
+		if info.Size() > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CloseWAL 关闭预写日志.
+func (p *PipeIndexProcessor) CloseWAL() error {
+	if p.wal == nil {
+		return nil
+	}
+	return p.wal.close()
+}
+
+func (p *PipeIndexProcessor) fWALDir() string {
+	return filepath.Join(p.cfg.DumpPath, _WALDir)
+}
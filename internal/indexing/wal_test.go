@@ -0,0 +1,145 @@
+package indexing
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func TestWALReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	cfg := &conf.IndexerConfig{DumpPath: dir, EnableWAL: true}
+
+	p := newTestIndexer(cfg)
+	assert.Empty(t, p.Load())
+	assert.Empty(t, p.indexing(newTestWrapper("1", "收入 保险 试点"), nil))
+	assert.Empty(t, p.indexing(newTestWrapper("2", "粮食 作物 保险"), nil))
+	_, err = p.Dump()
+	assert.Empty(t, err)
+	// dump之后只保留新的日志文件
+	files, err := listWALFiles(p.fWALDir())
+	assert.Empty(t, err)
+	assert.Equal(t, []uint64{3}, files)
+
+	assert.Empty(t, p.indexing(newTestWrapper("3", "农业 保险 补贴 保险"), nil))
+	assert.Empty(t, p.indexing(&common.ConcordanceWrapper{DocID: "1", Operation: pb.DocOperation_DeleteDoc}, nil))
+	upsert := newTestWrapper("2", "粮食 补贴")
+	upsert.Operation = pb.DocOperation_UpsertDoc
+	assert.Empty(t, p.indexing(upsert, nil))
+	// 模拟崩溃, 最后一次dump之后的写入只存在于日志中
+	assert.Empty(t, p.CloseWAL())
+
+	q := newTestIndexer(cfg)
+	assert.Empty(t, q.Load())
+	assert.Equal(t, p.GetDoc(), q.GetDoc())
+	assert.Equal(t, p.GetVocabulary(), q.GetVocabulary())
+	for _, term := range []string{"收入", "保险", "试点", "粮食", "作物", "农业", "补贴"} {
+		assert.Equal(t, postingsOf(p, term), postingsOf(q, term), term)
+	}
+	assert.Equal(t, uint64(3), q.DocsSinceDump())
+
+	// 重放之后的写入接着分配序列号
+	assert.Empty(t, q.indexing(newTestWrapper("4", "财政 预算"), nil))
+	assert.Empty(t, q.CloseWAL())
+	r := newTestIndexer(cfg)
+	assert.Empty(t, r.Load())
+	assert.Equal(t, uint64(3), r.GetDoc())
+	assert.Equal(t, 1, len(postingsOf(r, "预算")))
+	assert.Empty(t, r.CloseWAL())
+}
+
+func TestWALTornTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	cfg := &conf.IndexerConfig{DumpPath: dir, EnableWAL: true}
+
+	p := newTestIndexer(cfg)
+	assert.Empty(t, p.Load())
+	assert.Empty(t, p.indexing(newTestWrapper("1", "收入 保险"), nil))
+	assert.Empty(t, p.indexing(newTestWrapper("2", "粮食 保险"), nil))
+	assert.Empty(t, p.CloseWAL())
+
+	// 模拟写入最后一条记录期间崩溃
+	fn := fWALFile(p.fWALDir(), 1)
+	data, err := ioutil.ReadFile(fn)
+	assert.Empty(t, err)
+	assert.Empty(t, ioutil.WriteFile(fn, data[:len(data)-3], 0644))
+
+	q := newTestIndexer(cfg)
+	assert.Empty(t, q.Load())
+	assert.Equal(t, uint64(1), q.GetDoc())
+	assert.Equal(t, []Posting{{TermFrequency: 1, DocIdx: 1, DocID: "1", Positions: []uint32{1}}}, postingsOf(q, "保险"))
+	assert.Empty(t, q.CloseWAL())
+	info, err := os.Stat(fn)
+	assert.Empty(t, err)
+	assert.Less(t, info.Size(), int64(len(data)-3))
+
+	// 不加载dump时拒绝丢弃遗留的日志, 除非显式要求
+	r := newTestIndexer(cfg)
+	assert.Equal(t, true, errors.Is(r.OpenWAL(), ErrStaleWAL))
+	info, err = os.Stat(fn)
+	assert.Empty(t, err)
+	assert.NotEqual(t, int64(0), info.Size())
+
+	r = newTestIndexer(&conf.IndexerConfig{DumpPath: dir, EnableWAL: true, ResetWAL: true})
+	assert.Empty(t, r.OpenWAL())
+	files, err := listWALFiles(filepath.Join(dir, _WALDir))
+	assert.Empty(t, err)
+	assert.Equal(t, []uint64{1}, files)
+	info, err = os.Stat(fn)
+	assert.Empty(t, err)
+	assert.Equal(t, int64(0), info.Size())
+	assert.Empty(t, r.CloseWAL())
+
+	// 日志中没有记录时可以直接开始
+	r = newTestIndexer(cfg)
+	assert.Empty(t, r.OpenWAL())
+	assert.Empty(t, r.CloseWAL())
+}
+
+func TestWALGapAfterFallback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	cfg := &conf.IndexerConfig{DumpPath: dir, EnableWAL: true}
+
+	p := newTestIndexer(cfg)
+	assert.Empty(t, p.Load())
+	assert.Empty(t, p.indexing(newTestWrapper("1", "收入 保险"), nil))
+	_, err = p.Dump()
+	assert.Empty(t, err)
+	assert.Empty(t, p.indexing(newTestWrapper("2", "粮食 保险"), nil))
+	generation, err := p.Dump()
+	assert.Empty(t, err)
+	assert.Empty(t, p.indexing(newTestWrapper("3", "农业 补贴"), nil))
+	assert.Empty(t, p.CloseWAL())
+
+	// 最新的一代损坏, 回退到更早的一代之后, 文档2的日志已被删除
+	fn := filepath.Join(p.fGeneration(generation), _SegmentFile)
+	data, err := ioutil.ReadFile(fn)
+	assert.Empty(t, err)
+	data[len(data)-1] ^= 0xff
+	assert.Empty(t, ioutil.WriteFile(fn, data, 0644))
+
+	q := newTestIndexer(cfg)
+	assert.Equal(t, true, errors.Is(q.Load(), ErrWALGap))
+
+	// 显式要求时跳过缺失的记录
+	r := newTestIndexer(&conf.IndexerConfig{DumpPath: dir, EnableWAL: true, ResetWAL: true})
+	assert.Empty(t, r.Load())
+	assert.Equal(t, uint64(2), r.GetDoc())
+	assert.Equal(t, 0, len(postingsOf(r, "粮食")))
+	assert.Equal(t, 1, len(postingsOf(r, "农业")))
+	assert.Empty(t, r.CloseWAL())
+}
@@ -12,11 +12,72 @@ import (
 	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
 )
 
+// Message 待处理的消息, 处理完毕之后需调用Done, 否则其所在分区的位移不会再向前提交.
+type Message struct {
+	*sarama.ConsumerMessage
+	tracker *offsetTracker
+}
+
+// Done 标记消息已处理完毕, 分区内在它之前的消息全部处理完毕时提交位移 (并发安全).
+func (m *Message) Done() {
+	m.tracker.done(m.Offset)
+}
+
+// offsetTracker 跟踪单个分区中已投递但尚未处理完毕的消息.
+// 消息可能乱序处理完毕, 位移只提交到连续处理完毕的最大位移, 以保证至少一次投递.
+type offsetTracker struct {
+	mu        sync.Mutex
+	session   sarama.ConsumerGroupSession
+	topic     string
+	partition int32
+	// 按投递顺序排列的未提交位移
+	pending []int64
+	// 已处理完毕但尚未提交的位移
+	finished map[int64]struct{}
+}
+
+func newOffsetTracker(session sarama.ConsumerGroupSession, topic string, partition int32) *offsetTracker {
+	return &offsetTracker{
+		session:   session,
+		topic:     topic,
+		partition: partition,
+		pending:   make([]int64, 0),
+		finished:  make(map[int64]struct{}),
+	}
+}
+
+func (t *offsetTracker) add(offset int64) {
+	t.mu.Lock()
+	t.pending = append(t.pending, offset)
+	t.mu.Unlock()
+}
+
+func (t *offsetTracker) done(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.finished[offset] = struct{}{}
+	var n int
+	for n < len(t.pending) {
+		if _, ok := t.finished[t.pending[n]]; !ok {
+			break
+		}
+		delete(t.finished, t.pending[n])
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	// 与MarkMessage一致, 提交的是下一条待消费消息的位移
+	t.session.MarkOffset(t.topic, t.partition, t.pending[n-1]+1, "")
+	t.pending = t.pending[n:]
+}
+
 // CustomConsumerGroupHandler 自定义消费句柄
 type CustomConsumerGroupHandler struct {
 	once    sync.Once
 	cfg     *conf.KafkaConfig
-	msgCh   chan *sarama.ConsumerMessage
+	msgCh   chan *Message
 	readyCh chan struct{}
 	// 用于调控进程集合对主题和分区的分治
 	consumerGroup sarama.ConsumerGroup
@@ -24,7 +85,7 @@ type CustomConsumerGroupHandler struct {
 }
 
 // Msg 返回消费通道.
-func (h *CustomConsumerGroupHandler) Msg() <-chan *sarama.ConsumerMessage {
+func (h *CustomConsumerGroupHandler) Msg() <-chan *Message {
 	return h.msgCh
 }
 
@@ -45,7 +106,9 @@ func (h *CustomConsumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession
 
 // ConsumeClaim sarama.ConsumerGroupHandler接口定义实现.
 // ConsumeClaim() hook is called for each of the assigned claims.
+// 消息的位移在下游调用Message.Done之后才会提交.
 func (h *CustomConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	tracker := newOffsetTracker(session, claim.Topic(), claim.Partition())
 	for {
 		select {
 		case <-session.Context().Done():
@@ -59,8 +122,13 @@ func (h *CustomConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSe
 					log.Warn().Msg("consumer group claim, messages channel closed")
 					return nil
 				}
-				h.msgCh <- msg
-				session.MarkMessage(msg, "")
+				tracker.add(msg.Offset)
+				select {
+				case h.msgCh <- &Message{ConsumerMessage: msg, tracker: tracker}:
+				case <-session.Context().Done():
+					log.Info().Msg("consumer group claim, context done")
+					return nil
+				}
 			}
 		}
 	}
@@ -76,7 +144,7 @@ func (h *CustomConsumerGroupHandler) Close() {
 func NewCustomConsumerGroupHandler(cfg *conf.KafkaConfig) (*CustomConsumerGroupHandler, error) {
 	h := &CustomConsumerGroupHandler{
 		cfg:     cfg,
-		msgCh:   make(chan *sarama.ConsumerMessage),
+		msgCh:   make(chan *Message),
 		readyCh: make(chan struct{}),
 	}
 
@@ -167,6 +235,48 @@ func (h *CustomConsumerGroupHandler) start(ctx context.Context, topics []string)
 	}
 }
 
+// NewSyncProducer 新建同步生产者.
+func NewSyncProducer(cfg *conf.KafkaConfig) (sarama.SyncProducer, error) {
+	var err error
+	sc := sarama.NewConfig()
+	sc.Version, err = sarama.ParseKafkaVersion(cfg.Version)
+	if err != nil {
+		log.Error().Err(err).Msgf("unsupported kafka version %s", cfg.Version)
+		return nil, err
+	}
+	sc.Producer.RequiredAcks = sarama.WaitForAll
+	sc.Producer.Return.Successes = true
+	setKafkaAccessSettings(sc)
+
+	producer, err := sarama.NewSyncProducer(cfg.Brokers, sc)
+	if err != nil {
+		log.Error().Err(err).Msgf("cannot create producer for brokers <%v>", cfg.Brokers)
+		return nil, err
+	}
+	return producer, nil
+}
+
+// NewClient 新建Kafka客户端, 没有已提交的位移时从最早的消息开始消费.
+func NewClient(cfg *conf.KafkaConfig) (sarama.Client, error) {
+	var err error
+	sc := sarama.NewConfig()
+	sc.Version, err = sarama.ParseKafkaVersion(cfg.Version)
+	if err != nil {
+		log.Error().Err(err).Msgf("unsupported kafka version %s", cfg.Version)
+		return nil, err
+	}
+	sc.Consumer.Offsets.Initial = sarama.OffsetOldest
+	sc.Consumer.Return.Errors = true
+	setKafkaAccessSettings(sc)
+
+	client, err := sarama.NewClient(cfg.Brokers, sc)
+	if err != nil {
+		log.Error().Err(err).Msgf("cannot create client for brokers <%v>", cfg.Brokers)
+		return nil, err
+	}
+	return client, nil
+}
+
 func setKafkaAccessSettings(cfg *sarama.Config) {
 	usr := os.Getenv("KAFKA_USERNAME")
 	pwd := os.Getenv("KAFKA_PASSWORD")
@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	marked []int64
+}
+
+func (s *fakeSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+	s.marked = append(s.marked, offset)
+}
+
+func (s *fakeSession) Context() context.Context {
+	return context.Background()
+}
+
+func TestOffsetTracker(t *testing.T) {
+	session := &fakeSession{}
+	tracker := newOffsetTracker(session, "docs", 0)
+	for offset := int64(10); offset < 15; offset++ {
+		tracker.add(offset)
+	}
+
+	// 前面的消息未处理完毕时不提交位移
+	tracker.done(12)
+	tracker.done(11)
+	assert.Empty(t, session.marked)
+
+	tracker.done(10)
+	assert.Equal(t, []int64{13}, session.marked)
+
+	tracker.done(14)
+	assert.Equal(t, []int64{13}, session.marked)
+	tracker.done(13)
+	assert.Equal(t, []int64{13, 15}, session.marked)
+	assert.Empty(t, tracker.pending)
+	assert.Empty(t, tracker.finished)
+}
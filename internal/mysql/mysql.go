@@ -26,8 +26,9 @@ func NewClient(cfg *conf.MySQLConfig) *Client {
 // Doc 数据库schema定义
 type Doc struct {
 	ID    int64
-	DocID string `gorm:"size:8;column:doc_id;not null"`
+	DocID string `gorm:"size:20;column:doc_id;not null"`
 	Title string `gorm:"size:255;column:title;not null"`
+	Date  string `gorm:"size:32;column:date;not null"`
 }
 
 // Setup 初始化MySQL连接服务.
@@ -53,11 +54,42 @@ func (cli *Client) Close() error {
 	return cli.db.Close()
 }
 
-// Query 根据文档ID查找数据库中对应的文档名.
-func (cli *Client) Query(docID string) string {
+// SaveDoc 保存站点适配器从网页中抽取出的文档标题以及发布日期, 为空的字段保留数据库中原有的值.
+func (cli *Client) SaveDoc(docID, title, date string) error {
+	fields := make(map[string]interface{}, 2)
+	if title != "" {
+		fields["title"] = title
+	}
+	if date != "" {
+		fields["date"] = date
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
 	var doc Doc
+	if err := cli.db.Where(Doc{DocID: docID}).Assign(fields).FirstOrCreate(&doc).Error; err != nil {
+		log.Error().Err(err).Str("doc_id", docID).Msg("cannot save doc")
+		return err
+	}
+	return nil
+}
+
+// QueryDocs 根据文档ID列表批量查找数据库中对应的文档, 返回文档ID到文档的映射.
+func (cli *Client) QueryDocs(docIDs []string) (map[string]*Doc, error) {
+	docs := make(map[string]*Doc, len(docIDs))
+	if len(docIDs) == 0 {
+		return docs, nil
+	}
 
-	cli.db.Where("doc_id = ?", docID).First(&doc)
+	var rows []*Doc
+	if err := cli.db.Where("doc_id IN (?)", docIDs).Find(&rows).Error; err != nil {
+		log.Error().Err(err).Msg("cannot query docs")
+		return nil, err
+	}
+	for _, doc := range rows {
+		docs[doc.DocID] = doc
+	}
 
-	return doc.Title
+	return docs, nil
 }
@@ -5,7 +5,9 @@ USE mof_rpc;
 
 CREATE TABLE docs (
    id          INT         NOT NULL AUTO_INCREMENT,
-   doc_id      CHAR(8)     NOT NULL,
+   doc_id      CHAR(20)    NOT NULL,
    title       CHAR(255)   NOT NULL,
-   PRIMARY KEY (id)
+   date        CHAR(32)    NOT NULL DEFAULT '',
+   PRIMARY KEY (id),
+   UNIQUE KEY (doc_id)
 )
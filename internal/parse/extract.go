@@ -0,0 +1,197 @@
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+// DefaultJSONDoc 未配置时使用的JSON格式文档配置
+var DefaultJSONDoc = &conf.JSONDocConfig{
+	TextFields: []string{"content"},
+	TitleField: "title",
+	DateField:  "date",
+}
+
+// Extractor 从某种格式的文档中抽取内容, 需保证并发安全.
+type Extractor interface {
+	// Extract 从文档中抽取内容, 未抽取到正文时返回ErrEmptyDoc.
+	Extract(r io.Reader) (*Page, error)
+}
+
+var (
+	mdFence      = regexp.MustCompile("^(```|~~~)")
+	mdHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdRule       = regexp.MustCompile(`^([-*_]\s*){3,}$`)
+	mdTableRule  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	mdListMarker = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+	mdImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdHTMLTag    = regexp.MustCompile(`<[^>]+>`)
+	mdEmphasis   = regexp.MustCompile("(\\*\\*|__|\\*|_|~~|`)")
+)
+
+// MarkdownExtractor Markdown格式文档的内容抽取器.
+// 去除标记语法之后每个非空行作为正文的一段, 第一个一级标题作为标题; 代码块、front matter以及分割线被丢弃.
+type MarkdownExtractor struct{}
+
+// NewMarkdownExtractor 新建Markdown格式文档的内容抽取器.
+func NewMarkdownExtractor() *MarkdownExtractor {
+	return &MarkdownExtractor{}
+}
+
+// Extract 从Markdown文档中抽取标题与正文.
+func (e *MarkdownExtractor) Extract(r io.Reader) (*Page, error) {
+	page := &Page{Body: make([]string, 0)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var fenced, frontMatter bool
+	for lineno := 0; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineno == 0 && line == "---" {
+			frontMatter = true
+			continue
+		}
+		if frontMatter {
+			if line == "---" {
+				frontMatter = false
+			}
+			continue
+		}
+		if mdFence.MatchString(line) {
+			fenced = !fenced
+			continue
+		}
+		if fenced || mdRule.MatchString(line) || mdTableRule.MatchString(line) {
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			line = m[2]
+			if page.Title == "" && len(m[1]) == 1 {
+				page.Title = stripMarkdownInline(line)
+			}
+		}
+		line = strings.TrimLeft(line, "> ")
+		line = mdListMarker.ReplaceAllString(line, "")
+		if strings.HasPrefix(line, "|") {
+			line = strings.Join(strings.Fields(strings.Replace(line, "|", " ", -1)), " ")
+		}
+		if line = stripMarkdownInline(line); line != "" {
+			page.Body = append(page.Body, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(page.Body) == 0 {
+		return nil, ErrEmptyDoc
+	}
+	return page, nil
+}
+
+// stripMarkdownInline 去除行内的图片、链接、HTML标签以及强调标记, 保留其中的文字.
+func stripMarkdownInline(line string) string {
+	line = mdImage.ReplaceAllString(line, "$1")
+	line = mdLink.ReplaceAllString(line, "$1")
+	line = mdHTMLTag.ReplaceAllString(line, "")
+	line = mdEmphasis.ReplaceAllString(line, "")
+	return strings.TrimSpace(line)
+}
+
+// JSONExtractor JSON格式记录的内容抽取器, 按配置的字段抽取正文、标题以及发布日期.
+type JSONExtractor struct {
+	cfg *conf.JSONDocConfig
+}
+
+// NewJSONExtractor 新建JSON格式记录的内容抽取器, cfg为nil时使用DefaultJSONDoc.
+func NewJSONExtractor(cfg *conf.JSONDocConfig) *JSONExtractor {
+	if cfg == nil {
+		cfg = DefaultJSONDoc
+	}
+	return &JSONExtractor{cfg: cfg}
+}
+
+// Extract 从JSON记录中抽取内容, 正文字段中的每个字符串按换行拆分为正文的各段.
+func (e *JSONExtractor) Extract(r io.Reader) (*Page, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var record map[string]interface{}
+	if err = jsoniter.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("bad json record: %w", err)
+	}
+
+	page := &Page{Body: make([]string, 0)}
+	for _, field := range e.cfg.TextFields {
+		for _, text := range jsonStrings(jsonField(record, field)) {
+			for _, line := range strings.Split(text, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					page.Body = append(page.Body, line)
+				}
+			}
+		}
+	}
+	if len(page.Body) == 0 {
+		return nil, ErrEmptyDoc
+	}
+	if e.cfg.TitleField != "" {
+		if texts := jsonStrings(jsonField(record, e.cfg.TitleField)); len(texts) > 0 {
+			page.Title = strings.TrimSpace(texts[0])
+		}
+	}
+	if e.cfg.DateField != "" {
+		if texts := jsonStrings(jsonField(record, e.cfg.DateField)); len(texts) > 0 {
+			page.Date = strings.TrimSpace(texts[0])
+		}
+	}
+	return page, nil
+}
+
+// jsonField 按以.分隔的路径查找嵌套字段, 不存在时返回nil.
+func jsonField(record map[string]interface{}, path string) interface{} {
+	var value interface{} = record
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = object[key]; !ok {
+			return nil
+		}
+	}
+	return value
+}
+
+// jsonStrings 返回字段值中的字符串, 字段值为字符串数组时按顺序返回, 忽略其他类型.
+func jsonStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		{
+			return []string{v}
+		}
+	case []interface{}:
+		{
+			texts := make([]string, 0, len(v))
+			for _, item := range v {
+				if text, ok := item.(string); ok {
+					texts = append(texts, text)
+				}
+			}
+			return texts
+		}
+	default:
+		{
+			return nil
+		}
+	}
+}
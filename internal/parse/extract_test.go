@@ -0,0 +1,116 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
+)
+
+func TestMarkdownExtractor(t *testing.T) {
+	fr, err := os.Open("fixtures/docs/notice.md")
+	assert.Empty(t, err)
+	defer fr.Close()
+
+	page, err := NewMarkdownExtractor().Extract(fr)
+	assert.Empty(t, err)
+	assert.Equal(t, &Page{
+		Title: "关于开展三大粮食作物完全成本保险和收入保险试点工作的通知",
+		Body: []string{
+			"关于开展三大粮食作物完全成本保险和收入保险试点工作的通知",
+			"财金〔2018〕93号",
+			"按照2018年中央一号文件部署，为进一步提升农业保险保障水平，现就开展试点工作通知如下：",
+			"一、试点范围",
+			"从2018年开始，用3年时间，在6个省份开展试点。",
+			"每个省份选择4个产粮大县。",
+			"详见试点工作方案。",
+			"补贴情况表",
+			"省份 产粮大县",
+			"内蒙古 4",
+			"财政部  2018年8月20日",
+		},
+	}, page)
+
+	_, err = NewMarkdownExtractor().Extract(strings.NewReader("```\ncode\n```\n"))
+	assert.True(t, errors.Is(err, ErrEmptyDoc))
+}
+
+func TestJSONExtractor(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/docs/notice.json")
+	assert.Empty(t, err)
+
+	page, err := NewJSONExtractor(nil).Extract(strings.NewReader(string(data)))
+	assert.Empty(t, err)
+	assert.Equal(t, &Page{
+		Title: "关于做好2021年降成本重点工作的通知",
+		Date:  "2021-05-21",
+		Body:  []string{"发改运行〔2021〕602号", "各省、自治区、直辖市发展改革委："},
+	}, page)
+
+	// 嵌套字段与字符串数组, 非字符串字段被忽略
+	page, err = NewJSONExtractor(&conf.JSONDocConfig{
+		TextFields: []string{"meta.summary", "meta.views", "meta.source", "not.exist"},
+	}).Extract(strings.NewReader(string(data)))
+	assert.Empty(t, err)
+	assert.Equal(t, &Page{Body: []string{"巩固拓展降成本成果", "体改司"}}, page)
+
+	_, err = NewJSONExtractor(&conf.JSONDocConfig{TextFields: []string{"meta.views"}}).Extract(strings.NewReader(string(data)))
+	assert.True(t, errors.Is(err, ErrEmptyDoc))
+	_, err = NewJSONExtractor(nil).Extract(strings.NewReader("[1, 2]"))
+	assert.NotEmpty(t, err)
+}
+
+func TestParseDocTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parse")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	fixtures := map[pb.DocType]string{
+		pb.DocType_TextDoc:     "notice.txt",
+		pb.DocType_MarkdownDoc: "notice.md",
+		pb.DocType_JSONDoc:     "notice.json",
+	}
+	for docType, name := range fixtures {
+		assert.Empty(t, os.MkdirAll(filepath.Join(dir, common.FileType2FileTypeName[docType]), 0755))
+		data, err := ioutil.ReadFile(filepath.Join("fixtures/docs", name))
+		assert.Empty(t, err)
+		assert.Empty(t, ioutil.WriteFile(filepath.Join(dir, common.FileType2FileTypeName[docType],
+			docType.String()+"."+common.FileType2FileSuffix[docType]), data, 0644))
+	}
+
+	store := storage.NewLocalStorage(dir)
+	sites, err := NewSiteAdapters(nil)
+	assert.Empty(t, err)
+	p := NewPipeParseProcessor(store, nil, sites, nil)
+	ctx := context.Background()
+
+	// 文本文档直接交给下游
+	packet := &pb.Packet{DocType: pb.DocType_TextDoc, DocId: pb.DocType_TextDoc.String(), DocTitle: "title"}
+	doc, err := p.Process(ctx, &common.Document{Packet: packet})
+	assert.Empty(t, err)
+	assert.True(t, doc.Packet == packet)
+
+	for _, docType := range []pb.DocType{pb.DocType_MarkdownDoc, pb.DocType_JSONDoc} {
+		doc, err := p.Process(ctx, &common.Document{Packet: &pb.Packet{DocType: docType, DocId: docType.String()}})
+		assert.Empty(t, err)
+		assert.Equal(t, pb.DocType_TextDoc, doc.Packet.DocType)
+		assert.NotEmpty(t, doc.Packet.DocTitle)
+
+		file := &common.File{Type: pb.DocType_TextDoc, Name: docType.String()}
+		_, err = store.Get(ctx, file)
+		assert.Empty(t, err)
+		assert.NotEmpty(t, file.Body)
+	}
+
+	_, err = p.Process(ctx, &common.Document{Packet: &pb.Packet{DocType: pb.DocType(99), DocId: "99"}})
+	assert.True(t, errors.Is(err, ErrUnsupportedDocType))
+}
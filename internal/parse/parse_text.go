@@ -2,123 +2,146 @@ package parse
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
-	"strings"
-	"sync"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/rs/zerolog/log"
 
 	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
 )
 
+var (
+	// ErrUnsupportedWebStation 不支持的网站错误
+	ErrUnsupportedWebStation = errors.New("unsupported web station")
+	// ErrUnsupportedDocType 不支持的文档类型错误
+	ErrUnsupportedDocType = errors.New("unsupported doc type")
+	// ErrEmptyDoc 未解析出正文错误
+	ErrEmptyDoc = errors.New("empty doc")
+)
+
+// DocSaver 保存从文档中抽取出的标题以及发布日期, 需保证并发安全.
+type DocSaver interface {
+	SaveDoc(docID, title, date string) error
+}
+
 // PipeParseProcessor 文本解析器
 type PipeParseProcessor struct {
-	tokenBucket chan struct{}
-	storage     storage.Persister
+	storage  storage.Persister
+	docs     DocSaver
+	sites    *SiteAdapters
+	markdown Extractor
+	json     Extractor
 }
 
-// NewPipeParseProcessor 新建文本解析器.
-func NewPipeParseProcessor(storage storage.Persister) *PipeParseProcessor {
+// NewPipeParseProcessor 新建文本解析器, 网页由数据包对应的站点适配器解析, JSON格式记录按jsonDoc配置的字段解析.
+// 抽取出的标题以及发布日期保存到docs中, docs为nil时不保存.
+func NewPipeParseProcessor(storage storage.Persister, docs DocSaver, sites *SiteAdapters, jsonDoc *conf.JSONDocConfig) *PipeParseProcessor {
 	log.Info().Msg("load PipeParseProcessor plugin")
 	return &PipeParseProcessor{
-		tokenBucket: make(chan struct{}, 20),
-		storage:     storage,
+		storage:  storage,
+		docs:     docs,
+		sites:    sites,
+		markdown: NewMarkdownExtractor(),
+		json:     NewJSONExtractor(jsonDoc),
 	}
 }
 
-// InfoExtract 解析中/英文文本文件.
-func (p *PipeParseProcessor) InfoExtract(pGroup *sync.WaitGroup, input common.PacketChannel, output common.PacketChannel) {
-	pGroup.Add(1)
-LOOP_LABEL:
-	for {
-		select {
-		case packet, ok := <-input:
-			{
-				if !ok {
-					close(output)
-					break LOOP_LABEL
-				}
-				if packet.DeliveryStatus == pb.PacketDeliveryStatus_InDelivery {
-					switch packet.WebStation {
-					case pb.WebStation_MOFRPC:
-						{
-							go p.parseMOFRPCHTML(packet, output)
-						}
-					default:
-						{
-
-						}
-					}
-				}
-			}
-		default:
-			{
-
+// Process 按文档类型解析文档, 将正文写入存储并以文本文档的形式交给下游 (并发安全).
+// 网页由数据包对应的站点适配器解析, 文本文档直接交给下游.
+func (p *PipeParseProcessor) Process(ctx context.Context, doc *common.Document) (*common.Document, error) {
+	packet := doc.Packet
+	if packet.Operation == pb.DocOperation_DeleteDoc {
+		// 删除操作无需解析文档, 直接交给下游
+		return doc, nil
+	}
+	switch packet.DocType {
+	case pb.DocType_HTMLDoc:
+		{
+			adapter, ok := p.sites.Lookup(packet)
+			if !ok {
+				return nil, fmt.Errorf("%w: site=%s, web_station=%s, doc_url=%s",
+					ErrUnsupportedWebStation, packet.GetSite(), packet.GetWebStation(), packet.GetDocUrl())
 			}
+			return p.extract(ctx, doc, adapter)
+		}
+	case pb.DocType_TextDoc:
+		{
+			return doc, nil
+		}
+	case pb.DocType_MarkdownDoc:
+		{
+			return p.extract(ctx, doc, p.markdown)
+		}
+	case pb.DocType_JSONDoc:
+		{
+			return p.extract(ctx, doc, p.json)
+		}
+	default:
+		{
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedDocType, packet.DocType)
 		}
 	}
-	pGroup.Done()
-	log.Info().Msg("unload PipeParseProcessor plugin")
 }
 
-// 用于解析中华人民共和国财政部发布的文章网页.
-func (p *PipeParseProcessor) parseMOFRPCHTML(packet *pb.Packet, output common.PacketChannel) {
-	p.tokenBucket <- struct{}{}
-
+// extract 用抽取器解析文档.
+func (p *PipeParseProcessor) extract(ctx context.Context, doc *common.Document, extractor Extractor) (*common.Document, error) {
+	packet := doc.Packet
 	// TODO: minio是否有并发写检测机制（两个及以上的线程同时写一个同名对象）
-	path, err := p.storage.Readable(context.Background(), &common.File{
+	path, err := p.storage.Readable(ctx, &common.File{
 		Type: packet.DocType,
 		Name: packet.DocId,
 	})
 	if err != nil {
-		log.Error().Err(err)
-		return
+		return nil, err
 	}
 
 	fr, err := os.Open(path)
 	if err != nil {
-		log.Error().Err(err)
-		return
+		return nil, err
 	}
 	defer fr.Close()
 
-	doc, err := goquery.NewDocumentFromReader(fr)
+	page, err := extractor.Extract(fr)
 	if err != nil {
-		log.Error().Err(err)
-		return
+		return nil, err
 	}
 
-	body := make([]string, 0)
-	doc.Find("div.my_conboxzw div.TRS_Editor div.TRS_Editor p").Each(func(i int, s *goquery.Selection) {
-		body = append(body, strings.TrimSpace(s.Text()))
-	})
-
-	if len(body) > 0 {
-		if _, err = p.storage.Writable(context.Background(), &common.File{
-			Type: pb.DocType_TextDoc,
-			Name: packet.DocId,
-			Body: body,
-		}); err != nil {
-			log.Error().Err(err)
-			return
-		}
-		if _, err = p.storage.Put(context.Background(), &common.File{
-			Type: pb.DocType_TextDoc,
-			Name: packet.DocId,
-		}); err != nil {
-			log.Error().Err(err)
-			return
-		}
+	text := &common.File{
+		Type: pb.DocType_TextDoc,
+		Name: packet.DocId,
+		Body: page.Body,
+	}
+	if _, err = p.storage.Writable(ctx, text); err != nil {
+		return nil, err
+	}
+	if _, err = p.storage.Put(ctx, text); err != nil {
+		return nil, err
+	}
 
-		output <- &pb.Packet{
-			DocType: pb.DocType_TextDoc,
-			DocId:   packet.DocId,
+	title := packet.DocTitle
+	if page.Title != "" {
+		title = page.Title
+	}
+	// 查询结果中的标题与发布日期从数据库中读取
+	if p.docs != nil && (page.Title != "" || page.Date != "") {
+		if err = p.docs.SaveDoc(packet.DocId, page.Title, page.Date); err != nil {
+			return nil, err
 		}
 	}
+	doc.Packet = &pb.Packet{
+		WebStation: packet.WebStation,
+		DocType:    pb.DocType_TextDoc,
+		DocId:      packet.DocId,
+		DocTitle:   title,
+		Operation:  packet.Operation,
+		Site:       packet.Site,
+		DocUrl:     packet.DocUrl,
+		DocDate:    page.Date,
+	}
 	log.Debug().Msg("PipeParseProcessor processes one data packet")
-
-	<-p.tokenBucket
+	return doc, nil
 }
@@ -0,0 +1,161 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+var (
+	// ErrBadSiteConfig 站点适配器配置错误
+	ErrBadSiteConfig = errors.New("bad site config")
+)
+
+// BuiltinSites 内置的站点适配器配置
+var BuiltinSites = []*conf.SiteConfig{
+	{
+		// 中华人民共和国财政部网站
+		Station:       pb.WebStation_MOFRPC.String(),
+		URLPattern:    `^https?://[a-z]+\.mof\.gov\.cn/`,
+		BodySelector:  "div.my_conboxzw div.TRS_Editor div.TRS_Editor p",
+		TitleSelector: "div.my_conboxtitle h2",
+		DateSelector:  "div.my_conboxtitle span.my_conboxdate",
+	},
+}
+
+// Page 从网页中抽取出的内容
+type Page struct {
+	Title string
+	Date  string
+	Body  []string
+}
+
+// SiteAdapter 站点适配器, 负责从某个站点的网页中抽取内容, 需保证并发安全.
+type SiteAdapter interface {
+	// Station 返回适配器对应的站点名.
+	Station() string
+	// Match 判断文档地址是否属于该站点.
+	Match(url string) bool
+	// Extractor 从网页中抽取内容
+	Extractor
+}
+
+// SelectorAdapter 按CSS选择器抽取网页内容的声明式站点适配器
+type SelectorAdapter struct {
+	cfg        *conf.SiteConfig
+	urlPattern *regexp.Regexp
+}
+
+// NewSelectorAdapter 新建声明式站点适配器.
+func NewSelectorAdapter(cfg *conf.SiteConfig) (*SelectorAdapter, error) {
+	if cfg.Station == "" || cfg.BodySelector == "" {
+		return nil, fmt.Errorf("%w: station and body_selector are required", ErrBadSiteConfig)
+	}
+	a := &SelectorAdapter{cfg: cfg}
+	if cfg.URLPattern != "" {
+		pattern, err := regexp.Compile(cfg.URLPattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: station=%s, %v", ErrBadSiteConfig, cfg.Station, err)
+		}
+		a.urlPattern = pattern
+	}
+	return a, nil
+}
+
+// Station 返回适配器对应的站点名.
+func (a *SelectorAdapter) Station() string {
+	return a.cfg.Station
+}
+
+// Match 判断文档地址是否匹配URLPattern, 未配置URLPattern时总是不匹配.
+func (a *SelectorAdapter) Match(url string) bool {
+	return a.urlPattern != nil && a.urlPattern.MatchString(url)
+}
+
+// Extract 按配置的选择器抽取正文、标题以及发布日期, 未抽取到正文时返回ErrEmptyDoc.
+func (a *SelectorAdapter) Extract(r io.Reader) (*Page, error) {
+	html, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Body: make([]string, 0)}
+	html.Find(a.cfg.BodySelector).Each(func(i int, s *goquery.Selection) {
+		if text := strings.TrimSpace(s.Text()); text != "" {
+			page.Body = append(page.Body, text)
+		}
+	})
+	if len(page.Body) == 0 {
+		return nil, ErrEmptyDoc
+	}
+	if a.cfg.TitleSelector != "" {
+		page.Title = strings.TrimSpace(html.Find(a.cfg.TitleSelector).First().Text())
+	}
+	if a.cfg.DateSelector != "" {
+		page.Date = strings.TrimSpace(html.Find(a.cfg.DateSelector).First().Text())
+	}
+	return page, nil
+}
+
+// SiteAdapters 按站点名索引的站点适配器集合
+type SiteAdapters struct {
+	stations map[string]SiteAdapter
+	// 按注册顺序排列, 用于按文档地址查找
+	ordered []SiteAdapter
+}
+
+// NewSiteAdapters 以内置站点为基础, 按配置新建站点适配器集合, 配置中与内置站点同名的项覆盖内置配置.
+func NewSiteAdapters(sites []*conf.SiteConfig) (*SiteAdapters, error) {
+	s := &SiteAdapters{stations: make(map[string]SiteAdapter)}
+	for _, cfg := range append(append([]*conf.SiteConfig{}, BuiltinSites...), sites...) {
+		adapter, err := NewSelectorAdapter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.Register(adapter)
+	}
+	return s, nil
+}
+
+// Register 注册站点适配器, 已存在同名站点时替换之.
+func (s *SiteAdapters) Register(adapter SiteAdapter) {
+	if _, ok := s.stations[adapter.Station()]; ok {
+		for i, a := range s.ordered {
+			if a.Station() == adapter.Station() {
+				s.ordered[i] = adapter
+			}
+		}
+	} else {
+		s.ordered = append(s.ordered, adapter)
+	}
+	s.stations[adapter.Station()] = adapter
+}
+
+// Lookup 查找数据包对应的站点适配器.
+// 依次按site、文档地址以及web_station的枚举名查找, site不为空时不再按枚举名查找.
+func (s *SiteAdapters) Lookup(packet *pb.Packet) (SiteAdapter, bool) {
+	if site := packet.GetSite(); site != "" {
+		if adapter, ok := s.stations[site]; ok {
+			return adapter, true
+		}
+	}
+	if url := packet.GetDocUrl(); url != "" {
+		for _, adapter := range s.ordered {
+			if adapter.Match(url) {
+				return adapter, true
+			}
+		}
+	}
+	if packet.GetSite() == "" {
+		adapter, ok := s.stations[packet.GetWebStation().String()]
+		return adapter, ok
+	}
+	return nil, false
+}
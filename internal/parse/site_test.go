@@ -0,0 +1,134 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
+)
+
+func loadSiteFixture(t *testing.T) *SiteAdapters {
+	data, err := ioutil.ReadFile("fixtures/sites/sites.json")
+	assert.Empty(t, err)
+	var sites []*conf.SiteConfig
+	assert.Empty(t, jsoniter.Unmarshal(data, &sites))
+	adapters, err := NewSiteAdapters(sites)
+	assert.Empty(t, err)
+	return adapters
+}
+
+func extractFixture(t *testing.T, adapter SiteAdapter, name string) *Page {
+	fr, err := os.Open(filepath.Join("fixtures/sites", name))
+	assert.Empty(t, err)
+	defer fr.Close()
+	page, err := adapter.Extract(fr)
+	assert.Empty(t, err)
+	return page
+}
+
+func TestSiteAdapters(t *testing.T) {
+	sites := loadSiteFixture(t)
+
+	// 内置站点按web_station的枚举名查找
+	adapter, ok := sites.Lookup(&pb.Packet{WebStation: pb.WebStation_MOFRPC})
+	assert.True(t, ok)
+	assert.Equal(t, "MOFRPC", adapter.Station())
+	page := extractFixture(t, adapter, "mofrpc.html")
+	assert.Equal(t, []string{
+		"财金〔2018〕93号",
+		"内蒙古、辽宁、安徽、山东、河南、湖北省（自治区）财政厅（局）、农业（农牧、农村经济）厅（局、委、办）、银保监局：",
+		"按照2018年中央一号文件部署，为进一步提升农业保险保障水平，推动农业保险转型升级，现就开展三大粮食作物完全成本保险和收入保险试点工作通知如下：",
+		"财政部 农业农村部 银保监会",
+		"2018年8月20日",
+	}, page.Body)
+	assert.Equal(t, "关于开展三大粮食作物完全成本保险和收入保险试点工作的通知", page.Title)
+	assert.Equal(t, "2018-08-28", page.Date)
+
+	// 配置的站点按site或文档地址查找
+	adapter, ok = sites.Lookup(&pb.Packet{Site: "NDRC"})
+	assert.True(t, ok)
+	assert.Equal(t, "NDRC", adapter.Station())
+	adapter, ok = sites.Lookup(&pb.Packet{DocUrl: "https://www.ndrc.gov.cn/xxgk/zcfb/tz/202105/t20210521_1280523.html"})
+	assert.True(t, ok)
+	assert.Equal(t, "NDRC", adapter.Station())
+	page = extractFixture(t, adapter, "ndrc.html")
+	assert.Equal(t, &Page{
+		Title: "国家发展改革委关于做好2021年降成本重点工作的通知",
+		Date:  "2021-05-21",
+		Body: []string{
+			"发改运行〔2021〕602号",
+			"各省、自治区、直辖市及计划单列市、新疆生产建设兵团发展改革委：",
+			"为贯彻落实党中央、国务院决策部署，巩固拓展降成本成果，现就做好2021年降成本重点工作通知如下。",
+		},
+	}, page)
+
+	// 未知站点
+	_, ok = sites.Lookup(&pb.Packet{Site: "unknown", DocUrl: "https://example.com/a.html"})
+	assert.False(t, ok)
+
+	// 正文选择器未匹配任何内容
+	fr, err := os.Open("fixtures/sites/ndrc.html")
+	assert.Empty(t, err)
+	defer fr.Close()
+	mofrpc, _ := sites.Lookup(&pb.Packet{})
+	_, err = mofrpc.Extract(fr)
+	assert.True(t, errors.Is(err, ErrEmptyDoc))
+
+	_, err = NewSiteAdapters([]*conf.SiteConfig{{Station: "NDRC"}})
+	assert.True(t, errors.Is(err, ErrBadSiteConfig))
+	_, err = NewSiteAdapters([]*conf.SiteConfig{{Station: "NDRC", BodySelector: "p", URLPattern: "("}})
+	assert.True(t, errors.Is(err, ErrBadSiteConfig))
+}
+
+type fakeDocSaver struct {
+	docs map[string][2]string
+}
+
+func (s *fakeDocSaver) SaveDoc(docID, title, date string) error {
+	s.docs[docID] = [2]string{title, date}
+	return nil
+}
+
+func TestParseHTML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parse")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	for _, sub := range []string{"html", "text"} {
+		assert.Empty(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	data, err := ioutil.ReadFile("fixtures/sites/ndrc.html")
+	assert.Empty(t, err)
+	assert.Empty(t, ioutil.WriteFile(filepath.Join(dir, "html", "1.html"), data, 0644))
+
+	store := storage.NewLocalStorage(dir)
+	docs := &fakeDocSaver{docs: make(map[string][2]string)}
+	p := NewPipeParseProcessor(store, docs, loadSiteFixture(t), nil)
+	doc, err := p.Process(context.Background(), &common.Document{Packet: &pb.Packet{
+		DocType: pb.DocType_HTMLDoc,
+		DocId:   "1",
+		Site:    "NDRC",
+	}})
+	assert.Empty(t, err)
+	assert.Equal(t, pb.DocType_TextDoc, doc.Packet.DocType)
+	assert.Equal(t, "国家发展改革委关于做好2021年降成本重点工作的通知", doc.Packet.DocTitle)
+	assert.Equal(t, "2021-05-21", doc.Packet.DocDate)
+	assert.Equal(t, [2]string{"国家发展改革委关于做好2021年降成本重点工作的通知", "2021-05-21"}, docs.docs["1"])
+
+	file := &common.File{Type: pb.DocType_TextDoc, Name: "1"}
+	_, err = store.Get(context.Background(), file)
+	assert.Empty(t, err)
+	assert.Equal(t, 3, len(file.Body))
+
+	_, err = p.Process(context.Background(), &common.Document{Packet: &pb.Packet{DocId: "2", Site: "unknown"}})
+	assert.True(t, errors.Is(err, ErrUnsupportedWebStation))
+}
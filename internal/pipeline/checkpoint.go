@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
+)
+
+// 检查写入文档数的间隔
+var _CheckpointPollInterval = time.Second
+
+// ErrNoCommitPolicy 既没有开启预写日志也没有配置检查点错误
+var ErrNoCommitPolicy = errors.New("no commit policy: enable indexer.enable_wal or set indexer.checkpoint_interval_sec / indexer.checkpoint_every_docs")
+
+// ValidateCommitPolicy 校验kafka位移的提交策略.
+// 未开启预写日志时, 文档只有在dump之后才确认消费, 如果后台检查点也不生效, 位移永远不会提交,
+// 待确认的消息会无限堆积.
+func ValidateCommitPolicy(cfg *conf.IndexerConfig) error {
+	if cfg.EnableWAL || cfg.CheckpointIntervalSec > 0 || cfg.CheckpointEveryDocs > 0 {
+		return nil
+	}
+	return ErrNoCommitPolicy
+}
+
+// dumper 可以dump的索引
+type dumper interface {
+	Dump() (uint64, error)
+	DocsSinceDump() uint64
+}
+
+// checkpointResult 检查点结果
+type checkpointResult struct {
+	generation uint64
+	err        error
+}
+
+// Checkpointer 后台检查点, 按时间间隔或写入文档数dump索引, 也可以按需触发.
+// 所有dump都在同一个goroutine中串行执行.
+type Checkpointer struct {
+	indexer   dumper
+	interval  time.Duration
+	everyDocs uint64
+	trigger   chan chan *checkpointResult
+	exit      chan struct{}
+	done      chan struct{}
+}
+
+// NewCheckpointer 新建后台检查点.
+func NewCheckpointer(indexer dumper, cfg *conf.IndexerConfig) *Checkpointer {
+	return &Checkpointer{
+		indexer:   indexer,
+		interval:  time.Duration(cfg.CheckpointIntervalSec) * time.Second,
+		everyDocs: cfg.CheckpointEveryDocs,
+		trigger:   make(chan chan *checkpointResult),
+		exit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Run 运行后台检查点, 直到Stop被调用.
+func (c *Checkpointer) Run() {
+	defer close(c.done)
+
+	var intervalCh, pollCh <-chan time.Time
+	if c.interval > 0 {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		intervalCh = ticker.C
+	}
+	if c.everyDocs > 0 {
+		ticker := time.NewTicker(_CheckpointPollInterval)
+		defer ticker.Stop()
+		pollCh = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.exit:
+			{
+				return
+			}
+		case reply := <-c.trigger:
+			{
+				generation, err := c.indexer.Dump()
+				reply <- &checkpointResult{generation: generation, err: err}
+			}
+		case <-intervalCh:
+			{
+				// 没有新的写入时跳过
+				if c.indexer.DocsSinceDump() > 0 {
+					c.checkpoint("interval")
+				}
+			}
+		case <-pollCh:
+			{
+				if c.indexer.DocsSinceDump() >= c.everyDocs {
+					c.checkpoint("docs")
+				}
+			}
+		}
+	}
+}
+
+func (c *Checkpointer) checkpoint(reason string) {
+	generation, err := c.indexer.Dump()
+	if err != nil {
+		log.Error().Err(err).Msgf("cannot checkpoint indexing, reason=%s", reason)
+		return
+	}
+	log.Info().Msgf("checkpoint indexing, reason=%s, generation=%d", reason, generation)
+}
+
+// Trigger 立即dump一次索引, 返回新的代数.
+func (c *Checkpointer) Trigger(ctx context.Context) (uint64, error) {
+	reply := make(chan *checkpointResult, 1)
+	select {
+	case c.trigger <- reply:
+	case <-c.done:
+		return 0, utils.ErrServiceUnavailable
+	case <-ctx.Done():
+		return 0, utils.ErrContextDone
+	}
+	select {
+	case result := <-reply:
+		return result.generation, result.err
+	case <-ctx.Done():
+		return 0, utils.ErrContextDone
+	}
+}
+
+// Stop 停止后台检查点, 等待进行中的dump完成.
+func (c *Checkpointer) Stop() {
+	close(c.exit)
+	<-c.done
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
+)
+
+type fakeDumper struct {
+	generation uint64
+	docs       uint64
+}
+
+func (d *fakeDumper) Dump() (uint64, error) {
+	atomic.StoreUint64(&d.docs, 0)
+	return atomic.AddUint64(&d.generation, 1), nil
+}
+
+func (d *fakeDumper) DocsSinceDump() uint64 {
+	return atomic.LoadUint64(&d.docs)
+}
+
+func TestCheckpointerTrigger(t *testing.T) {
+	d := &fakeDumper{}
+	c := NewCheckpointer(d, &conf.IndexerConfig{})
+	go c.Run()
+
+	generation, err := c.Trigger(context.Background())
+	assert.Empty(t, err)
+	assert.Equal(t, uint64(1), generation)
+	generation, err = c.Trigger(context.Background())
+	assert.Empty(t, err)
+	assert.Equal(t, uint64(2), generation)
+
+	c.Stop()
+	_, err = c.Trigger(context.Background())
+	assert.Equal(t, utils.ErrServiceUnavailable, err)
+}
+
+func TestCheckpointerEveryDocs(t *testing.T) {
+	interval := _CheckpointPollInterval
+	_CheckpointPollInterval = 10 * time.Millisecond
+	defer func() { _CheckpointPollInterval = interval }()
+
+	d := &fakeDumper{}
+	c := NewCheckpointer(d, &conf.IndexerConfig{CheckpointEveryDocs: 3})
+	go c.Run()
+	defer c.Stop()
+
+	atomic.StoreUint64(&d.docs, 2)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, uint64(0), atomic.LoadUint64(&d.generation))
+
+	atomic.StoreUint64(&d.docs, 3)
+	assert.Eventually(t, func() bool { return atomic.LoadUint64(&d.generation) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(0), d.DocsSinceDump())
+}
+
+func TestValidateCommitPolicy(t *testing.T) {
+	// 没有预写日志也没有检查点时, 文档永远不会被确认, 拒绝启动
+	err := ValidateCommitPolicy(&conf.IndexerConfig{})
+	assert.True(t, errors.Is(err, ErrNoCommitPolicy))
+
+	for _, cfg := range []*conf.IndexerConfig{
+		{EnableWAL: true},
+		{CheckpointIntervalSec: 600},
+		{CheckpointEveryDocs: 1000},
+	} {
+		assert.Empty(t, ValidateCommitPolicy(cfg))
+	}
+}
@@ -0,0 +1,497 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/deadletter"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/kafka"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/mysql"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/query"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stemming"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stopword"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/tokenize"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
+)
+
+// 查询词典信息时未指定limit, 默认返回的待重新索引的文档ID个数
+const _DefaultStaleDocsLimit = 100
+
+// Container 语料数据容器, 管道的各个阶段及其顺序由配置声明.
+type Container struct {
+	once sync.Once
+	cfg  *conf.PipelineConfig
+
+	consumer   *kafka.CustomConsumerGroupHandler
+	storage    storage.Persister
+	db         *mysql.Client
+	deadLetter deadletter.Sink
+
+	language     common.LanguageType
+	dictionary   *tokenize.Dictionary
+	stopWords    *stopword.Lists
+	tokenizer    *tokenize.PipeTokenizeProcessor
+	stoper       *stopword.PipeStopWordsProcessor
+	stemmer      *stemming.PipeStemmingProcessor
+	indexer      *indexing.PipeIndexProcessor
+	pipeline     *Pipeline
+	checkpointer *Checkpointer
+
+	// 管道启动之后关闭
+	ready chan struct{}
+	// Run退出之后关闭
+	exit chan struct{}
+}
+
+// NewContainer 新建数据容器.
+func NewContainer(cfg *conf.PipelineConfig) *Container {
+	h := &Container{
+		cfg: cfg,
+	}
+
+	var err error
+
+	h.consumer, err = kafka.NewCustomConsumerGroupHandler(h.cfg.Kafka)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create kafka consumer")
+	}
+
+	h.storage, err = storage.NewS3Storage(h.cfg.Minio)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create storage")
+	}
+	if err = h.storage.Init(); err != nil {
+		log.Fatal().Err(err).Msg("cannot init storage")
+	}
+
+	h.db = mysql.NewClient(h.cfg.MySQL)
+	if err = h.db.Setup(); err != nil {
+		log.Fatal().Err(err).Msg("cannot setup mysql")
+	}
+
+	h.deadLetter, err = deadletter.NewSink(h.cfg.DeadLetter, h.cfg.Kafka)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create dead letter sink")
+	}
+
+	h.language, err = ParseLanguage(h.cfg.Language)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot parse pipeline language")
+	}
+	// 词典在分词器需要时才加载, 各分词器共用
+	h.dictionary = tokenize.NewDictionary(h.cfg.Tokenizer)
+	h.tokenizer, err = tokenize.NewPipeTokenizeProcessor(h.storage, h.language, h.cfg.Tokenizer, h.dictionary)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create tokenizer")
+	}
+	h.stopWords, err = stopword.NewLists(h.cfg.StopWords)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load stopword lists")
+	}
+	h.stoper = stopword.NewPipeStopWordsProcessor(h.language, h.stopWords)
+	normalizer, err := stemming.NewChain(h.cfg.Stemming)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create stemming normalizers")
+	}
+	h.stemmer = stemming.NewPipeStemmingProcessor(h.language, normalizer)
+	h.indexer = indexing.NewPipeIndexProcessor(h.cfg.Indexer, h.storage)
+	h.indexer.UseStopWords(h.stopWords)
+	h.pipeline, err = BuildPipeline(&StageEnv{
+		Config:        h.cfg,
+		Storage:       h.storage,
+		Docs:          h.db,
+		Indexer:       h.indexer,
+		Language:      h.language,
+		Dictionary:    h.dictionary,
+		StopWordLists: h.stopWords,
+		Normalizer:    normalizer,
+		Tokenizer:     h.tokenizer,
+		StopWords:     h.stoper,
+		Stemmer:       h.stemmer,
+	}, h.cfg.Stages)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot build pipeline")
+	}
+
+	if err = ValidateCommitPolicy(h.cfg.Indexer); err != nil {
+		log.Fatal().Err(err).Msg("cannot commit kafka offsets")
+	}
+	h.checkpointer = NewCheckpointer(h.indexer, h.cfg.Indexer)
+
+	h.ready = make(chan struct{})
+	h.exit = make(chan struct{})
+
+	if cfg.Indexer.Load {
+		// 加载完成之前拒绝查询以及按需触发的检查点
+		h.indexer.MarkServiceUnavailable()
+	}
+	go h.process(cfg.Indexer.Load)
+
+	return h
+}
+
+func (h *Container) process(load bool) {
+	if load {
+		if err := h.indexer.Load(); err != nil {
+			log.Fatal().Err(err).Msg("cannot load indexing")
+		}
+		h.buildSnapshot()
+		h.indexer.MarkServiceAvailable()
+	} else if err := h.indexer.OpenWAL(); err != nil {
+		log.Fatal().Err(err).Msg("cannot open wal")
+	}
+	// 加载期间重放预写日志以及替换倒排索引都不加锁, 检查点需在加载完成之后才能启动
+	go h.checkpointer.Run()
+	// 加载完成之前消费到的文档积压在第一个阶段的输入队列中
+	h.pipeline.Start(context.Background())
+	close(h.ready)
+}
+
+// Run 运行数据容器, 直到消费者被关闭.
+// 消息的位移只在文档被持久化 (写入预写日志或dump), 或处理失败并写入死信之后才会提交.
+func (h *Container) Run() {
+	defer close(h.exit)
+
+	for msg := range h.consumer.Msg() {
+		packet := new(pb.Packet)
+		if err := proto.Unmarshal(msg.Value, packet); err != nil {
+			log.Warn().Err(err).Msg("bad message")
+			h.newMessageAck(msg, packet).Fail(common.StageConsume, err)
+			continue
+		}
+		if packet.DeliveryStatus == pb.PacketDeliveryStatus_InDelivery {
+			log.Info().Msg("consume one packet")
+			// 管道处理不过来时阻塞在这里, 不再从消费者读取消息
+			h.pipeline.Input() <- &common.Document{Packet: packet, Ack: h.newMessageAck(msg, packet)}
+		} else {
+			if packet.DeliveryStatus == pb.PacketDeliveryStatus_OutOfStock {
+				// 构造并发布新快照, 期间查询继续访问旧快照
+				h.buildSnapshot()
+			}
+			msg.Done()
+		}
+	}
+}
+
+// buildSnapshot 构造并发布新的索引快照, 快照记录其中的文档去停词时使用的停词表, 访问该快照的查询语句按其移除停词.
+func (h *Container) buildSnapshot() {
+	h.indexer.BuildTFIDF()
+}
+
+// newMessageAck 新建消息的确认句柄.
+// 处理失败的消息写入死信之后提交位移; 未配置死信或写入死信失败时不提交位移, 重启之后重新消费.
+func (h *Container) newMessageAck(msg *kafka.Message, packet *pb.Packet) *common.Ack {
+	return common.NewAck(msg.Done, func(stage string, err error) {
+		if h.deadLetter == nil {
+			log.Error().Err(err).Msgf("cannot process doc, offset will not be committed, stage=%s, doc_id=%s, partition=%d, offset=%d",
+				stage, packet.DocId, msg.Partition, msg.Offset)
+			return
+		}
+		letter := &deadletter.Letter{
+			Stage:     stage,
+			DocID:     packet.DocId,
+			Error:     err.Error(),
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Packet:    msg.Value,
+			CreatedAt: time.Now().Unix(),
+		}
+		if err := h.deadLetter.Send(letter); err != nil {
+			log.Error().Err(err).Msgf("cannot send dead letter, offset will not be committed, stage=%s, doc_id=%s, partition=%d, offset=%d",
+				stage, packet.DocId, msg.Partition, msg.Offset)
+			return
+		}
+		msg.Done()
+	})
+}
+
+// Stop 停止运行数据容器 (并发安全).
+func (h *Container) Stop() {
+	log.Info().Msg("try to close pipeline container ...")
+	h.once.Do(func() {
+		// 先停止消费, 再按顺序排空管道的各个阶段, 最后dump索引
+		h.consumer.Close()
+		<-h.exit
+		<-h.ready
+		h.pipeline.Stop()
+		h.checkpointer.Stop()
+		if _, err := h.indexer.Dump(); err != nil {
+			log.Error().Err(err).Msg("cannot dump indexing")
+		}
+		if err := h.indexer.CloseWAL(); err != nil {
+			log.Error().Err(err).Msg("cannot close wal")
+		}
+		if h.deadLetter != nil {
+			h.deadLetter.Close() // nolint
+		}
+		h.storage.Destroy() // nolint
+		h.db.Close()        // nolint
+	})
+	log.Info().Msg("pipeline container has been closed")
+}
+
+// Query 利用关键词查询相似文档, 命中文档按得分降序排列.
+// 携带分页令牌的查询总是访问令牌对应的快照, 保证翻页期间结果稳定.
+func (h *Container) Query(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	if !h.indexer.ServiceAvailable() {
+		return nil, utils.ErrServiceUnavailable
+	}
+
+	ranking, ok := common.RankingFunction2Name[req.GetRanking()]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", indexing.ErrUnknownRanking, req.GetRanking())
+	}
+	scorer, err := h.indexer.Scorer(ranking)
+	if err != nil {
+		return nil, err
+	}
+	node, err := query.Parse(req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = req.GetTopk()
+	}
+	offset := req.GetOffset()
+	fingerprint := queryFingerprint(req.GetQuery(), req.GetRanking())
+
+	// 同一次查询只访问同一份快照
+	var snapshot *indexing.TFIDF
+	if req.GetPageToken() != "" {
+		token, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, err
+		}
+		if token.Fingerprint != fingerprint {
+			return nil, utils.ErrInvalidPageToken
+		}
+		if snapshot, err = h.indexer.SnapshotAt(token.Epoch, token.Generation); err != nil {
+			return nil, err
+		}
+		offset = token.Offset
+	} else {
+		snapshot = h.indexer.Snapshot()
+	}
+
+	q, err := query.Evaluate(snapshot, node, func(text string) (*common.ConcordanceWrapper, error) {
+		return h.analyzeQuery(ctx, snapshot, text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objects, total := snapshot.Window(offset, limit, scorer, q)
+	if utils.IsContextDone(ctx) {
+		return nil, utils.ErrContextDone
+	}
+
+	docIDList := make([]string, len(objects))
+	for idx, obj := range objects {
+		docIDList[idx] = obj.DocID
+	}
+	docs, err := h.db.QueryDocs(docIDList)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.QueryResponse{
+		Hits:      make([]*pb.SearchHit, len(objects)),
+		TotalHits: total,
+	}
+	for idx, obj := range objects {
+		resp.Hits[idx] = &pb.SearchHit{
+			DocId: obj.DocID,
+			Score: obj.Similarity,
+			Rank:  offset + uint32(idx) + 1,
+		}
+		if doc, ok := docs[obj.DocID]; ok {
+			resp.Hits[idx].Title = doc.Title
+			resp.Hits[idx].Date = doc.Date
+		}
+	}
+	if next := uint64(offset) + uint64(len(objects)); len(objects) > 0 && next < total {
+		resp.NextPageToken = encodePageToken(&pageToken{
+			Epoch:       snapshot.Epoch,
+			Generation:  snapshot.Generation,
+			Offset:      uint32(next),
+			Fingerprint: fingerprint,
+		})
+	}
+
+	return resp, nil
+}
+
+// analyzeQuery 对查询语句依次进行分词、去停词以及词干提取, 语种为auto时按分词器检测出的语种处理.
+// 停词按所访问快照构造时的停词表移除, 与快照中的文档保持一致.
+func (h *Container) analyzeQuery(ctx context.Context, snapshot *indexing.TFIDF, text string) (*common.ConcordanceWrapper, error) {
+	query := h.tokenizer.QueryTokenize(text, h.language)
+	if utils.IsContextDone(ctx) {
+		return nil, utils.ErrContextDone
+	}
+
+	h.stoper.QueryRemoveStopWords(snapshot.StopWords, query.Language, query)
+	if utils.IsContextDone(ctx) {
+		return nil, utils.ErrContextDone
+	}
+
+	h.stemmer.QueryApplyStemming(query.Language, query)
+	if utils.IsContextDone(ctx) {
+		return nil, utils.ErrContextDone
+	}
+
+	return query, nil
+}
+
+// TriggerCheckpoint 立即dump一次索引, 期间写入与查询照常进行, 返回新dump的代数.
+func (h *Container) TriggerCheckpoint(ctx context.Context) (*pb.TriggerCheckpointResponse, error) {
+	if !h.indexer.ServiceAvailable() {
+		return nil, utils.ErrServiceUnavailable
+	}
+
+	generation, err := h.checkpointer.Trigger(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TriggerCheckpointResponse{
+		Generation: generation,
+		Document:   h.indexer.GetDoc(),
+	}, nil
+}
+
+// GetSystemInfo 获取系统信息.
+func (h *Container) GetSystemInfo() (*pb.GetSystemInfoResponse, error) {
+	if !h.indexer.ServiceAvailable() {
+		return nil, utils.ErrServiceUnavailable
+	}
+
+	return &pb.GetSystemInfoResponse{
+		DocumentCapacity:   h.indexer.GetDocCapacity(),
+		Document:           h.indexer.GetDoc(),
+		VocabularyCapacity: h.indexer.GetVocabularyCapacity(),
+		Vocabulary:         h.indexer.GetVocabulary(),
+		ServiceStatus:      pb.ServiceStatus_Available,
+	}, nil
+}
+
+// AddDictionaryTerms 向可编辑的用户词典添加词条并重新加载词典, 之后分词的文档与查询语句使用新词典.
+func (h *Container) AddDictionaryTerms(ctx context.Context, req *pb.AddDictionaryTermsRequest) (*pb.DictionaryResponse, error) {
+	terms := make([]*tokenize.UserTerm, len(req.GetTerms()))
+	for idx, term := range req.GetTerms() {
+		terms[idx] = &tokenize.UserTerm{
+			Text:      term.GetText(),
+			Frequency: int(term.GetFrequency()),
+			POS:       term.GetPos(),
+		}
+	}
+	if err := h.dictionary.AddTerms(terms); err != nil {
+		return nil, err
+	}
+	return h.dictionaryInfo(0), nil
+}
+
+// RemoveDictionaryTerms 从可编辑的用户词典删除词条并重新加载词典.
+func (h *Container) RemoveDictionaryTerms(ctx context.Context, req *pb.RemoveDictionaryTermsRequest) (*pb.DictionaryResponse, error) {
+	if err := h.dictionary.RemoveTerms(req.GetTexts()); err != nil {
+		return nil, err
+	}
+	return h.dictionaryInfo(0), nil
+}
+
+// ReloadDictionary 重新加载全部词典文件, 用于在外部修改词典文件之后生效.
+func (h *Container) ReloadDictionary(ctx context.Context) (*pb.DictionaryResponse, error) {
+	if err := h.dictionary.Reload(); err != nil {
+		return nil, err
+	}
+	return h.dictionaryInfo(0), nil
+}
+
+// GetDictionaryInfo 获取当前的词典版本, 以及使用其他词典版本分词、需要重新索引的文档.
+func (h *Container) GetDictionaryInfo(ctx context.Context, req *pb.GetDictionaryInfoRequest) (*pb.DictionaryResponse, error) {
+	if !h.indexer.ServiceAvailable() {
+		return nil, utils.ErrServiceUnavailable
+	}
+	if h.dictionary.Version() == "" {
+		return nil, tokenize.ErrDictionaryNotLoaded
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = _DefaultStaleDocsLimit
+	}
+	return h.dictionaryInfo(limit), nil
+}
+
+// dictionaryInfo 汇总词典信息, 返回至多limit个需要重新索引的文档ID.
+func (h *Container) dictionaryInfo(limit int) *pb.DictionaryResponse {
+	version := h.dictionary.Version()
+	docIDs, stale := h.indexer.StaleDocs(version, limit)
+	return &pb.DictionaryResponse{
+		Version:     version,
+		UserTerms:   uint64(h.dictionary.UserTerms()),
+		StaleDocs:   stale,
+		StaleDocIds: docIDs,
+	}
+}
+
+// ListStopWords 列出停词表中的停词.
+func (h *Container) ListStopWords(ctx context.Context, req *pb.ListStopWordsRequest) (*pb.StopWordsResponse, error) {
+	set := h.stopWords.Current()
+	words, err := set.Words(req.GetList())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.StopWordsResponse{
+		Version:      set.Version,
+		QueryVersion: h.queryStopWords().Version,
+		Total:        uint64(len(words)),
+		Words:        words,
+	}, nil
+}
+
+// AddStopWords 向停词表添加停词, 之后处理的文档立即生效, 查询语句在构造下一个索引快照之后生效.
+func (h *Container) AddStopWords(ctx context.Context, req *pb.EditStopWordsRequest) (*pb.StopWordsResponse, error) {
+	set, err := h.stopWords.Add(req.GetList(), req.GetWords())
+	if err != nil {
+		return nil, err
+	}
+	return h.stopWordsInfo(req.GetList(), set), nil
+}
+
+// RemoveStopWords 从停词表删除停词, 生效时机与AddStopWords相同.
+func (h *Container) RemoveStopWords(ctx context.Context, req *pb.EditStopWordsRequest) (*pb.StopWordsResponse, error) {
+	set, err := h.stopWords.Remove(req.GetList(), req.GetWords())
+	if err != nil {
+		return nil, err
+	}
+	return h.stopWordsInfo(req.GetList(), set), nil
+}
+
+// queryStopWords 返回当前快照记录的停词表, 即新的查询语句使用的停词表.
+func (h *Container) queryStopWords() *stopword.Set {
+	if set := h.indexer.Snapshot().StopWords; set != nil {
+		return set
+	}
+	return h.stopWords.Current()
+}
+
+// stopWordsInfo 汇总修改之后的停词表信息, 不返回停词本身.
+func (h *Container) stopWordsInfo(list string, set *stopword.Set) *pb.StopWordsResponse {
+	return &pb.StopWordsResponse{
+		Version:      set.Version,
+		QueryVersion: h.queryStopWords().Version,
+		Total:        uint64(set.Len(list)),
+	}
+}
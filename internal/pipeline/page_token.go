@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/fnv"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
+)
+
+// pageToken 分页令牌, 绑定生成它的快照 (纪元与代数) 以及查询条件.
+// 快照代数在重启之后从头计数, 纪元用于区分不同进程发布的同一代数的快照.
+type pageToken struct {
+	Epoch       uint64 `json:"e"`
+	Generation  uint64 `json:"g"`
+	Offset      uint32 `json:"o"`
+	Fingerprint uint64 `json:"f"`
+}
+
+func encodePageToken(token *pageToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(s string) (*pageToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, utils.ErrInvalidPageToken
+	}
+	token := new(pageToken)
+	if err = json.Unmarshal(data, token); err != nil {
+		return nil, utils.ErrInvalidPageToken
+	}
+	return token, nil
+}
+
+// queryFingerprint 计算查询条件的指纹, 防止令牌被用于其他查询.
+func queryFingerprint(query string, ranking pb.RankingFunction) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(query))            // nolint
+	h.Write([]byte{0})                // nolint
+	h.Write([]byte(ranking.String())) // nolint
+	return h.Sum64()
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/utils"
+)
+
+func TestPageToken(t *testing.T) {
+	token := &pageToken{
+		Epoch:       0x9e3779b97f4a7c15,
+		Generation:  7,
+		Offset:      20,
+		Fingerprint: queryFingerprint("粮食 保险", pb.RankingFunction_BM25),
+	}
+
+	decoded, err := decodePageToken(encodePageToken(token))
+	assert.Empty(t, err)
+	assert.Equal(t, token, decoded)
+
+	assert.NotEqual(t, token.Fingerprint, queryFingerprint("粮食 保险", pb.RankingFunction_TFIDFCosine))
+	assert.NotEqual(t, token.Fingerprint, queryFingerprint("粮食", pb.RankingFunction_BM25))
+
+	_, err = decodePageToken("not a token")
+	assert.Equal(t, utils.ErrInvalidPageToken, err)
+}
@@ -0,0 +1,180 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/parse"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stemming"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/stopword"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/tokenize"
+)
+
+var (
+	// ErrUnknownStage 阶段名未注册错误
+	ErrUnknownStage = errors.New("unknown stage")
+	// ErrUnknownLanguage 未知语种错误
+	ErrUnknownLanguage = errors.New("unknown language")
+	// ErrBadStages 管道阶段声明错误
+	ErrBadStages = errors.New("bad stages")
+)
+
+// StageFactory 根据阶段配置构建阶段的处理逻辑.
+type StageFactory func(env *StageEnv, cfg *conf.StageConfig) (Processor, error)
+
+// StageEnv 构建各个阶段时共享的依赖.
+// Tokenizer/StopWords/Stemmer按管道的语种构建, 同时用于分析查询语句,
+// 未单独指定语种的阶段直接复用, 避免重复加载词典; 单独指定语种的阶段与之共用Dictionary/StopWordLists/Normalizer.
+type StageEnv struct {
+	Config        *conf.PipelineConfig
+	Storage       storage.Persister
+	Docs          parse.DocSaver
+	Indexer       *indexing.PipeIndexProcessor
+	Language      common.LanguageType
+	Dictionary    *tokenize.Dictionary
+	StopWordLists *stopword.Lists
+	Normalizer    stemming.Normalizer
+	Tokenizer     *tokenize.PipeTokenizeProcessor
+	StopWords     *stopword.PipeStopWordsProcessor
+	Stemmer       *stemming.PipeStemmingProcessor
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]StageFactory)
+)
+
+// RegisterStage 以name注册阶段, 重复注册时panic.
+func RegisterStage(name string, factory StageFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("pipeline: register nil stage factory " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("pipeline: register stage twice " + name)
+	}
+	registry[name] = factory
+}
+
+// LookupStage 按name查找已注册的阶段.
+func LookupStage(name string) (StageFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+func init() {
+	RegisterStage(common.StageParse, func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		sites, err := parse.NewSiteAdapters(env.Config.Sites)
+		if err != nil {
+			return nil, err
+		}
+		return parse.NewPipeParseProcessor(env.Storage, env.Docs, sites, env.Config.JSONDoc), nil
+	})
+	RegisterStage(common.StageTokenize, func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		language, shared, err := stageLanguage(env, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if shared {
+			return env.Tokenizer, nil
+		}
+		return tokenize.NewPipeTokenizeProcessor(env.Storage, language, env.Config.Tokenizer, env.Dictionary)
+	})
+	RegisterStage(common.StageStopWords, func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		language, shared, err := stageLanguage(env, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if shared {
+			return env.StopWords, nil
+		}
+		return stopword.NewPipeStopWordsProcessor(language, env.StopWordLists), nil
+	})
+	RegisterStage(common.StageStemming, func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		language, shared, err := stageLanguage(env, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if shared {
+			return env.Stemmer, nil
+		}
+		return stemming.NewPipeStemmingProcessor(language, env.Normalizer), nil
+	})
+	RegisterStage(common.StageIndexing, func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		return env.Indexer, nil
+	})
+}
+
+// ParseLanguage 解析语种名, 为空时返回中文.
+func ParseLanguage(name string) (common.LanguageType, error) {
+	if name == "" {
+		return common.LanguageTypeChinsese, nil
+	}
+	language, ok := common.LanguageName2Type[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownLanguage, name)
+	}
+	return language, nil
+}
+
+// stageLanguage 返回阶段使用的语种, 以及该语种是否与管道的语种一致.
+func stageLanguage(env *StageEnv, cfg *conf.StageConfig) (common.LanguageType, bool, error) {
+	if cfg.Language == "" {
+		return env.Language, true, nil
+	}
+	language, err := ParseLanguage(cfg.Language)
+	if err != nil {
+		return 0, false, err
+	}
+	return language, language == env.Language, nil
+}
+
+// DefaultStages 返回默认的管道阶段声明.
+func DefaultStages() []*conf.StageConfig {
+	return []*conf.StageConfig{
+		{Name: common.StageParse},
+		{Name: common.StageTokenize},
+		{Name: common.StageStopWords},
+		{Name: common.StageStemming},
+		{Name: common.StageIndexing},
+	}
+}
+
+// BuildPipeline 按声明的顺序构建管道, 声明为空时使用默认阶段.
+// 阶段名不可重复 (阶段名用于记录死信), 最后一个阶段必须为indexing.
+func BuildPipeline(env *StageEnv, stages []*conf.StageConfig) (*Pipeline, error) {
+	if len(stages) == 0 {
+		stages = DefaultStages()
+	}
+	if stages[len(stages)-1].Name != common.StageIndexing {
+		return nil, fmt.Errorf("%w: the last stage must be %s", ErrBadStages, common.StageIndexing)
+	}
+
+	seen := make(map[string]struct{}, len(stages))
+	built := make([]*Stage, 0, len(stages))
+	for _, cfg := range stages {
+		if _, dup := seen[cfg.Name]; dup {
+			return nil, fmt.Errorf("%w: duplicate stage %s", ErrBadStages, cfg.Name)
+		}
+		seen[cfg.Name] = struct{}{}
+
+		factory, ok := LookupStage(cfg.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownStage, cfg.Name)
+		}
+		processor, err := factory(env, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build stage %s: %w", cfg.Name, err)
+		}
+		built = append(built, NewStage(cfg.Name, processor, cfg.Workers, cfg.QueueSize))
+	}
+	return NewPipeline(built...), nil
+}
@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+)
+
+func init() {
+	// 按文本构建concordance, 代替从存储中读取文档并分词
+	RegisterStage("test-tokenize", func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		return processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			doc.Concordance = common.NewConcordanceWrapper(doc.Packet.DocId)
+			for position, term := range strings.Fields(doc.Packet.DocTitle) {
+				doc.Concordance.Add(term, uint32(position))
+			}
+			return doc, nil
+		}), nil
+	})
+	// 丢弃重复的文档
+	RegisterStage("test-dedup", func(env *StageEnv, cfg *conf.StageConfig) (Processor, error) {
+		var seen sync.Map
+		return processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			if _, dup := seen.LoadOrStore(doc.Packet.DocId, struct{}{}); dup {
+				doc.Ack.Done()
+				return nil, nil
+			}
+			return doc, nil
+		}), nil
+	})
+}
+
+func TestBuildPipeline(t *testing.T) {
+	indexer := indexing.NewPipeIndexProcessor(&conf.IndexerConfig{}, nil)
+	env := &StageEnv{Indexer: indexer, Language: common.LanguageTypeEnglish}
+
+	p, err := BuildPipeline(env, []*conf.StageConfig{
+		{Name: "test-tokenize", Workers: 2, QueueSize: 2},
+		{Name: "test-dedup", Workers: 1},
+		{Name: common.StageIndexing},
+	})
+	assert.Empty(t, err)
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.Name()
+	}
+	assert.Equal(t, []string{"test-tokenize", "test-dedup", common.StageIndexing}, names)
+	assert.Equal(t, 2, p.stages[0].workers)
+	assert.Equal(t, _DefaultQueueSize, cap(p.stages[1].input))
+
+	p.Start(context.Background())
+	for _, docID := range []string{"1", "2", "1", "3", "2"} {
+		p.Input() <- &common.Document{Packet: &pb.Packet{DocId: docID, DocTitle: "fiscal budget " + docID}}
+	}
+	p.Stop()
+	assert.Equal(t, uint64(3), indexer.GetDoc())
+}
+
+func TestBuildPipelineDefault(t *testing.T) {
+	p, err := BuildPipeline(&StageEnv{Config: &conf.PipelineConfig{}}, nil)
+	assert.Empty(t, err)
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.Name()
+	}
+	assert.Equal(t, []string{
+		common.StageParse, common.StageTokenize, common.StageStopWords, common.StageStemming, common.StageIndexing,
+	}, names)
+}
+
+func TestBuildPipelineBadStages(t *testing.T) {
+	env := &StageEnv{Config: &conf.PipelineConfig{}}
+	_, err := BuildPipeline(env, []*conf.StageConfig{{Name: "not-exist"}, {Name: common.StageIndexing}})
+	assert.True(t, errors.Is(err, ErrUnknownStage))
+	_, err = BuildPipeline(env, []*conf.StageConfig{{Name: common.StageIndexing}, {Name: "test-dedup"}})
+	assert.True(t, errors.Is(err, ErrBadStages))
+	_, err = BuildPipeline(env, []*conf.StageConfig{{Name: "test-dedup"}, {Name: "test-dedup"}, {Name: common.StageIndexing}})
+	assert.True(t, errors.Is(err, ErrBadStages))
+	_, err = BuildPipeline(env, []*conf.StageConfig{{Name: common.StageStopWords, Language: "klingon"}, {Name: common.StageIndexing}})
+	assert.True(t, errors.Is(err, ErrUnknownLanguage))
+}
@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+)
+
+const (
+	// 每个阶段默认的worker数
+	_DefaultWorkers = 20
+	// 每个阶段默认的输入队列长度
+	_DefaultQueueSize = 20
+)
+
+// Processor 管道阶段的处理逻辑, 需保证并发安全.
+type Processor interface {
+	// Process 处理一个文档, 返回交给下游的文档, 返回nil表示文档不再向下游传递.
+	// 非最后一个阶段返回nil时 (例如过滤、去重), 文档被视为处理完毕并由所在阶段确认;
+	// 最后一个阶段返回nil时由处理逻辑自行在文档持久化之后确认.
+	// 返回错误时文档被丢弃, 由所在阶段通过确认句柄报告失败.
+	Process(ctx context.Context, doc *common.Document) (*common.Document, error)
+}
+
+// Stage 管道阶段, 由固定数目的worker从有界的输入队列中阻塞地读取文档并处理.
+// 输入队列写满时上游的写入被阻塞, 从而将背压逐级传递到消息消费端.
+type Stage struct {
+	name      string
+	processor Processor
+	workers   int
+	input     chan *common.Document
+	// 下游阶段的输入队列, 最后一个阶段为nil
+	output chan *common.Document
+	wg     sync.WaitGroup
+}
+
+// NewStage 新建管道阶段, workers与queueSize不大于0时使用默认值.
+func NewStage(name string, processor Processor, workers, queueSize int) *Stage {
+	if workers <= 0 {
+		workers = _DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = _DefaultQueueSize
+	}
+	return &Stage{
+		name:      name,
+		processor: processor,
+		workers:   workers,
+		input:     make(chan *common.Document, queueSize),
+	}
+}
+
+// Name 返回阶段名.
+func (s *Stage) Name() string {
+	return s.name
+}
+
+// start 启动所有worker, 输入队列关闭且排空之后关闭下游的输入队列.
+func (s *Stage) start(ctx context.Context) {
+	s.wg.Add(s.workers)
+	for i := 0; i < s.workers; i++ {
+		go s.work(ctx)
+	}
+	go func() {
+		s.wg.Wait()
+		if s.output != nil {
+			close(s.output)
+		}
+		log.Info().Msgf("stage %s stopped", s.name)
+	}()
+	log.Info().Msgf("stage %s started, workers=%d, queue_size=%d", s.name, s.workers, cap(s.input))
+}
+
+func (s *Stage) work(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			{
+				// 未处理完的文档不会被确认, 重启之后重新消费
+				return
+			}
+		case doc, ok := <-s.input:
+			{
+				if !ok {
+					return
+				}
+				next, err := s.processor.Process(ctx, doc)
+				if err != nil && ctx.Err() != nil {
+					// 因取消而失败的文档不写入死信
+					return
+				}
+				if err != nil {
+					log.Error().Err(err).Msgf("stage %s cannot process doc, doc_id=%s", s.name, doc.Packet.GetDocId())
+					doc.Ack.Fail(s.name, err)
+					continue
+				}
+				if s.output == nil {
+					continue
+				}
+				if next == nil {
+					// 被过滤的文档不再有下游确认, 否则其所在分区的位移不会再向前提交
+					doc.Ack.Done()
+					continue
+				}
+				select {
+				case s.output <- next:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// Pipeline 由若干阶段首尾相连组成的处理管道
+type Pipeline struct {
+	stages []*Stage
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPipeline 按顺序连接各个阶段.
+func NewPipeline(stages ...*Stage) *Pipeline {
+	for i := 0; i+1 < len(stages); i++ {
+		stages[i].output = stages[i+1].input
+	}
+	return &Pipeline{stages: stages, done: make(chan struct{})}
+}
+
+// Start 启动所有阶段, ctx被取消时各阶段立即退出, 不再排空输入队列.
+func (p *Pipeline) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	for _, stage := range p.stages {
+		stage.start(ctx)
+	}
+	go func() {
+		for _, stage := range p.stages {
+			stage.wg.Wait()
+		}
+		close(p.done)
+	}()
+}
+
+// Input 返回第一个阶段的输入队列.
+func (p *Pipeline) Input() chan<- *common.Document {
+	return p.stages[0].input
+}
+
+// Stop 关闭第一个阶段的输入队列, 按顺序等待各阶段排空之后退出. 调用方需保证之后不再写入.
+func (p *Pipeline) Stop() {
+	close(p.stages[0].input)
+	<-p.done
+	p.cancel()
+}
@@ -0,0 +1,195 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+)
+
+type processorFunc func(ctx context.Context, doc *common.Document) (*common.Document, error)
+
+func (f processorFunc) Process(ctx context.Context, doc *common.Document) (*common.Document, error) {
+	return f(ctx, doc)
+}
+
+// collector 记录每个文档的确认结果
+type collector struct {
+	mu     sync.Mutex
+	done   []string
+	failed map[string]string
+}
+
+func newCollector() *collector {
+	return &collector{done: make([]string, 0), failed: make(map[string]string)}
+}
+
+func (c *collector) newDoc(docID string) *common.Document {
+	return &common.Document{
+		Packet: &pb.Packet{DocId: docID},
+		Ack: common.NewAck(func() {
+			c.mu.Lock()
+			c.done = append(c.done, docID)
+			c.mu.Unlock()
+		}, func(stage string, err error) {
+			c.mu.Lock()
+			c.failed[docID] = stage
+			c.mu.Unlock()
+		}),
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	c := newCollector()
+	var odd = errors.New("odd doc")
+	p := NewPipeline(
+		NewStage("parse", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			doc.Concordance = common.NewConcordanceWrapper(doc.Packet.DocId)
+			return doc, nil
+		}), 4, 2),
+		NewStage("filter", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			id, _ := strconv.Atoi(doc.Packet.DocId)
+			if id%2 == 1 {
+				return nil, odd
+			}
+			return doc, nil
+		}), 4, 2),
+		NewStage("indexing", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			// 模拟较慢的下游
+			time.Sleep(time.Millisecond)
+			doc.Ack.Done()
+			return nil, nil
+		}), 2, 2),
+	)
+	p.Start(context.Background())
+	for id := 0; id < 100; id++ {
+		p.Input() <- c.newDoc(strconv.Itoa(id))
+	}
+	// Stop返回时所有阶段均已排空
+	p.Stop()
+
+	assert.Equal(t, 50, len(c.done))
+	assert.Equal(t, 50, len(c.failed))
+	sort.Strings(c.done)
+	for _, docID := range c.done {
+		id, _ := strconv.Atoi(docID)
+		assert.Equal(t, 0, id%2)
+	}
+	for _, stage := range c.failed {
+		assert.Equal(t, "filter", stage)
+	}
+}
+
+func TestPipelineAcksFilteredDocs(t *testing.T) {
+	c := newCollector()
+	p := NewPipeline(
+		NewStage("dedup", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			// 过滤掉偶数文档, 不再向下游传递
+			id, _ := strconv.Atoi(doc.Packet.DocId)
+			if id%2 == 0 {
+				return nil, nil
+			}
+			return doc, nil
+		}), 4, 2),
+		NewStage("indexing", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			doc.Ack.Done()
+			return nil, nil
+		}), 2, 2),
+	)
+	p.Start(context.Background())
+	for id := 0; id < 100; id++ {
+		p.Input() <- c.newDoc(strconv.Itoa(id))
+	}
+	p.Stop()
+
+	// 与kafka的位移跟踪一致, 只有连续确认的文档之后位移才会向前提交
+	acked := make(map[int]struct{}, len(c.done))
+	for _, docID := range c.done {
+		id, _ := strconv.Atoi(docID)
+		acked[id] = struct{}{}
+	}
+	committed := 0
+	for {
+		if _, ok := acked[committed]; !ok {
+			break
+		}
+		committed++
+	}
+	assert.Equal(t, 100, committed)
+	assert.Empty(t, c.failed)
+}
+
+func TestPipelineBackPressure(t *testing.T) {
+	c := newCollector()
+	release := make(chan struct{})
+	p := NewPipeline(
+		NewStage("parse", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			return doc, nil
+		}), 1, 1),
+		NewStage("indexing", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			<-release
+			doc.Ack.Done()
+			return nil, nil
+		}), 1, 1),
+	)
+	p.Start(context.Background())
+
+	// 下游阻塞时, 每个阶段最多持有 worker数 + 队列长度 个文档, 之后写入被阻塞
+	sent := make(chan int)
+	go func() {
+		n := 0
+		for id := 0; id < 10; id++ {
+			p.Input() <- c.newDoc(strconv.Itoa(id))
+			n++
+		}
+		sent <- n
+	}()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-sent:
+		t.Fatal("input should be blocked by back-pressure")
+	default:
+	}
+
+	close(release)
+	assert.Equal(t, 10, <-sent)
+	p.Stop()
+	assert.Equal(t, 10, len(c.done))
+}
+
+func TestPipelineCancel(t *testing.T) {
+	c := newCollector()
+	ctx, cancel := context.WithCancel(context.Background())
+	block := make(chan struct{})
+	p := NewPipeline(
+		NewStage("indexing", processorFunc(func(ctx context.Context, doc *common.Document) (*common.Document, error) {
+			select {
+			case <-block:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return nil, nil
+		}), 1, 4),
+	)
+	p.Start(ctx)
+	p.Input() <- c.newDoc("1")
+	p.Input() <- c.newDoc("2")
+
+	cancel()
+	select {
+	case <-p.done:
+	case <-time.After(time.Second):
+		t.Fatal("pipeline should exit after cancel")
+	}
+	// 未处理完的文档既不会被确认, 也不会报告失败
+	assert.Empty(t, c.done)
+	assert.Empty(t, c.failed)
+}
@@ -0,0 +1,205 @@
+package query
+
+import (
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+)
+
+// Analyzer 将词条或短语文本转换为索引词条, 与文档走同样的分词、去停词以及词干提取流程.
+type Analyzer func(text string) (*common.ConcordanceWrapper, error)
+
+// evaluator 在一份快照的倒排列表上求值查询语法树
+type evaluator struct {
+	t       *indexing.TFIDF
+	analyze Analyzer
+	// 非排除子句中的词条, 用于为候选文档打分
+	concordance map[string]uint64
+}
+
+// Evaluate 在快照的倒排列表上求值查询语法树, 得到候选文档以及参与打分的词条.
+// 分析后为空的词条或短语 (例如停词) 不构成约束.
+func Evaluate(t *indexing.TFIDF, node Node, analyze Analyzer) (*indexing.Query, error) {
+	e := &evaluator{
+		t:           t,
+		analyze:     analyze,
+		concordance: make(map[string]uint64),
+	}
+	candidates, err := e.eval(node, false)
+	if err != nil {
+		return nil, err
+	}
+	if candidates == nil {
+		// 整个查询都不构成约束, 此时也没有可以打分的词条
+		candidates = make(map[uint64]int)
+	}
+	return &indexing.Query{
+		Concordance: e.concordance,
+		Candidates:  candidates,
+	}, nil
+}
+
+// eval 返回满足节点的文档序号到短语命中次数的映射, 节点不构成约束时返回nil.
+func (e *evaluator) eval(node Node, negated bool) (map[uint64]int, error) {
+	switch n := node.(type) {
+	case *TermNode:
+		{
+			return e.evalTerm(n, negated)
+		}
+	case *PhraseNode:
+		{
+			return e.evalPhrase(n, negated)
+		}
+	case *BooleanNode:
+		{
+			return e.evalBoolean(n, negated)
+		}
+	}
+	return nil, nil
+}
+
+func (e *evaluator) evalTerm(n *TermNode, negated bool) (map[uint64]int, error) {
+	analyzed, err := e.analyze(n.Text)
+	if err != nil {
+		return nil, err
+	}
+	if len(analyzed.Concordance) == 0 {
+		return nil, nil
+	}
+	docs := make(map[uint64]int)
+	for term, freq := range analyzed.Concordance {
+		if !negated {
+			e.concordance[term] += freq
+		}
+		if tp, ok := e.t.Terms.Get(term); ok {
+			for _, posting := range tp.Postings {
+				docs[posting.DocIdx] = 0
+			}
+		}
+	}
+	return docs, nil
+}
+
+func (e *evaluator) evalPhrase(n *PhraseNode, negated bool) (map[uint64]int, error) {
+	analyzed, err := e.analyze(n.Text)
+	if err != nil {
+		return nil, err
+	}
+	if len(analyzed.Concordance) == 0 {
+		return nil, nil
+	}
+	if !negated {
+		for term, freq := range analyzed.Concordance {
+			e.concordance[term] += freq
+		}
+	}
+	return e.t.MatchPhrase(indexing.NewPhraseQuery(analyzed, n.Proximity, n.Slop)), nil
+}
+
+func (e *evaluator) evalBoolean(n *BooleanNode, negated bool) (map[uint64]int, error) {
+	var must, should, mustNot map[uint64]int
+	for _, c := range n.Clauses {
+		if group, ok := c.Node.(*BooleanNode); ok && c.Occur != OccurMustNot && !positive(group) {
+			// 只含排除子句的分组 (例如 a (NOT b)) 作为所在组合的排除条件, 而不是以全部文档为基础求值
+			docs, err := e.evalExclusion(group, negated)
+			if err != nil {
+				return nil, err
+			}
+			if docs != nil {
+				mustNot = union(mustNot, docs)
+			}
+			continue
+		}
+		docs, err := e.eval(c.Node, negated != (c.Occur == OccurMustNot))
+		if err != nil {
+			return nil, err
+		}
+		if docs == nil {
+			continue
+		}
+		switch c.Occur {
+		case OccurMust:
+			must = intersect(must, docs)
+		case OccurShould:
+			should = union(should, docs)
+		case OccurMustNot:
+			mustNot = union(mustNot, docs)
+		}
+	}
+
+	var docs map[uint64]int
+	if must != nil {
+		docs = must
+		// 有必选子句时, 可选子句只贡献短语命中次数
+		for docIdx, n := range should {
+			if _, ok := docs[docIdx]; ok {
+				docs[docIdx] += n
+			}
+		}
+	} else if should != nil {
+		docs = should
+	} else if mustNot != nil {
+		// 只有排除子句时以全部文档为基础, 已删除文档的向量为空
+		docs = make(map[uint64]int, e.t.Doc)
+		for i, v := range e.t.Vectors {
+			if v.DocID != "" {
+				docs[uint64(i+1)] = 0
+			}
+		}
+	} else {
+		return nil, nil
+	}
+	for docIdx := range mustNot {
+		delete(docs, docIdx)
+	}
+	return docs, nil
+}
+
+// evalExclusion 返回只含排除子句的分组所排除的文档, 分组不构成约束时返回nil.
+func (e *evaluator) evalExclusion(n *BooleanNode, negated bool) (map[uint64]int, error) {
+	var excluded map[uint64]int
+	for _, c := range n.Clauses {
+		var docs map[uint64]int
+		var err error
+		if c.Occur == OccurMustNot {
+			docs, err = e.eval(c.Node, !negated)
+		} else {
+			// 只含排除子句的分组中, 非排除子句同样是只含排除子句的分组
+			docs, err = e.evalExclusion(c.Node.(*BooleanNode), negated)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if docs != nil {
+			excluded = union(excluded, docs)
+		}
+	}
+	return excluded, nil
+}
+
+// intersect 求交集, a为nil时返回b的副本.
+func intersect(a, b map[uint64]int) map[uint64]int {
+	ret := make(map[uint64]int)
+	if a == nil {
+		for docIdx, n := range b {
+			ret[docIdx] = n
+		}
+		return ret
+	}
+	for docIdx, n := range a {
+		if m, ok := b[docIdx]; ok {
+			ret[docIdx] = n + m
+		}
+	}
+	return ret
+}
+
+// union 求并集, 结果复用a.
+func union(a, b map[uint64]int) map[uint64]int {
+	if a == nil {
+		a = make(map[uint64]int, len(b))
+	}
+	for docIdx, n := range b {
+		a[docIdx] += n
+	}
+	return a
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+)
+
+// buildSnapshot 用按空格切分的文档构造快照, 文档序号从1开始.
+func buildSnapshot(docs []string) *indexing.TFIDF {
+	t := &indexing.TFIDF{
+		Doc:     uint64(len(docs)),
+		Vectors: make([]*indexing.DocVector, len(docs)),
+		Terms:   indexing.NewTermDict(),
+	}
+	for i, doc := range docs {
+		t.Vectors[i] = &indexing.DocVector{DocID: fmt.Sprintf("%d", i+1)}
+		w := analyze(doc)
+		for term, positions := range w.Positions {
+			tp, ok := t.Terms.Get(term)
+			if !ok {
+				tp = &indexing.TermPostings{}
+				t.Terms.Add(term, tp)
+			}
+			tp.DocFrequency++
+			tp.Postings = append(tp.Postings, indexing.FrozenPosting{
+				DocIdx:        uint64(i + 1),
+				TermFrequency: w.Concordance[term],
+				Positions:     positions,
+			})
+		}
+	}
+	return t
+}
+
+// analyze 按空格切分文本并丢弃停词"的".
+func analyze(text string) *common.ConcordanceWrapper {
+	w := common.NewConcordanceWrapper("")
+	for position, term := range strings.Fields(text) {
+		if term != "的" {
+			w.Add(term, uint32(position))
+		}
+	}
+	return w
+}
+
+func TestEvaluate(t *testing.T) {
+	s := buildSnapshot([]string{
+		"粮食 作物 保险",
+		"粮食 收入 保险",
+		"财政 预算",
+		"保险 试点",
+	})
+	cases := map[string]map[uint64]int{
+		`粮食 预算`:                 {1: 0, 2: 0, 3: 0},
+		`+保险 粮食`:                {1: 0, 2: 0, 4: 0},
+		`粮食 AND 保险`:             {1: 0, 2: 0},
+		`保险 -收入`:                {1: 0, 4: 0},
+		`保险 AND NOT (作物 OR 试点)`: {2: 0},
+		`"粮食 作物" 预算`:            {1: 1, 3: 0},
+		`+保险 "粮食 保险"~1`:         {1: 1, 2: 1, 4: 0},
+		`预算 OR (NOT 保险)`:        {3: 0},
+		`保险 (NOT 粮食)`:           {4: 0},
+		`保险 AND (NOT 粮食)`:       {4: 0},
+		`保险 ((NOT 粮食) -试点)`:     {},
+		`保险 NOT (NOT 粮食)`:       {1: 0, 2: 0},
+		`的 AND 预算`:              {3: 0},
+		`不存在`:                   {},
+		`的`:                     {},
+	}
+	for q, expected := range cases {
+		node, err := Parse(q)
+		assert.Empty(t, err, q)
+		ret, err := Evaluate(s, node, func(text string) (*common.ConcordanceWrapper, error) {
+			return analyze(text), nil
+		})
+		assert.Empty(t, err, q)
+		assert.Equal(t, expected, ret.Candidates, q)
+	}
+
+	node, _ := Parse(`+保险 -收入 "粮食 作物"`)
+	ret, _ := Evaluate(s, node, func(text string) (*common.ConcordanceWrapper, error) {
+		return analyze(text), nil
+	})
+	// 排除子句中的词条不参与打分
+	assert.Equal(t, map[string]uint64{"保险": 1, "粮食": 1, "作物": 1}, ret.Concordance)
+}
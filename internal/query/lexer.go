@@ -0,0 +1,132 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenPlus
+	tokenMinus
+	tokenLParen
+	tokenRParen
+)
+
+// token 词法单元
+type token struct {
+	kind tokenKind
+	text string
+	// 词法单元在查询语句中的字节偏移, 用于报告语法错误
+	pos int
+	// 邻近查询的距离, 仅对tokenPhrase有效
+	proximity bool
+	slop      uint32
+}
+
+func (t *token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenPhrase:
+		return fmt.Sprintf("phrase %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// lex 将查询语句切分为词法单元, 末尾总是tokenEOF.
+func lex(s string) ([]*token, error) {
+	tokens := make([]*token, 0)
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			{
+				i += size
+			}
+		case r == '(':
+			{
+				tokens = append(tokens, &token{kind: tokenLParen, text: "(", pos: i})
+				i += size
+			}
+		case r == ')':
+			{
+				tokens = append(tokens, &token{kind: tokenRParen, text: ")", pos: i})
+				i += size
+			}
+		case r == '"':
+			{
+				end := strings.IndexByte(s[i+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("%w: unterminated phrase at offset %d", ErrSyntax, i)
+				}
+				t := &token{kind: tokenPhrase, text: s[i+1 : i+1+end], pos: i}
+				i += end + 2
+				if i < len(s) && s[i] == '~' {
+					j := i + 1
+					for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+						j++
+					}
+					slop, err := strconv.ParseUint(s[i+1:j], 10, 32)
+					if err != nil {
+						return nil, fmt.Errorf("%w: invalid proximity at offset %d", ErrSyntax, i)
+					}
+					t.proximity = true
+					t.slop = uint32(slop)
+					i = j
+				}
+				tokens = append(tokens, t)
+			}
+		case r == '+' || r == '-':
+			{
+				// 前缀运算符必须紧贴其后的子句
+				if next, _ := utf8.DecodeRuneInString(s[i+size:]); i+size >= len(s) || unicode.IsSpace(next) || next == ')' {
+					return nil, fmt.Errorf("%w: dangling %q at offset %d", ErrSyntax, r, i)
+				}
+				kind := tokenPlus
+				if r == '-' {
+					kind = tokenMinus
+				}
+				tokens = append(tokens, &token{kind: kind, text: string(r), pos: i})
+				i += size
+			}
+		default:
+			{
+				j := i
+				for j < len(s) {
+					r, size := utf8.DecodeRuneInString(s[j:])
+					if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+						break
+					}
+					j += size
+				}
+				t := &token{kind: tokenWord, text: s[i:j], pos: i}
+				// 只有大写形式才是运算符, 小写形式按普通词条处理
+				switch t.text {
+				case "AND":
+					t.kind = tokenAnd
+				case "OR":
+					t.kind = tokenOr
+				case "NOT":
+					t.kind = tokenNot
+				}
+				tokens = append(tokens, t)
+				i = j
+			}
+		}
+	}
+	tokens = append(tokens, &token{kind: tokenEOF, pos: len(s)})
+	return tokens, nil
+}
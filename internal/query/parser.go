@@ -0,0 +1,288 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSyntax 查询语句语法错误
+	ErrSyntax = errors.New("query syntax error")
+)
+
+// Occur 子句在布尔组合中的约束
+type Occur int
+
+const (
+	// OccurShould 可选子句, 组合中没有必选子句时至少满足一个可选子句
+	OccurShould Occur = iota
+	// OccurMust 必选子句
+	OccurMust
+	// OccurMustNot 排除子句
+	OccurMustNot
+)
+
+// Node 查询语法树节点
+type Node interface {
+	String() string
+}
+
+// TermNode 词条, 分析后得到多个索引词条时满足其一即可
+type TermNode struct {
+	Text string
+}
+
+// PhraseNode 短语或邻近查询
+type PhraseNode struct {
+	Text      string
+	Proximity bool
+	Slop      uint32
+}
+
+// Clause 布尔组合中的子句
+type Clause struct {
+	Occur Occur
+	Node  Node
+}
+
+// BooleanNode 布尔组合
+type BooleanNode struct {
+	Clauses []*Clause
+}
+
+func (n *TermNode) String() string {
+	return n.Text
+}
+
+func (n *PhraseNode) String() string {
+	if n.Proximity {
+		return fmt.Sprintf("%q~%d", n.Text, n.Slop)
+	}
+	return fmt.Sprintf("%q", n.Text)
+}
+
+func (n *BooleanNode) String() string {
+	s := "("
+	for i, c := range n.Clauses {
+		if i > 0 {
+			s += " "
+		}
+		switch c.Occur {
+		case OccurMust:
+			s += "+"
+		case OccurMustNot:
+			s += "-"
+		}
+		s += c.Node.String()
+	}
+	return s + ")"
+}
+
+// Parse 解析查询语句.
+//
+// 语法 (优先级由低到高):
+//
+//	a OR b        满足其一
+//	a b           并列子句, 未加前缀的子句为可选子句
+//	a AND b       同时满足
+//	NOT a, -a     排除
+//	+a            必须满足
+//	(...)         分组
+//	"..."         短语, "..."~N 为邻近查询
+//
+// 运算符只识别大写形式. 查询语句必须包含至少一个非排除的词条或短语.
+// 只含排除子句的分组作为所在组合的排除条件, 例如 a (NOT b) 与 a AND (NOT b) 均等价于 a -b.
+func Parse(s string) (Node, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected %s at offset %d", ErrSyntax, t, t.pos)
+	}
+	if !positive(node) {
+		return nil, fmt.Errorf("%w: query has no positive clause", ErrSyntax)
+	}
+	return node, nil
+}
+
+type parser struct {
+	tokens []*token
+	cur    int
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.cur]
+}
+
+func (p *parser) next() *token {
+	t := p.tokens[p.cur]
+	if t.kind != tokenEOF {
+		p.cur++
+	}
+	return t
+}
+
+// parseOr 解析 group { OR group }.
+func (p *parser) parseOr() (Node, error) {
+	node, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenOr {
+		return node, nil
+	}
+	or := &BooleanNode{Clauses: []*Clause{{Occur: OccurShould, Node: node}}}
+	for p.peek().kind == tokenOr {
+		p.next()
+		if node, err = p.parseGroup(); err != nil {
+			return nil, err
+		}
+		or.Clauses = append(or.Clauses, &Clause{Occur: OccurShould, Node: node})
+	}
+	return or, nil
+}
+
+// parseGroup 解析若干并列子句.
+func (p *parser) parseGroup() (Node, error) {
+	group := &BooleanNode{Clauses: make([]*Clause, 0)}
+	for {
+		switch t := p.peek(); t.kind {
+		case tokenOr, tokenRParen, tokenEOF:
+			{
+				if len(group.Clauses) == 0 {
+					return nil, fmt.Errorf("%w: missing clause before %s at offset %d", ErrSyntax, t, t.pos)
+				}
+				if len(group.Clauses) == 1 {
+					return clauseNode(group.Clauses[0]), nil
+				}
+				return group, nil
+			}
+		}
+		clause, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		group.Clauses = append(group.Clauses, clause)
+	}
+}
+
+// parseAnd 解析 unary { AND unary }.
+func (p *parser) parseAnd() (*Clause, error) {
+	clause, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenAnd {
+		return clause, nil
+	}
+	and := &BooleanNode{Clauses: []*Clause{must(clause)}}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		if clause, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		and.Clauses = append(and.Clauses, must(clause))
+	}
+	return &Clause{Occur: OccurShould, Node: and}, nil
+}
+
+// parseUnary 解析带前缀的子句.
+func (p *parser) parseUnary() (*Clause, error) {
+	switch p.peek().kind {
+	case tokenNot:
+		{
+			p.next()
+			clause, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &Clause{Occur: OccurMustNot, Node: clauseNode(clause)}, nil
+		}
+	case tokenPlus:
+		{
+			p.next()
+			node, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return &Clause{Occur: OccurMust, Node: node}, nil
+		}
+	case tokenMinus:
+		{
+			p.next()
+			node, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return &Clause{Occur: OccurMustNot, Node: node}, nil
+		}
+	}
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return &Clause{Occur: OccurShould, Node: node}, nil
+}
+
+// parsePrimary 解析词条、短语或括号分组.
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenWord:
+		{
+			return &TermNode{Text: t.text}, nil
+		}
+	case tokenPhrase:
+		{
+			return &PhraseNode{Text: t.text, Proximity: t.proximity, Slop: t.slop}, nil
+		}
+	case tokenLParen:
+		{
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if t := p.next(); t.kind != tokenRParen {
+				return nil, fmt.Errorf("%w: expect \")\" but got %s at offset %d", ErrSyntax, t, t.pos)
+			}
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unexpected %s at offset %d", ErrSyntax, t, t.pos)
+}
+
+// must 将AND连接的子句转换为必选子句, 排除子句保持不变.
+func must(c *Clause) *Clause {
+	if c.Occur == OccurMustNot {
+		return c
+	}
+	return &Clause{Occur: OccurMust, Node: c.Node}
+}
+
+// clauseNode 将单个子句转换为节点.
+func clauseNode(c *Clause) Node {
+	if c.Occur == OccurMustNot {
+		return &BooleanNode{Clauses: []*Clause{c}}
+	}
+	return c.Node
+}
+
+// positive 判断节点能否在不依赖全集的情况下命中文档.
+func positive(node Node) bool {
+	n, ok := node.(*BooleanNode)
+	if !ok {
+		return true
+	}
+	for _, c := range n.Clauses {
+		if c.Occur != OccurMustNot && positive(c.Node) {
+			return true
+		}
+	}
+	return false
+}
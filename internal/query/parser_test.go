@@ -0,0 +1,54 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		`粮食`:                   `粮食`,
+		`粮食 保险`:                `(粮食 保险)`,
+		`+粮食 -保险 试点`:           `(+粮食 -保险 试点)`,
+		`粮食 AND 保险`:            `(+粮食 +保险)`,
+		`粮食 AND NOT 保险`:        `(+粮食 -保险)`,
+		`粮食 OR 保险 AND 试点`:      `(粮食 (+保险 +试点))`,
+		`(粮食 OR 保险) AND -试点`:   `(+(粮食 保险) -试点)`,
+		`"收入 保险"~3 OR "粮食 作物"`: `("收入 保险"~3 "粮食 作物")`,
+		`粮食 -(保险 OR 试点)`:       `(粮食 -(保险 试点))`,
+		`NOT NOT 粮食 保险`:        `(-(-粮食) 保险)`,
+		`and or not`:           `(and or not)`,
+		`covid-19 +"粮食"`:       `(covid-19 +"粮食")`,
+	}
+	for s, expected := range cases {
+		node, err := Parse(s)
+		assert.Empty(t, err, s)
+		if err == nil {
+			assert.Equal(t, expected, node.String(), s)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`   `,
+		`(粮食`,
+		`粮食)`,
+		`()`,
+		`粮食 AND`,
+		`OR 粮食`,
+		`粮食 OR OR 保险`,
+		`"粮食`,
+		`"粮食"~`,
+		`粮食 - 保险`,
+		`粮食 +`,
+		`-粮食`,
+		`NOT 粮食 -保险`,
+	} {
+		_, err := Parse(s)
+		assert.Equal(t, true, errors.Is(err, ErrSyntax), s)
+	}
+}
@@ -1,132 +1,79 @@
 package stemming
 
 import (
-	"sync"
+	"context"
 
-	stemmer "github.com/agonopol/go-stem"
-	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
 	"github.com/rs/zerolog/log"
-)
 
-/*
-	https://tartarus.org/martin/PorterStemmer/index.html
-*/
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+)
 
 // PipeStemmingProcessor 词干抽取器
 type PipeStemmingProcessor struct {
 	tokenBucket chan struct{}
 	language    common.LanguageType
+	normalizer  Normalizer
 }
 
-// NewPipeStemmingProcessor 新建词干抽取器.
-func NewPipeStemmingProcessor(language common.LanguageType) *PipeStemmingProcessor {
+// NewPipeStemmingProcessor 新建词干抽取器, normalizer为nil时使用Porter词干提取.
+func NewPipeStemmingProcessor(language common.LanguageType, normalizer Normalizer) *PipeStemmingProcessor {
+	if normalizer == nil {
+		normalizer = Porter{}
+	}
 	log.Info().Msg("load PipeStemmingProcessor plugin")
 	return &PipeStemmingProcessor{
 		tokenBucket: make(chan struct{}, 20),
 		language:    language,
+		normalizer:  normalizer,
 	}
 }
 
-// ApplyStemming 抽取中/英文词汇的词干, 并重构concordance.
-func (p *PipeStemmingProcessor) ApplyStemming(pGroup *sync.WaitGroup, input common.ConcordanceChannel, output common.ConcordanceChannel) {
-	pGroup.Add(1)
-LOOP_LABEL:
-	for {
-		select {
-		case packet, ok := <-input:
-			{
-				if !ok {
-					close(output)
-					break LOOP_LABEL
-				}
-				switch p.language {
-				case common.LanguageTypeEnglish:
-					{
-						go p.applyEnglishStemming(packet, output)
-					}
-				case common.LanguageTypeChinsese:
-					{
-						go p.applyChineseStemming(packet, output)
-					}
-				default:
-					{
-
-					}
-				}
-			}
-		default:
-			{
-
-			}
-		}
+// Process 抽取中/英文词汇的词干, 并重构concordance (并发安全).
+// 语种为auto时按分词器检测出的语种抽取.
+func (p *PipeStemmingProcessor) Process(ctx context.Context, doc *common.Document) (*common.Document, error) {
+	language := p.language
+	if language == common.LanguageTypeAuto {
+		language = doc.Concordance.Language
 	}
-	pGroup.Done()
-	log.Info().Msg("unload PipeStemmingProcessor plugin")
+	p.applyLanguageStemming(language, doc.Concordance)
+	log.Debug().Msg("PipeStemmingProcessor processes one data packet")
+	return doc, nil
 }
 
-// QueryApplyStemming 抽取查询语句中的词干.
-func (p *PipeStemmingProcessor) QueryApplyStemming(language common.LanguageType, concordance map[string]uint64) {
+// QueryApplyStemming 抽取查询语句中的词干, 与文档使用同一条归一化链.
+func (p *PipeStemmingProcessor) QueryApplyStemming(language common.LanguageType, query *common.ConcordanceWrapper) {
 	p.tokenBucket <- struct{}{}
 
-	if language == common.LanguageTypeEnglish {
-		for k, v := range concordance {
-			out := string(stemmer.Stem([]byte(k)))
-			if vv, ok := concordance[out]; ok {
-				concordance[out] = vv + v
-				concordance[k] -= v
-			} else {
-				concordance[out] = v
-			}
-		}
-		for k, v := range concordance {
-			if v == 0 {
-				delete(concordance, k)
-			}
-		}
-	} else if language == common.LanguageTypeChinsese { // nolint
-		// 词干提取是英文语料预处理的一个步骤, 中文并不需要
-	}
+	p.applyLanguageStemming(language, query)
 
 	<-p.tokenBucket
 }
 
-func (p *PipeStemmingProcessor) applyEnglishStemming(packet *common.ConcordanceWrapper, output common.ConcordanceChannel) {
-	p.tokenBucket <- struct{}{}
-
-	for k, v := range packet.Concordance {
-		out := string(stemmer.Stem([]byte(k)))
-		if vv, ok := packet.Concordance[out]; ok {
-			packet.Concordance[out] = vv + v
-			packet.Concordance[k] -= v
-		} else {
-			packet.Concordance[out] = v
+// applyLanguageStemming 按语种抽取词干.
+// 词干提取是英文语料预处理的一个步骤, 中文并不需要; 中英文混合时只处理不含汉字的词条.
+func (p *PipeStemmingProcessor) applyLanguageStemming(language common.LanguageType, packet *common.ConcordanceWrapper) {
+	switch language {
+	case common.LanguageTypeEnglish:
+		{
+			applyEnglishStemming(p.normalizer, packet, false)
 		}
-	}
-	for k, v := range packet.Concordance {
-		if v == 0 {
-			delete(packet.Concordance, k)
+	case common.LanguageTypeMixed:
+		{
+			applyEnglishStemming(p.normalizer, packet, true)
 		}
 	}
-
-	output <- &common.ConcordanceWrapper{
-		DocID:       packet.DocID,
-		Concordance: packet.Concordance,
-	}
-	log.Debug().Msg("PipeStemmingProcessor processes one data packet")
-
-	<-p.tokenBucket
 }
 
-func (p *PipeStemmingProcessor) applyChineseStemming(packet *common.ConcordanceWrapper, output common.ConcordanceChannel) {
-	p.tokenBucket <- struct{}{}
-
-	// 词干提取是英文语料预处理的一个步骤, 中文并不需要
-
-	output <- &common.ConcordanceWrapper{
-		DocID:       packet.DocID,
-		Concordance: packet.Concordance,
+// applyEnglishStemming 将词条替换为归一化的结果, 结果相同的词条合并词频与位置. skipHan为true时跳过含有汉字的词条.
+func applyEnglishStemming(normalizer Normalizer, packet *common.ConcordanceWrapper, skipHan bool) {
+	terms := make([]string, 0, len(packet.Concordance))
+	for k := range packet.Concordance {
+		if skipHan && common.ContainsHan(k) {
+			continue
+		}
+		terms = append(terms, k)
+	}
+	for _, k := range terms {
+		packet.Rename(k, normalizer.Normalize(k))
 	}
-	log.Debug().Msg("PipeStemmingProcessor processes one data packet")
-
-	<-p.tokenBucket
 }
@@ -1,6 +1,7 @@
 package stemming
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,28 +50,40 @@ func TestEnApplyStemming(t *testing.T) {
 
 	p := &PipeStemmingProcessor{
 		tokenBucket: make(chan struct{}, 1),
+		language:    common.LanguageTypeEnglish,
+		normalizer:  Porter{},
 	}
 	inpacket := &common.ConcordanceWrapper{
 		Concordance: inConcordance,
 	}
-	output := make(common.ConcordanceChannel)
 
-	exit := make(chan struct{})
-
-	go func() {
-		outpacket := <-output
-		for k, v := range ouConcordance {
-			vv, ok := outpacket.Concordance[k]
-			assert.Equal(t, ok, true)
-			assert.Equal(t, vv, v)
-		}
-		exit <- struct{}{}
-	}()
-
-	p.applyEnglishStemming(inpacket, output)
-
-	<-exit
+	doc, err := p.Process(context.Background(), &common.Document{Concordance: inpacket})
+	assert.Empty(t, err)
+	outpacket := doc.Concordance
+	for k, v := range ouConcordance {
+		vv, ok := outpacket.Concordance[k]
+		assert.Equal(t, ok, true)
+		assert.Equal(t, vv, v)
+	}
 }
 
 func TestChApplyStemming(t *testing.T) {
 }
+
+func TestMixedApplyStemming(t *testing.T) {
+	p := NewPipeStemmingProcessor(common.LanguageTypeAuto, nil)
+	inpacket := common.NewConcordanceWrapper("1")
+	inpacket.Language = common.LanguageTypeMixed
+	for position, term := range []string{"财政", "abandoned", "2020", "abandon", "gdp"} {
+		inpacket.Add(term, uint32(position))
+	}
+
+	doc, err := p.Process(context.Background(), &common.Document{Concordance: inpacket})
+	assert.Empty(t, err)
+	assert.Equal(t, map[string][]uint32{
+		"财政":      {0},
+		"abandon": {1, 3},
+		"2020":    {2},
+		"gdp":     {4},
+	}, doc.Concordance.Positions)
+}
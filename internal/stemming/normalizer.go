@@ -0,0 +1,170 @@
+package stemming
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	stemmer "github.com/agonopol/go-stem"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+// 英文词条的归一化方式
+const (
+	// NormalizerPorter Porter词干提取
+	NormalizerPorter = "porter"
+	// NormalizerPorter2 Snowball English (Porter2) 词干提取
+	NormalizerPorter2 = "porter2"
+	// NormalizerLight 只还原名词复数的轻量词干提取
+	NormalizerLight = "light"
+	// NormalizerLemma 按词元词典还原词元
+	NormalizerLemma = "lemma"
+	// NormalizerNone 不做任何处理
+	NormalizerNone = "none"
+)
+
+var (
+	// ErrUnknownNormalizer 未知归一化方式错误
+	ErrUnknownNormalizer = errors.New("unknown normalizer")
+	// ErrBadLemmas 词元词典错误
+	ErrBadLemmas = errors.New("bad lemmas")
+)
+
+// Normalizer 将英文词条归一化为词干或词元, 需保证并发安全.
+type Normalizer interface {
+	Normalize(term string) string
+}
+
+// Chain 依次应用的一组归一化方式, 前一个的输出作为后一个的输入
+type Chain []Normalizer
+
+// Normalize 依次应用各个归一化方式.
+func (c Chain) Normalize(term string) string {
+	for _, n := range c {
+		term = n.Normalize(term)
+	}
+	return term
+}
+
+// NewChain 按配置新建归一化链, cfg为nil或未声明归一化方式时使用porter.
+func NewChain(cfg *conf.StemmingConfig) (Chain, error) {
+	names := []string{NormalizerPorter}
+	if cfg != nil && len(cfg.Normalizers) > 0 {
+		names = cfg.Normalizers
+	}
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case NormalizerPorter:
+			chain = append(chain, Porter{})
+		case NormalizerPorter2:
+			chain = append(chain, Porter2{})
+		case NormalizerLight:
+			chain = append(chain, Light{})
+		case NormalizerLemma:
+			{
+				var files []string
+				if cfg != nil {
+					files = cfg.Lemmas
+				}
+				lemmatizer, err := NewLemmatizer(files...)
+				if err != nil {
+					return nil, err
+				}
+				chain = append(chain, lemmatizer)
+			}
+		case NormalizerNone:
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNormalizer, name)
+		}
+	}
+	return chain, nil
+}
+
+/*
+	https://tartarus.org/martin/PorterStemmer/index.html
+*/
+
+// Porter Porter词干提取
+type Porter struct{}
+
+// Normalize 返回词条的Porter词干.
+func (Porter) Normalize(term string) string {
+	return string(stemmer.Stem([]byte(term)))
+}
+
+// Light 轻量词干提取, 只按以下规则还原名词复数 (Harman, 1991), 不改变词性:
+// -ies还原为-y (-eies/-aies除外), -es还原为-e (-aes/-ees/-oes除外), 去掉-s (-us/-ss除外).
+type Light struct{}
+
+// Normalize 返回词条的单数形式.
+func (Light) Normalize(term string) string {
+	n := len(term)
+	switch {
+	case n > 3 && strings.HasSuffix(term, "ies") && !strings.HasSuffix(term, "eies") && !strings.HasSuffix(term, "aies"):
+		return term[:n-3] + "y"
+	case n > 2 && strings.HasSuffix(term, "es") && !strings.HasSuffix(term, "aes") &&
+		!strings.HasSuffix(term, "ees") && !strings.HasSuffix(term, "oes"):
+		return term[:n-1]
+	case n > 1 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "us") && !strings.HasSuffix(term, "ss"):
+		return term[:n-1]
+	}
+	return term
+}
+
+// Lemmatizer 按词元词典还原词元, 不在词典中的词条保持不变
+type Lemmatizer struct {
+	lemmas map[string]string
+}
+
+// NewLemmatizer 加载词元词典, 每行为"词元 变形1 变形2 ...", 以#开头的行为注释.
+// 同一变形出现在多个词元之下时以先出现的为准.
+func NewLemmatizer(files ...string) (*Lemmatizer, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no lemma file", ErrBadLemmas)
+	}
+	l := &Lemmatizer{lemmas: make(map[string]string)}
+	for _, fn := range files {
+		if err := l.load(fn); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *Lemmatizer) load(fn string) error {
+	fr, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	scanner := bufio.NewScanner(fr)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(strings.ToLower(line))
+		if len(fields) < 2 {
+			return fmt.Errorf("%w: %s:%d has no inflected form", ErrBadLemmas, fn, lineno)
+		}
+		for _, form := range fields[1:] {
+			if _, ok := l.lemmas[form]; !ok {
+				l.lemmas[form] = fields[0]
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Normalize 返回词条的词元.
+func (l *Lemmatizer) Normalize(term string) string {
+	if lemma, ok := l.lemmas[term]; ok {
+		return lemma
+	}
+	return term
+}
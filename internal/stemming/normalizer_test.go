@@ -0,0 +1,207 @@
+package stemming
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+// loadFixture 读取词条与期望结果, input与output逐行对应; output为空时input每行为"词条 期望结果".
+func loadFixture(t testing.TB, input, output string) ([]string, []string) {
+	readLines := func(fn string) []string {
+		fr, err := os.Open(fn)
+		assert.Empty(t, err)
+		defer fr.Close()
+		lines := make([]string, 0)
+		scanner := bufio.NewScanner(fr)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		assert.Empty(t, scanner.Err())
+		return lines
+	}
+
+	if output != "" {
+		words, stems := readLines(input), readLines(output)
+		assert.Equal(t, len(words), len(stems))
+		return words, stems
+	}
+	lines := readLines(input)
+	words, stems := make([]string, len(lines)), make([]string, len(lines))
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		assert.Equal(t, 2, len(fields), line)
+		words[i], stems[i] = fields[0], fields[1]
+	}
+	return words, stems
+}
+
+// assertFixture 逐个比较归一化结果与期望结果.
+func assertFixture(t *testing.T, normalizer Normalizer, words, stems []string) {
+	var mismatches int
+	for i, word := range words {
+		if got := normalizer.Normalize(word); got != stems[i] {
+			if mismatches++; mismatches <= 10 {
+				t.Errorf("%s: expected %s, got %s", word, stems[i], got)
+			}
+		}
+	}
+	assert.Equal(t, 0, mismatches)
+}
+
+func TestPorterFixture(t *testing.T) {
+	words, stems := loadFixture(t, "fixtures/input.txt", "fixtures/output.txt")
+	assertFixture(t, Porter{}, words, stems)
+}
+
+func TestPorter2Fixture(t *testing.T) {
+	words, stems := loadFixture(t, "fixtures/porter2.txt", "")
+	assertFixture(t, Porter2{}, words, stems)
+}
+
+func TestLight(t *testing.T) {
+	for word, stem := range map[string]string{
+		"policies": "policy",
+		"agencies": "agency",
+		"studies":  "study",
+		"taxes":    "taxe",
+		"shoes":    "shoe",
+		"trees":    "tree",
+		"budgets":  "budget",
+		"status":   "status",
+		"progress": "progress",
+		"s":        "s",
+		"reform":   "reform",
+	} {
+		assert.Equal(t, stem, Light{}.Normalize(word), word)
+	}
+}
+
+func TestNewChain(t *testing.T) {
+	chain, err := NewChain(nil)
+	assert.Empty(t, err)
+	assert.Equal(t, Chain{Porter{}}, chain)
+
+	// 先还原词元, 再提取词干
+	chain, err = NewChain(&conf.StemmingConfig{
+		Normalizers: []string{NormalizerLemma, NormalizerPorter2},
+		Lemmas:      []string{"fixtures/lemmas.txt"},
+	})
+	assert.Empty(t, err)
+	for word, stem := range map[string]string{
+		"went":     "go",
+		"children": "child",
+		"mice":     "mous",
+		"women":    "woman",
+		"knights":  "knight",
+	} {
+		assert.Equal(t, stem, chain.Normalize(word), word)
+	}
+
+	chain, err = NewChain(&conf.StemmingConfig{Normalizers: []string{NormalizerNone}})
+	assert.Empty(t, err)
+	assert.Equal(t, "knights", chain.Normalize("knights"))
+
+	_, err = NewChain(&conf.StemmingConfig{Normalizers: []string{"lancaster"}})
+	assert.True(t, errors.Is(err, ErrUnknownNormalizer))
+	_, err = NewChain(&conf.StemmingConfig{Normalizers: []string{NormalizerLemma}})
+	assert.True(t, errors.Is(err, ErrBadLemmas))
+	_, err = NewChain(&conf.StemmingConfig{Normalizers: []string{NormalizerLemma}, Lemmas: []string{"fixtures/input.txt"}})
+	assert.True(t, errors.Is(err, ErrBadLemmas))
+}
+
+func TestQueryConsistency(t *testing.T) {
+	chain, err := NewChain(&conf.StemmingConfig{Normalizers: []string{NormalizerLight}})
+	assert.Empty(t, err)
+	p := NewPipeStemmingProcessor(common.LanguageTypeEnglish, chain)
+
+	inpacket := common.NewConcordanceWrapper("1")
+	for position, term := range []string{"tax", "policies", "taxes", "policy"} {
+		inpacket.Add(term, uint32(position))
+	}
+	doc, err := p.Process(context.Background(), &common.Document{Concordance: inpacket})
+	assert.Empty(t, err)
+	assert.Equal(t, map[string][]uint32{"tax": {0}, "policy": {1, 3}, "taxe": {2}}, doc.Concordance.Positions)
+
+	// 查询语句与文档使用同一条归一化链
+	query := common.NewConcordanceWrapper("")
+	query.Add("policies", 0)
+	p.QueryApplyStemming(common.LanguageTypeEnglish, query)
+	assert.Equal(t, map[string][]uint32{"policy": {0}}, query.Positions)
+}
+
+// comparedChains 参与比较的归一化链
+var comparedChains = []struct {
+	name string
+	cfg  *conf.StemmingConfig
+}{
+	{"none", &conf.StemmingConfig{Normalizers: []string{NormalizerNone}}},
+	{"light", &conf.StemmingConfig{Normalizers: []string{NormalizerLight}}},
+	{"lemma", &conf.StemmingConfig{Normalizers: []string{NormalizerLemma}, Lemmas: []string{"fixtures/lemmas.txt"}}},
+	{"porter", &conf.StemmingConfig{Normalizers: []string{NormalizerPorter}}},
+	{"porter2", &conf.StemmingConfig{Normalizers: []string{NormalizerPorter2}}},
+	{"lemma+porter2", &conf.StemmingConfig{Normalizers: []string{NormalizerLemma, NormalizerPorter2}, Lemmas: []string{"fixtures/lemmas.txt"}}},
+}
+
+// TestCompareNormalizers 在Porter词表上比较各条归一化链:
+// 归并之后的词条数越少说明归并得越激进, 与Porter一致的比例反映与现有索引的兼容程度.
+// 用 go test -v -run TestCompareNormalizers 查看比较结果.
+func TestCompareNormalizers(t *testing.T) {
+	words, stems := loadFixture(t, "fixtures/input.txt", "fixtures/output.txt")
+
+	distinct := make(map[string]int, len(comparedChains))
+	for _, c := range comparedChains {
+		chain, err := NewChain(c.cfg)
+		assert.Empty(t, err)
+
+		vocabulary := make(map[string]struct{})
+		var agreed int
+		for i, word := range words {
+			stem := chain.Normalize(word)
+			vocabulary[stem] = struct{}{}
+			if stem == stems[i] {
+				agreed++
+			}
+			// 归一化结果非空, 且不会比原词长
+			assert.NotEmpty(t, stem, word)
+			if c.name != "lemma" && c.name != "lemma+porter2" {
+				assert.True(t, len(stem) <= len(word), "%s: %s -> %s", c.name, word, stem)
+			}
+		}
+		distinct[c.name] = len(vocabulary)
+		t.Logf("%-14s terms=%d distinct=%d (%.1f%%) agree_with_porter=%.1f%%", c.name, len(words),
+			len(vocabulary), 100*float64(len(vocabulary))/float64(len(words)), 100*float64(agreed)/float64(len(words)))
+	}
+
+	assert.Equal(t, len(words), distinct["none"])
+	assert.True(t, distinct["light"] < distinct["none"])
+	assert.True(t, distinct["lemma"] < distinct["none"])
+	assert.True(t, distinct["porter"] < distinct["light"])
+	assert.True(t, distinct["porter2"] < distinct["light"])
+	assert.True(t, distinct["lemma+porter2"] <= distinct["porter2"])
+}
+
+func BenchmarkNormalizers(b *testing.B) {
+	words, _ := loadFixture(b, "fixtures/input.txt", "fixtures/output.txt")
+	for _, c := range comparedChains {
+		chain, err := NewChain(c.cfg)
+		assert.Empty(b, err)
+		b.Run(c.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, word := range words {
+					chain.Normalize(word)
+				}
+			}
+		})
+	}
+}
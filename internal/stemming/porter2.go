@@ -0,0 +1,348 @@
+package stemming
+
+import (
+	"strings"
+)
+
+/*
+	https://snowballstem.org/algorithms/english/stemmer.html
+*/
+
+// Porter2 Snowball English (Porter2) 词干提取
+type Porter2 struct{}
+
+// 不经过词干提取, 直接替换或保持不变的特殊词
+var porter2Exceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// 经过step 1a之后保持不变的词
+var porter2Invariants = map[string]struct{}{
+	"inning":  {},
+	"outing":  {},
+	"canning": {},
+	"herring": {},
+	"earring": {},
+	"proceed": {},
+	"exceed":  {},
+	"succeed": {},
+}
+
+// porter2Word 词干提取过程中的词, R1/R2为区域的起始位置
+type porter2Word struct {
+	b      []byte
+	r1, r2 int
+}
+
+// Normalize 返回词条的Porter2词干.
+func (Porter2) Normalize(term string) string {
+	if stem, ok := porter2Exceptions[term]; ok {
+		return stem
+	}
+	if len(term) < 3 {
+		return term
+	}
+
+	w := &porter2Word{b: []byte(strings.TrimPrefix(term, "'"))}
+	w.prelude()
+	w.markRegions()
+	w.step0()
+	w.step1a()
+	if _, ok := porter2Invariants[string(w.b)]; !ok {
+		w.step1b()
+		w.step1c()
+		w.step2()
+		w.step3()
+		w.step4()
+		w.step5()
+	}
+	for i, c := range w.b {
+		if c == 'Y' {
+			w.b[i] = 'y'
+		}
+	}
+	return string(w.b)
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// prelude 将词首的y以及元音之后的y标记为辅音Y.
+func (w *porter2Word) prelude() {
+	for i, c := range w.b {
+		if c == 'y' && (i == 0 || isVowel(w.b[i-1])) {
+			w.b[i] = 'Y'
+		}
+	}
+}
+
+// markRegions 计算R1与R2. R1为第一个"元音+辅音"之后的部分, R2为R1中第一个"元音+辅音"之后的部分;
+// 以gener/commun/arsen开头的词, R1为其后的部分.
+func (w *porter2Word) markRegions() {
+	w.r1 = len(w.b)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if len(w.b) >= len(prefix) && string(w.b[:len(prefix)]) == prefix {
+			w.r1 = len(prefix)
+			break
+		}
+	}
+	if w.r1 == len(w.b) {
+		w.r1 = w.region(0)
+	}
+	w.r2 = w.region(w.r1)
+}
+
+// region 返回从from开始第一个"元音+辅音"之后的位置, 不存在时返回词长.
+func (w *porter2Word) region(from int) int {
+	for i := from + 1; i < len(w.b); i++ {
+		if !isVowel(w.b[i]) && isVowel(w.b[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w.b)
+}
+
+func (w *porter2Word) hasSuffix(suffix string) bool {
+	// 比较时不复制词的内容
+	return len(w.b) >= len(suffix) && string(w.b[len(w.b)-len(suffix):]) == suffix
+}
+
+// longestSuffix 返回词以之结尾的最长后缀, 不存在时返回空字符串.
+func (w *porter2Word) longestSuffix(suffixes ...string) string {
+	var longest string
+	for _, suffix := range suffixes {
+		if len(suffix) > len(longest) && w.hasSuffix(suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+func (w *porter2Word) replace(suffix, with string) {
+	w.b = append(w.b[:len(w.b)-len(suffix)], with...)
+}
+
+// inR1 后缀是否位于R1中.
+func (w *porter2Word) inR1(suffix string) bool {
+	return len(w.b)-len(suffix) >= w.r1
+}
+
+// inR2 后缀是否位于R2中.
+func (w *porter2Word) inR2(suffix string) bool {
+	return len(w.b)-len(suffix) >= w.r2
+}
+
+// containsVowel 词的前n个字母中是否含有元音.
+func (w *porter2Word) containsVowel(n int) bool {
+	for _, c := range w.b[:n] {
+		if isVowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortSyllable 词的前n个字母是否以短音节结尾: 非元音+元音+非w/x/Y的非元音, 或者词首的元音+非元音.
+func (w *porter2Word) shortSyllable(n int) bool {
+	if n == 2 {
+		return isVowel(w.b[0]) && !isVowel(w.b[1])
+	}
+	if n < 3 {
+		return false
+	}
+	c := w.b[n-1]
+	return !isVowel(w.b[n-3]) && isVowel(w.b[n-2]) && !isVowel(c) && c != 'w' && c != 'x' && c != 'Y'
+}
+
+// step0 去掉所有格'/'s/'s'.
+func (w *porter2Word) step0() {
+	if suffix := w.longestSuffix("'", "'s", "'s'"); suffix != "" {
+		w.replace(suffix, "")
+	}
+}
+
+// step1a 还原复数.
+func (w *porter2Word) step1a() {
+	switch suffix := w.longestSuffix("sses", "ied", "ies", "s", "us", "ss"); suffix {
+	case "sses":
+		w.replace(suffix, "ss")
+	case "ied", "ies":
+		if len(w.b) > len(suffix)+1 {
+			w.replace(suffix, "i")
+		} else {
+			w.replace(suffix, "ie")
+		}
+	case "s":
+		// 紧邻s的字母之前需含有元音
+		if len(w.b) >= 2 && w.containsVowel(len(w.b)-2) {
+			w.replace(suffix, "")
+		}
+	}
+}
+
+// step1b 去掉-ed/-ing等后缀.
+func (w *porter2Word) step1b() {
+	switch suffix := w.longestSuffix("eed", "eedly", "ed", "edly", "ing", "ingly"); suffix {
+	case "eed", "eedly":
+		if w.inR1(suffix) {
+			w.replace(suffix, "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		if !w.containsVowel(len(w.b) - len(suffix)) {
+			return
+		}
+		w.replace(suffix, "")
+		switch {
+		case w.hasSuffix("at") || w.hasSuffix("bl") || w.hasSuffix("iz"):
+			w.b = append(w.b, 'e')
+		case w.longestSuffix("bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt") != "":
+			w.b = w.b[:len(w.b)-1]
+		case w.r1 == len(w.b) && w.shortSyllable(len(w.b)):
+			// 短词
+			w.b = append(w.b, 'e')
+		}
+	}
+}
+
+// step1c 将非词首辅音之后的y/Y替换为i.
+func (w *porter2Word) step1c() {
+	n := len(w.b)
+	if n > 2 && (w.b[n-1] == 'y' || w.b[n-1] == 'Y') && !isVowel(w.b[n-2]) {
+		w.b[n-1] = 'i'
+	}
+}
+
+var porter2Step2 = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"izati":   "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var porter2Step2Suffixes = suffixesOf(porter2Step2)
+
+// step2 将R1中的派生后缀替换为较短的形式.
+func (w *porter2Word) step2() {
+	suffix := w.longestSuffix(porter2Step2Suffixes...)
+	if suffix == "" || !w.inR1(suffix) {
+		return
+	}
+	switch suffix {
+	case "ogi":
+		if n := len(w.b) - len(suffix); n < 1 || w.b[n-1] != 'l' {
+			return
+		}
+	case "li":
+		if n := len(w.b) - len(suffix); n < 1 || strings.IndexByte("cdeghkmnrt", w.b[n-1]) < 0 {
+			return
+		}
+	}
+	w.replace(suffix, porter2Step2[suffix])
+}
+
+var porter2Step3 = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var porter2Step3Suffixes = suffixesOf(porter2Step3)
+
+// step3 将R1中的派生后缀替换为较短的形式, -ative需位于R2中.
+func (w *porter2Word) step3() {
+	suffix := w.longestSuffix(porter2Step3Suffixes...)
+	if suffix == "" || !w.inR1(suffix) || (suffix == "ative" && !w.inR2(suffix)) {
+		return
+	}
+	w.replace(suffix, porter2Step3[suffix])
+}
+
+var porter2Step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement",
+	"ment", "ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+// step4 去掉R2中的派生后缀, -ion需紧跟在s或t之后.
+func (w *porter2Word) step4() {
+	suffix := w.longestSuffix(porter2Step4Suffixes...)
+	if suffix == "" || !w.inR2(suffix) {
+		return
+	}
+	if suffix == "ion" {
+		if n := len(w.b) - len(suffix); n < 1 || (w.b[n-1] != 's' && w.b[n-1] != 't') {
+			return
+		}
+	}
+	w.replace(suffix, "")
+}
+
+// step5 去掉词尾的e以及ll中的一个l.
+func (w *porter2Word) step5() {
+	n := len(w.b)
+	switch {
+	case w.hasSuffix("e"):
+		if w.inR2("e") || (w.inR1("e") && !w.shortSyllable(n-1)) {
+			w.replace("e", "")
+		}
+	case w.hasSuffix("l"):
+		if w.inR2("l") && n >= 2 && w.b[n-2] == 'l' {
+			w.replace("l", "")
+		}
+	}
+}
+
+func suffixesOf(rules map[string]string) []string {
+	suffixes := make([]string, 0, len(rules))
+	for suffix := range rules {
+		suffixes = append(suffixes, suffix)
+	}
+	return suffixes
+}
@@ -0,0 +1,352 @@
+package stopword
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+// 停词表名
+const (
+	// ListEnglish 英文停词表
+	ListEnglish = "english"
+	// ListChinese 中文停词表
+	ListChinese = "chinese"
+	// ListSpecial 特殊停词表, 对所有语种生效
+	ListSpecial = "special"
+)
+
+var (
+	// ErrUnknownList 未知停词表错误
+	ErrUnknownList = errors.New("unknown stopword list")
+	// ErrNoEditableLists 未配置停词表的修改记录错误
+	ErrNoEditableLists = errors.New("no editable stopword lists")
+	// ErrBadStopWord 停词错误
+	ErrBadStopWord = errors.New("bad stopword")
+)
+
+// listNames 停词表名, 按版本计算的顺序排列
+var listNames = []string{ListEnglish, ListChinese, ListSpecial}
+
+// Set 一组停词表, 构建之后不再修改
+type Set struct {
+	// 各停词表内容的哈希值
+	Version string
+	lists   map[string]map[string]struct{}
+}
+
+// Words 返回停词表中按字典序排列的停词.
+func (s *Set) Words(list string) ([]string, error) {
+	words, ok := s.lists[list]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownList, list)
+	}
+	return sortedWords(words), nil
+}
+
+// Len 返回停词表中的停词个数.
+func (s *Set) Len(list string) int {
+	return len(s.lists[list])
+}
+
+// sortedWords 返回按字典序排列的停词.
+func sortedWords(words map[string]struct{}) []string {
+	sorted := make([]string, 0, len(words))
+	for word := range words {
+		sorted = append(sorted, word)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// listEdits 通过管理接口对一个停词表做的修改
+type listEdits struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// edits 停词表的修改记录, 叠加在配置的停词表文件之上
+type edits struct {
+	English *listEdits `json:"english"`
+	Chinese *listEdits `json:"chinese"`
+	Special *listEdits `json:"special"`
+}
+
+func (e *edits) list(name string) *listEdits {
+	var l **listEdits
+	switch name {
+	case ListEnglish:
+		l = &e.English
+	case ListChinese:
+		l = &e.Chinese
+	default:
+		l = &e.Special
+	}
+	if *l == nil {
+		*l = &listEdits{}
+	}
+	return *l
+}
+
+// Lists 语料集合的停词表, 由配置的停词表文件以及通过管理接口做的修改组成,
+// 未配置文件的停词表使用内置的EnStopWords/ChStopWords/SpStopWords.
+// 文档按当前的停词表处理; 每个索引快照记录其中的文档去停词时使用的停词表, 查询语句按所访问快照的停词表处理,
+// 修改停词表之后, 查询在下一次构造快照时才使用新的停词表, 翻页访问的旧快照仍使用原来的停词表.
+type Lists struct {
+	cfg *conf.StopWordsConfig
+
+	// 串行化停词表的修改
+	mu      sync.Mutex
+	base    map[string]map[string]struct{}
+	current atomic.Value
+	// 构建过的各个版本的停词表, 用于按文档记录的版本找回处理文档时使用的停词表
+	sets map[string]*Set
+}
+
+// NewLists 加载停词表, cfg为nil时只使用内置的停词表.
+func NewLists(cfg *conf.StopWordsConfig) (*Lists, error) {
+	if cfg == nil {
+		cfg = &conf.StopWordsConfig{}
+	}
+	l := &Lists{
+		cfg:  cfg,
+		base: make(map[string]map[string]struct{}, len(listNames)),
+		sets: make(map[string]*Set),
+	}
+	builtins := map[string]map[string]struct{}{
+		ListEnglish: EnStopWords,
+		ListChinese: ChStopWords,
+		ListSpecial: SpStopWords,
+	}
+	for _, name := range listNames {
+		files := l.files(name)
+		if len(files) == 0 {
+			l.base[name] = builtins[name]
+			continue
+		}
+		words := make(map[string]struct{})
+		for _, fn := range files {
+			if err := readWords(fn, words); err != nil {
+				return nil, err
+			}
+		}
+		l.base[name] = words
+	}
+
+	e, err := l.readEdits()
+	if err != nil {
+		return nil, err
+	}
+	set := l.build(e)
+	l.current.Store(set)
+	l.sets[set.Version] = set
+	log.Info().Msgf("load stopword lists, version=%s", set.Version)
+	return l, nil
+}
+
+// files 返回停词表配置的文件.
+func (l *Lists) files(name string) []string {
+	switch name {
+	case ListEnglish:
+		return l.cfg.English
+	case ListChinese:
+		return l.cfg.Chinese
+	default:
+		return l.cfg.Special
+	}
+}
+
+// Current 返回处理文档时使用的停词表.
+func (l *Lists) Current() *Set {
+	return l.current.Load().(*Set)
+}
+
+// Lookup 按版本查找构建过的停词表.
+func (l *Lists) Lookup(version string) (*Set, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set, ok := l.sets[version]
+	return set, ok
+}
+
+// Add 向停词表添加停词, 之后处理的文档立即生效.
+func (l *Lists) Add(list string, words []string) (*Set, error) {
+	return l.edit(list, words, func(e *listEdits, base map[string]struct{}, word string) {
+		e.Removed = removeWord(e.Removed, word)
+		if _, ok := base[word]; !ok {
+			e.Added = addWord(e.Added, word)
+		}
+	})
+}
+
+// Remove 从停词表删除停词, 配置的停词表文件中的停词同样可以删除, 之后处理的文档立即生效.
+func (l *Lists) Remove(list string, words []string) (*Set, error) {
+	return l.edit(list, words, func(e *listEdits, base map[string]struct{}, word string) {
+		e.Added = removeWord(e.Added, word)
+		if _, ok := base[word]; ok {
+			e.Removed = addWord(e.Removed, word)
+		}
+	})
+}
+
+// edit 修改停词表, 写入修改记录之后替换当前的停词表.
+func (l *Lists) edit(list string, words []string, fn func(e *listEdits, base map[string]struct{}, word string)) (*Set, error) {
+	base, ok := l.base[list]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownList, list)
+	}
+	if l.cfg.Editable == "" {
+		return nil, ErrNoEditableLists
+	}
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		if word == "" || strings.TrimSpace(word) != word {
+			return nil, fmt.Errorf("%w: %q", ErrBadStopWord, word)
+		}
+		if list == ListEnglish {
+			// 英文词条在分词时已转为小写
+			word = strings.ToLower(word)
+		}
+		normalized[i] = word
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, err := l.readEdits()
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range normalized {
+		fn(e.list(list), base, word)
+	}
+	if err = l.writeEdits(e); err != nil {
+		return nil, err
+	}
+	set := l.build(e)
+	if known, ok := l.sets[set.Version]; ok {
+		// 撤销修改之后恢复原来的版本, 沿用原来的停词表
+		set = known
+	}
+	l.current.Store(set)
+	l.sets[set.Version] = set
+	log.Info().Msgf("update stopword list %s, version=%s", list, set.Version)
+	return set, nil
+}
+
+// build 将修改记录叠加到停词表文件上, 构建新的一组停词表.
+func (l *Lists) build(e *edits) *Set {
+	set := &Set{lists: make(map[string]map[string]struct{}, len(listNames))}
+	h := fnv.New64a()
+	for _, name := range listNames {
+		base, edited := l.base[name], e.list(name)
+		words := base
+		if len(edited.Added) > 0 || len(edited.Removed) > 0 {
+			words = make(map[string]struct{}, len(base)+len(edited.Added))
+			for word := range base {
+				words[word] = struct{}{}
+			}
+			for _, word := range edited.Added {
+				words[word] = struct{}{}
+			}
+			for _, word := range edited.Removed {
+				delete(words, word)
+			}
+		}
+		set.lists[name] = words
+		fmt.Fprintf(h, "%s\n", name)
+		for _, word := range sortedWords(words) {
+			fmt.Fprintf(h, "%s\n", word)
+		}
+	}
+	set.Version = strconv.FormatUint(h.Sum64(), 16)
+	return set
+}
+
+// readEdits 读取修改记录, 未配置或文件不存在时返回空记录.
+func (l *Lists) readEdits() (*edits, error) {
+	e := &edits{}
+	if l.cfg.Editable == "" {
+		return e, nil
+	}
+	data, err := ioutil.ReadFile(l.cfg.Editable)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return e, nil
+		}
+		return nil, err
+	}
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	if err = json.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("bad stopword edits %s: %w", l.cfg.Editable, err)
+	}
+	return e, nil
+}
+
+// writeEdits 写入修改记录, 先写临时文件再重命名, 避免留下写了一半的记录.
+func (l *Lists) writeEdits(e *edits) error {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.MarshalIndent(e, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(l.cfg.Editable), 0755); err != nil {
+		return err
+	}
+	tmp := l.cfg.Editable + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.cfg.Editable)
+}
+
+// readWords 读取停词表文件, 每行一个停词, 忽略空行.
+func readWords(fn string, words map[string]struct{}) error {
+	fr, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	scanner := bufio.NewScanner(fr)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words[word] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// addWord 向升序排列的停词列表中插入停词.
+func addWord(words []string, word string) []string {
+	i := sort.SearchStrings(words, word)
+	if i < len(words) && words[i] == word {
+		return words
+	}
+	words = append(words, "")
+	copy(words[i+1:], words[i:])
+	words[i] = word
+	return words
+}
+
+// removeWord 从升序排列的停词列表中删除停词.
+func removeWord(words []string, word string) []string {
+	i := sort.SearchStrings(words, word)
+	if i < len(words) && words[i] == word {
+		return append(words[:i], words[i+1:]...)
+	}
+	return words
+}
@@ -0,0 +1,109 @@
+package stopword
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func newTestWrapper(words ...string) *common.ConcordanceWrapper {
+	wrapper := common.NewConcordanceWrapper("1")
+	for i, word := range words {
+		wrapper.Add(word, uint32(i))
+	}
+	return wrapper
+}
+
+func TestLists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stopwords")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	finance := filepath.Join(dir, "finance.txt")
+	assert.Empty(t, ioutil.WriteFile(finance, []byte("通知\n\n有关\n"), 0644))
+	cfg := &conf.StopWordsConfig{
+		English:  []string{"raw_stopword_txt/en_sws.txt"},
+		Chinese:  []string{"raw_stopword_txt/ch_sws.txt", finance},
+		Editable: filepath.Join(dir, "edits", "stopwords.json"),
+	}
+
+	lists, err := NewLists(cfg)
+	assert.Empty(t, err)
+	// 停词表文件与内置的停词表一致, 多个文件取并集; 未配置文件的停词表使用内置的停词表
+	set := lists.Current()
+	assert.Equal(t, len(EnStopWords), set.Len(ListEnglish))
+	assert.Equal(t, len(ChStopWords)+1, set.Len(ListChinese))
+	assert.Equal(t, len(SpStopWords), set.Len(ListSpecial))
+	v1 := set.Version
+	pinned := set
+
+	p := NewPipeStopWordsProcessor(common.LanguageTypeChinsese, lists)
+	process := func(words ...string) map[string][]uint32 {
+		doc, err := p.Process(context.Background(), &common.Document{Concordance: newTestWrapper(words...)})
+		assert.Empty(t, err)
+		return doc.Concordance.Positions
+	}
+	query := func(set *Set, words ...string) map[string][]uint32 {
+		wrapper := newTestWrapper(words...)
+		p.QueryRemoveStopWords(set, common.LanguageTypeChinsese, wrapper)
+		return wrapper.Positions
+	}
+	assert.Equal(t, map[string][]uint32{"财政": {2}}, process("关于", "通知", "财政"))
+	// 文档记录处理时使用的停词表版本, 可以按版本找回停词表
+	doc, err := p.Process(context.Background(), &common.Document{Concordance: newTestWrapper("财政")})
+	assert.Empty(t, err)
+	assert.Equal(t, v1, doc.Concordance.StopWordsVersion)
+	found, ok := lists.Lookup(v1)
+	assert.True(t, ok)
+	assert.Equal(t, pinned, found)
+
+	// 修改之后文档立即使用新的停词表, 查询语句继续使用快照记录的原来的停词表
+	set, err = lists.Add(ListChinese, []string{"财政"})
+	assert.Empty(t, err)
+	assert.NotEqual(t, v1, set.Version)
+	set, err = lists.Remove(ListChinese, []string{"通知"})
+	assert.Empty(t, err)
+	v2 := set.Version
+	assert.Equal(t, map[string][]uint32{"通知": {1}}, process("关于", "通知", "财政"))
+	assert.Equal(t, map[string][]uint32{"财政": {2}}, query(pinned, "关于", "通知", "财政"))
+	assert.Equal(t, map[string][]uint32{"通知": {1}}, query(lists.Current(), "关于", "通知", "财政"))
+	// 未记录停词表时使用当前的停词表
+	assert.Equal(t, map[string][]uint32{"通知": {1}}, query(nil, "关于", "通知", "财政"))
+
+	// 修改记录在重启之后仍然有效, 撤销全部修改之后恢复原来的版本
+	lists, err = NewLists(cfg)
+	assert.Empty(t, err)
+	assert.Equal(t, v2, lists.Current().Version)
+	_, err = lists.Add(ListChinese, []string{"通知"})
+	assert.Empty(t, err)
+	set, err = lists.Remove(ListChinese, []string{"财政"})
+	assert.Empty(t, err)
+	assert.Equal(t, v1, set.Version)
+
+	// 英文停词统一转为小写
+	set, err = lists.Add(ListEnglish, []string{"Fiscal"})
+	assert.Empty(t, err)
+	words, err := set.Words(ListEnglish)
+	assert.Empty(t, err)
+	assert.Contains(t, words, "fiscal")
+
+	_, err = lists.Add("klingon", []string{"a"})
+	assert.True(t, errors.Is(err, ErrUnknownList))
+	_, err = lists.Current().Words("klingon")
+	assert.True(t, errors.Is(err, ErrUnknownList))
+	_, err = lists.Add(ListEnglish, []string{" a"})
+	assert.True(t, errors.Is(err, ErrBadStopWord))
+	lists, err = NewLists(nil)
+	assert.Empty(t, err)
+	_, err = lists.Remove(ListEnglish, []string{"a"})
+	assert.Equal(t, ErrNoEditableLists, err)
+	_, err = NewLists(&conf.StopWordsConfig{English: []string{filepath.Join(dir, "not-exist")}})
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,7 +1,7 @@
 package stopword
 
 import (
-	"sync"
+	"context"
 
 	"github.com/rs/zerolog/log"
 
@@ -12,115 +12,75 @@ import (
 type PipeStopWordsProcessor struct {
 	tokenBucket chan struct{}
 	language    common.LanguageType
+	lists       *Lists
 }
 
-// NewPipeStopWordsProcessor 新建停词器.
-func NewPipeStopWordsProcessor(language common.LanguageType) *PipeStopWordsProcessor {
+// NewPipeStopWordsProcessor 新建停词器, 各停词器可以共用同一组停词表, lists为nil时使用内置的停词表.
+func NewPipeStopWordsProcessor(language common.LanguageType, lists *Lists) *PipeStopWordsProcessor {
+	if lists == nil {
+		lists, _ = NewLists(nil) // nolint
+	}
 	log.Info().Msg("load PipeStopWordsProcessor plugin")
 	return &PipeStopWordsProcessor{
 		tokenBucket: make(chan struct{}, 20),
 		language:    language,
+		lists:       lists,
 	}
 }
 
-// RemoveStopWords 移除concordance中的中/英文停词+特殊停词.
-func (p *PipeStopWordsProcessor) RemoveStopWords(pGroup *sync.WaitGroup, input common.ConcordanceChannel, output common.ConcordanceChannel) {
-	pGroup.Add(1)
-LOOP_LABEL:
-	for {
-		select {
-		case packet, ok := <-input:
-			{
-				if !ok {
-					close(output)
-					break LOOP_LABEL
-				}
-				switch p.language {
-				case common.LanguageTypeEnglish:
-					{
-						go p.removeEnglishStopWords(packet, output)
-					}
-				case common.LanguageTypeChinsese:
-					{
-						go p.removeChineseStopWords(packet, output)
-					}
-				default:
-					{
-
-					}
-				}
-			}
-		default:
-			{
-
-			}
-		}
+// Process 按当前的停词表移除concordance中的中/英文停词+特殊停词, 并记录所用停词表的版本 (并发安全).
+// 语种为auto时按分词器检测出的语种移除.
+func (p *PipeStopWordsProcessor) Process(ctx context.Context, doc *common.Document) (*common.Document, error) {
+	language := p.language
+	if language == common.LanguageTypeAuto {
+		language = doc.Concordance.Language
 	}
-	pGroup.Done()
-	log.Info().Msg("unload PipeStopWordsProcessor plugin")
+	set := p.lists.Current()
+	removeLanguageStopWords(set, language, doc.Concordance)
+	doc.Concordance.StopWordsVersion = set.Version
+	log.Debug().Msg("PipeStopWordsProcessor processes one data packet")
+	return doc, nil
 }
 
-// QueryRemoveStopWords 移除查询语句中的停词.
-func (p *PipeStopWordsProcessor) QueryRemoveStopWords(language common.LanguageType, concordance map[string]uint64) {
+// QueryRemoveStopWords 按set移除查询语句中的停词, set应为所访问的索引快照构造时的停词表, 为nil时使用当前的停词表.
+func (p *PipeStopWordsProcessor) QueryRemoveStopWords(set *Set, language common.LanguageType, query *common.ConcordanceWrapper) {
 	p.tokenBucket <- struct{}{}
 
-	if language == common.LanguageTypeEnglish {
-		for k := range concordance {
-			if _, ok := EnStopWords[k]; ok {
-				delete(concordance, k)
-			} else if _, ok := SpStopWords[k]; ok {
-				delete(concordance, k)
-			}
-		}
-	} else if language == common.LanguageTypeChinsese {
-		for k := range concordance {
-			if _, ok := ChStopWords[k]; ok {
-				delete(concordance, k)
-			} else if _, ok := SpStopWords[k]; ok {
-				delete(concordance, k)
-			}
-		}
+	if set == nil {
+		set = p.lists.Current()
 	}
+	removeLanguageStopWords(set, language, query)
 
 	<-p.tokenBucket
 }
 
-func (p *PipeStopWordsProcessor) removeEnglishStopWords(packet *common.ConcordanceWrapper, output common.ConcordanceChannel) {
-	p.tokenBucket <- struct{}{}
-
-	for k := range packet.Concordance {
-		if _, ok := EnStopWords[k]; ok {
-			delete(packet.Concordance, k)
-		} else if _, ok := SpStopWords[k]; ok {
-			delete(packet.Concordance, k)
+// removeLanguageStopWords 按语种移除停词, 中英文混合时同时移除中/英文停词.
+func removeLanguageStopWords(set *Set, language common.LanguageType, packet *common.ConcordanceWrapper) {
+	switch language {
+	case common.LanguageTypeEnglish:
+		{
+			removeStopWords(packet, set.lists[ListSpecial], set.lists[ListEnglish])
+		}
+	case common.LanguageTypeChinsese:
+		{
+			removeStopWords(packet, set.lists[ListSpecial], set.lists[ListChinese])
+		}
+	case common.LanguageTypeMixed:
+		{
+			removeStopWords(packet, set.lists[ListSpecial], set.lists[ListEnglish], set.lists[ListChinese])
 		}
 	}
-
-	output <- &common.ConcordanceWrapper{
-		DocID:       packet.DocID,
-		Concordance: packet.Concordance,
-	}
-	log.Debug().Msg("PipeStopWordsProcessor processes one data packet")
-
-	<-p.tokenBucket
 }
 
-func (p *PipeStopWordsProcessor) removeChineseStopWords(packet *common.ConcordanceWrapper, output common.ConcordanceChannel) {
-	p.tokenBucket <- struct{}{}
-
+// removeStopWords 移除停词, 词条的位置信息会一并移除.
+// 其余词条保留原始位置, 因此短语匹配时停词留下的空位在文档与查询中是一致的.
+func removeStopWords(packet *common.ConcordanceWrapper, stopWords ...map[string]struct{}) {
 	for k := range packet.Concordance {
-		if _, ok := ChStopWords[k]; ok {
-			delete(packet.Concordance, k)
-		} else if _, ok := SpStopWords[k]; ok {
-			delete(packet.Concordance, k)
+		for _, words := range stopWords {
+			if _, ok := words[k]; ok {
+				packet.Remove(k)
+				break
+			}
 		}
 	}
-
-	output <- &common.ConcordanceWrapper{
-		DocID:       packet.DocID,
-		Concordance: packet.Concordance,
-	}
-	log.Debug().Msg("PipeStopWordsProcessor processes one data packet")
-
-	<-p.tokenBucket
 }
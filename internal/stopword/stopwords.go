@@ -1,7 +1,7 @@
 package stopword
 
 var (
-	// EnStopWords 英文停词表
+	// EnStopWords 内置的英文停词表, 未配置英文停词表文件时使用
 	EnStopWords = map[string]struct{}{
 		"a":             struct{}{},
 		"i":             struct{}{},
@@ -566,7 +566,7 @@ var (
 		"unfortunately": struct{}{},
 	}
 
-	// ChStopWords 中文停词表
+	// ChStopWords 内置的中文停词表, 未配置中文停词表文件时使用
 	ChStopWords = map[string]struct{}{
 		"一":       struct{}{},
 		"与":       struct{}{},
@@ -2083,7 +2083,7 @@ var (
 		"打开天窗说亮话": struct{}{},
 	}
 
-	// SpStopWords 特殊词停词表
+	// SpStopWords 内置的特殊词停词表, 未配置特殊停词表文件时使用
 	SpStopWords = map[string]struct{}{
 		"?":                   struct{}{},
 		"“":                   struct{}{},
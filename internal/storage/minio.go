@@ -124,7 +124,7 @@ func (p *S3Storage) Put(ctx context.Context, file *common.File) (string, error)
 		n, err := p.cli.FPutObject(ctx, p.cfg.Bucket, rPath, lPath, minio.PutObjectOptions{})
 		if err != nil {
 			log.Warn().Err(err).Msgf("cannot write local tmp file to s3, retry=%d, object=%s, file=%s, file size=%d, uploaded=%d",
-				retry, rPath, lPath, utils.FileSize(lPath), n)
+				retry, rPath, lPath, utils.FileSize(lPath), n.Size)
 			retry++
 			return err
 		}
@@ -0,0 +1,294 @@
+package tokenize
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/huichen/sego"
+	"github.com/rs/zerolog/log"
+
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+const (
+	// DefaultDictionary 未配置时使用的基础词典
+	DefaultDictionary = "dict/dictionary.txt"
+	// DefaultUserTermFrequency 未指定词频时用户词条的词频
+	DefaultUserTermFrequency = 1000
+)
+
+var (
+	// ErrDictionaryNotLoaded 词典尚未加载错误
+	ErrDictionaryNotLoaded = errors.New("dictionary not loaded")
+	// ErrNoEditableDictionary 未配置可编辑的用户词典错误
+	ErrNoEditableDictionary = errors.New("no editable dictionary")
+	// ErrBadUserTerm 用户词条错误
+	ErrBadUserTerm = errors.New("bad user term")
+)
+
+// UserTerm 用户词典中的词条
+type UserTerm struct {
+	Text      string
+	Frequency int
+	POS       string
+}
+
+// Dictionary 中文分词词典, 由基础词典、只读的用户词典以及一个可编辑的用户词典组成,
+// 同一词条出现在多个词典中时以可编辑的用户词典、用户词典、基础词典的顺序优先.
+// 增删用户词条或重新加载词典时构建新的分词器并原子替换, 分词过程中不会看到加载了一半的词典.
+// 词典版本是各词典文件内容的哈希值, 随分词结果记录在索引中, 用于找出需要重新索引的文档.
+type Dictionary struct {
+	// 按优先级从高到低排列的词典文件
+	files    []string
+	editable string
+
+	// 串行化词典的加载与修改
+	mu      sync.Mutex
+	current atomic.Value
+}
+
+// loadedDictionary 已加载的词典
+type loadedDictionary struct {
+	segmenter *sego.Segmenter
+	version   string
+	userTerms int
+}
+
+// NewDictionary 新建词典, cfg为nil时只使用DefaultDictionary; 词典在第一次调用Load时加载.
+func NewDictionary(cfg *conf.TokenizerConfig) *Dictionary {
+	d := &Dictionary{}
+	base := DefaultDictionary
+	if cfg != nil {
+		if cfg.Dictionary != "" {
+			base = cfg.Dictionary
+		}
+		if cfg.EditableDictionary != "" {
+			d.editable = cfg.EditableDictionary
+			d.files = append(d.files, cfg.EditableDictionary)
+		}
+		d.files = append(d.files, cfg.UserDictionaries...)
+	}
+	d.files = append(d.files, base)
+	return d
+}
+
+// Load 加载词典, 已经加载过时直接返回.
+func (d *Dictionary) Load() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current.Load() != nil {
+		return nil
+	}
+	return d.load()
+}
+
+// Reload 重新加载全部词典文件, 加载失败时继续使用原有的词典.
+// 没有分词器使用词典时 (例如英文管道) 返回ErrDictionaryNotLoaded.
+func (d *Dictionary) Reload() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current.Load() == nil {
+		return ErrDictionaryNotLoaded
+	}
+	return d.load()
+}
+
+// load 加载词典文件并替换当前的分词器, 调用者需持有mu.
+func (d *Dictionary) load() error {
+	files := make([]string, 0, len(d.files))
+	h := fnv.New64a()
+	var userTerms int
+	for _, fn := range d.files {
+		data, err := ioutil.ReadFile(fn)
+		if err != nil {
+			if fn == d.editable && os.IsNotExist(err) {
+				// 可编辑的用户词典在第一次添加词条时创建
+				continue
+			}
+			// sego在词典文件不存在时直接退出进程, 因此需要事先检查
+			return fmt.Errorf("cannot read dictionary %s: %w", fn, err)
+		}
+		if len(data) == 0 {
+			// 空词典不影响分词, 也不影响词典版本
+			continue
+		}
+		files = append(files, fn)
+		fmt.Fprintf(h, "%s\n%d\n", filepath.Base(fn), len(data))
+		h.Write(data) // nolint
+		if fn != d.files[len(d.files)-1] {
+			userTerms += countTerms(data)
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("empty dictionary: %v", d.files)
+	}
+
+	segmenter := new(sego.Segmenter)
+	segmenter.LoadDictionary(strings.Join(files, ","))
+	loaded := &loadedDictionary{
+		segmenter: segmenter,
+		version:   strconv.FormatUint(h.Sum64(), 16),
+		userTerms: userTerms,
+	}
+	d.current.Store(loaded)
+	log.Info().Msgf("load dictionary, version=%s, files=%v", loaded.version, files)
+	return nil
+}
+
+// loaded 返回当前的词典, 尚未加载时返回nil.
+func (d *Dictionary) loaded() *loadedDictionary {
+	if v := d.current.Load(); v != nil {
+		return v.(*loadedDictionary)
+	}
+	return nil
+}
+
+// Version 返回当前的词典版本, 尚未加载时返回空字符串.
+func (d *Dictionary) Version() string {
+	if loaded := d.loaded(); loaded != nil {
+		return loaded.version
+	}
+	return ""
+}
+
+// UserTerms 返回当前词典中用户词条的数量, 同一词条出现在多个用户词典中时重复计数.
+func (d *Dictionary) UserTerms() int {
+	if loaded := d.loaded(); loaded != nil {
+		return loaded.userTerms
+	}
+	return 0
+}
+
+// AddTerms 向可编辑的用户词典添加词条并重新加载词典, 已存在的词条被覆盖.
+// 词频为0时使用DefaultUserTermFrequency; sego会丢弃词频小于2的词条, 因此词频为1的词条被拒绝.
+func (d *Dictionary) AddTerms(terms []*UserTerm) error {
+	for _, term := range terms {
+		if term.Text == "" || strings.IndexFunc(term.Text, unicode.IsSpace) >= 0 ||
+			term.Frequency < 0 || term.Frequency == 1 || strings.IndexFunc(term.POS, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("%w: %q", ErrBadUserTerm, term.Text)
+		}
+	}
+	return d.edit(func(entries map[string]*UserTerm) {
+		for _, term := range terms {
+			entry := *term
+			if entry.Frequency == 0 {
+				entry.Frequency = DefaultUserTermFrequency
+			}
+			entries[entry.Text] = &entry
+		}
+	})
+}
+
+// RemoveTerms 从可编辑的用户词典中删除词条并重新加载词典, 不存在的词条被忽略.
+// 基础词典与只读的用户词典中的词条不受影响.
+func (d *Dictionary) RemoveTerms(texts []string) error {
+	return d.edit(func(entries map[string]*UserTerm) {
+		for _, text := range texts {
+			delete(entries, text)
+		}
+	})
+}
+
+// edit 修改可编辑的用户词典, 写入新的词典文件之后重新加载词典.
+func (d *Dictionary) edit(fn func(entries map[string]*UserTerm)) error {
+	if d.editable == "" {
+		return ErrNoEditableDictionary
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current.Load() == nil {
+		return ErrDictionaryNotLoaded
+	}
+
+	entries, err := readUserTerms(d.editable)
+	if err != nil {
+		return err
+	}
+	fn(entries)
+	if err = writeUserTerms(d.editable, entries); err != nil {
+		return err
+	}
+	return d.load()
+}
+
+// countTerms 统计词典文件中至少包含词条与词频两列的行数.
+func countTerms(data []byte) int {
+	var n int
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.Fields(line)) >= 2 {
+			n++
+		}
+	}
+	return n
+}
+
+// readUserTerms 读取用户词典, 文件不存在时返回空集合.
+func readUserTerms(fn string) (map[string]*UserTerm, error) {
+	entries := make(map[string]*UserTerm)
+	fr, err := os.Open(fn)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer fr.Close()
+
+	scanner := bufio.NewScanner(fr)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		frequency, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		term := &UserTerm{Text: fields[0], Frequency: frequency}
+		if len(fields) > 2 {
+			term.POS = fields[2]
+		}
+		entries[term.Text] = term
+	}
+	return entries, scanner.Err()
+}
+
+// writeUserTerms 按词条排序写入用户词典, 先写临时文件再重命名, 避免留下写了一半的词典.
+func writeUserTerms(fn string, entries map[string]*UserTerm) error {
+	texts := make([]string, 0, len(entries))
+	for text := range entries {
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+
+	buf := new(bytes.Buffer)
+	for _, text := range texts {
+		term := entries[text]
+		if term.POS != "" {
+			fmt.Fprintf(buf, "%s %d %s\n", term.Text, term.Frequency, term.POS)
+		} else {
+			fmt.Fprintf(buf, "%s %d\n", term.Text, term.Frequency)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+	tmp := fn + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
@@ -0,0 +1,78 @@
+package tokenize
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+)
+
+func TestDictionary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dict")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	base := filepath.Join(dir, "dictionary.txt")
+	assert.Empty(t, ioutil.WriteFile(base, []byte("财政 100 n\n收入 100 n\n数字 100 n\n经济 100 n\n"), 0644))
+	user := filepath.Join(dir, "user.txt")
+	assert.Empty(t, ioutil.WriteFile(user, []byte("财政收入 10000 n\n"), 0644))
+	cfg := &conf.TokenizerConfig{
+		Dictionary:         base,
+		UserDictionaries:   []string{user},
+		EditableDictionary: filepath.Join(dir, "editable", "user.txt"),
+	}
+
+	dictionary := NewDictionary(cfg)
+	assert.True(t, errors.Is(dictionary.Reload(), ErrDictionaryNotLoaded))
+	p, err := NewPipeTokenizeProcessor(nil, common.LanguageTypeChinsese, cfg, dictionary)
+	assert.Empty(t, err)
+	v1 := dictionary.Version()
+	assert.NotEmpty(t, v1)
+	assert.Equal(t, 1, dictionary.UserTerms())
+
+	// 用户词典中的词条优先于基础词典
+	query := p.QueryTokenize("财政收入数字经济", common.LanguageTypeChinsese)
+	assert.Equal(t, map[string][]uint32{"财政收入": {0}, "数字": {1}, "经济": {2}}, query.Positions)
+	assert.Equal(t, v1, query.DictVersion)
+
+	// 添加词条之后不需要重启即可生效, 词典版本随之改变
+	assert.Empty(t, dictionary.AddTerms([]*UserTerm{{Text: "数字经济", POS: "n"}}))
+	v2 := dictionary.Version()
+	assert.NotEqual(t, v1, v2)
+	assert.Equal(t, 2, dictionary.UserTerms())
+	query = p.QueryTokenize("财政收入数字经济", common.LanguageTypeChinsese)
+	assert.Equal(t, map[string][]uint32{"财政收入": {0}, "数字经济": {1}}, query.Positions)
+	assert.Equal(t, v2, query.DictVersion)
+	data, err := ioutil.ReadFile(cfg.EditableDictionary)
+	assert.Empty(t, err)
+	assert.Equal(t, "数字经济 1000 n\n", string(data))
+
+	// 删除词条之后恢复原来的词典版本, 只读用户词典中的词条不受影响
+	assert.Empty(t, dictionary.RemoveTerms([]string{"数字经济", "财政收入"}))
+	assert.Equal(t, v1, dictionary.Version())
+	query = p.QueryTokenize("财政收入数字经济", common.LanguageTypeChinsese)
+	assert.Equal(t, map[string][]uint32{"财政收入": {0}, "数字": {1}, "经济": {2}}, query.Positions)
+
+	// 词典文件在外部被修改之后重新加载
+	assert.Empty(t, ioutil.WriteFile(user, []byte("财政收入 10000 n\n数字经济 10000 n\n"), 0644))
+	assert.Empty(t, dictionary.Reload())
+	assert.NotEqual(t, v1, dictionary.Version())
+	query = p.QueryTokenize("数字经济", common.LanguageTypeChinsese)
+	assert.Equal(t, map[string][]uint32{"数字经济": {0}}, query.Positions)
+
+	assert.True(t, errors.Is(dictionary.AddTerms([]*UserTerm{{Text: "数字 经济"}}), ErrBadUserTerm))
+	assert.True(t, errors.Is(dictionary.AddTerms([]*UserTerm{{Text: "数字经济", Frequency: 1}}), ErrBadUserTerm))
+	assert.Equal(t, ErrNoEditableDictionary, NewDictionary(nil).RemoveTerms([]string{"数字经济"}))
+
+	// 词典文件不存在时报错, 而不是退出进程
+	_, err = NewPipeTokenizeProcessor(nil, common.LanguageTypeChinsese, &conf.TokenizerConfig{Dictionary: filepath.Join(dir, "not-exist")}, nil)
+	assert.True(t, os.IsNotExist(errors.Unwrap(err)))
+	// 英文分词不加载词典
+	_, err = NewPipeTokenizeProcessor(nil, common.LanguageTypeEnglish, &conf.TokenizerConfig{Dictionary: filepath.Join(dir, "not-exist")}, nil)
+	assert.Empty(t, err)
+}
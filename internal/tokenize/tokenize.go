@@ -2,9 +2,10 @@ package tokenize
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
 	"strings"
-	"sync"
 	"unicode"
 
 	"github.com/huichen/sego"
@@ -12,186 +13,271 @@ import (
 
 	pb "github.com/amazingchow/photon-dance-vector-space-searcher/api"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
 	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/storage"
 )
 
+// 中文分词方式
+const (
+	// ChineseModeDictionary 按词典分词
+	ChineseModeDictionary = "dictionary"
+	// ChineseModeNGram 按汉字n-gram切分
+	ChineseModeNGram = "ngram"
+	// ChineseModeBoth 按词典分词, 同时以子字段的形式记录汉字n-gram
+	ChineseModeBoth = "both"
+)
+
+var (
+	// ErrUnsupportedLanguage 不支持的语种错误
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	// ErrBadTokenizerConfig 分词配置错误
+	ErrBadTokenizerConfig = errors.New("bad tokenizer config")
+)
+
+// 未配置时使用的n-gram长度
+var _DefaultNGramSizes = []int{2, 3}
+
 // PipeTokenizeProcessor 文本分词器
 type PipeTokenizeProcessor struct {
 	tokenBucket chan struct{}
 	storage     storage.Persister
 	language    common.LanguageType
-	chSegmenter *sego.Segmenter
+	dictionary  *Dictionary
 	chRegExp    *regexp.Regexp
+	// 是否按词典分词, 以及是否记录汉字n-gram
+	chDictionary bool
+	chNGram      bool
+	ngramSizes   []int
 }
 
-// NewPipeTokenizeProcessor 新建文本分词器.
-func NewPipeTokenizeProcessor(storage storage.Persister, language common.LanguageType) *PipeTokenizeProcessor {
+// cursor 分词过程中下一个词条的位置, 词典分词与n-gram子字段分别编号.
+// 同一文档的各行使用同一份词典, 分词期间替换的词典从下一个文档开始生效.
+type cursor struct {
+	word  uint32
+	ngram uint32
+	dict  *loadedDictionary
+}
+
+// NewPipeTokenizeProcessor 新建文本分词器, cfg为nil时按词典进行中文分词.
+// 各分词器可以共用同一份词典, dictionary为nil且需要按词典分词时按cfg新建.
+func NewPipeTokenizeProcessor(storage storage.Persister, language common.LanguageType, cfg *conf.TokenizerConfig,
+	dictionary *Dictionary) (*PipeTokenizeProcessor, error) {
 	p := &PipeTokenizeProcessor{
-		tokenBucket: make(chan struct{}, 20),
-		storage:     storage,
-		language:    language,
+		tokenBucket:  make(chan struct{}, 20),
+		storage:      storage,
+		language:     language,
+		chDictionary: true,
+		ngramSizes:   _DefaultNGramSizes,
+	}
+	if cfg != nil {
+		switch cfg.ChineseMode {
+		case "", ChineseModeDictionary:
+		case ChineseModeNGram:
+			p.chDictionary, p.chNGram = false, true
+		case ChineseModeBoth:
+			p.chNGram = true
+		default:
+			return nil, fmt.Errorf("%w: chinese_mode=%s", ErrBadTokenizerConfig, cfg.ChineseMode)
+		}
+		if len(cfg.NGramSizes) > 0 {
+			for _, n := range cfg.NGramSizes {
+				if n <= 0 {
+					return nil, fmt.Errorf("%w: ngram_sizes=%v", ErrBadTokenizerConfig, cfg.NGramSizes)
+				}
+			}
+			p.ngramSizes = cfg.NGramSizes
+		}
 	}
-	if language == common.LanguageTypeChinsese {
-		p.chSegmenter = new(sego.Segmenter)
-		p.chSegmenter.LoadDictionary("dict/dictionary.txt")
+	if language != common.LanguageTypeEnglish {
+		if p.chDictionary {
+			if dictionary == nil {
+				dictionary = NewDictionary(cfg)
+			}
+			if err := dictionary.Load(); err != nil {
+				return nil, err
+			}
+			p.dictionary = dictionary
+		}
 		p.chRegExp = regexp.MustCompile("[\u4E00-\u9FA5]+")
 	}
 	log.Info().Msg("load PipeTokenizeProcessor plugin")
-	return p
+	return p, nil
 }
 
-// InfoTokenize 对中/英文文本进行分词.
-func (p *PipeTokenizeProcessor) InfoTokenize(pGroup *sync.WaitGroup, input common.PacketChannel, output common.ConcordanceChannel) {
-	pGroup.Add(1)
-LOOP_LABEL:
-	for {
-		select {
-		case packet, ok := <-input:
-			{
-				if !ok {
-					close(output)
-					break LOOP_LABEL
-				}
-				if packet.DeliveryStatus == pb.PacketDeliveryStatus_InDelivery {
-					switch p.language {
-					case common.LanguageTypeEnglish:
-						{
-							go p.tokenizeEnglishDoc(packet, output)
-						}
-					case common.LanguageTypeChinsese:
-						{
-							go p.tokenizeChineseDoc(packet, output)
-						}
-					default:
-						{
-
-						}
-					}
-				}
-			}
-		default:
-			{
+// Process 对中/英文以及中英文混合文本进行分词, 并将concordance交给下游 (并发安全).
+// 语种为auto时按文档内容检测语种, 检测结果记录在concordance中.
+func (p *PipeTokenizeProcessor) Process(ctx context.Context, doc *common.Document) (*common.Document, error) {
+	packet := doc.Packet
+	if packet.Operation == pb.DocOperation_DeleteDoc {
+		doc.Concordance = common.NewConcordanceWrapper(packet.DocId)
+		doc.Concordance.Operation = packet.Operation
+		return doc, nil
+	}
 
-			}
+	file, err := p.readDoc(ctx, packet)
+	if err != nil {
+		return nil, err
+	}
+	language := p.language
+	if language == common.LanguageTypeAuto {
+		language = common.DetectLanguage(file.Body...)
+	}
+
+	wrapper := common.NewConcordanceWrapper(packet.DocId)
+	wrapper.Operation = packet.Operation
+	wrapper.Language = language
+	c := p.newCursor(language)
+	for _, line := range file.Body {
+		if err = p.tokenize(language, line, wrapper, c); err != nil {
+			return nil, err
 		}
 	}
-	pGroup.Done()
-	log.Info().Msg("unload PipeTokenizeProcessor plugin")
+	if c.dict != nil {
+		wrapper.DictVersion = c.dict.version
+	}
+	doc.Concordance = wrapper
+	log.Debug().Msg("PipeTokenizeProcessor processes one data packet")
+	return doc, nil
 }
 
-func (p *PipeTokenizeProcessor) tokenizeEnglishDoc(packet *pb.Packet, output common.ConcordanceChannel) {
-	p.tokenBucket <- struct{}{}
+// newCursor 新建分词游标, 按词典分词中文时取当前的词典.
+func (p *PipeTokenizeProcessor) newCursor(language common.LanguageType) *cursor {
+	c := new(cursor)
+	if language != common.LanguageTypeEnglish && p.chDictionary {
+		c.dict = p.dictionary.loaded()
+	}
+	return c
+}
 
+// readDoc 从存储中读取文档的正文.
+func (p *PipeTokenizeProcessor) readDoc(ctx context.Context, packet *pb.Packet) (*common.File, error) {
 	file := &common.File{
 		Type: packet.DocType,
 		Name: packet.DocId,
 		Body: make([]string, 0),
 	}
-	if _, err := p.storage.Readable(context.Background(), file); err != nil {
-		log.Error().Err(err)
-		return
+	if _, err := p.storage.Readable(ctx, file); err != nil {
+		return nil, err
 	}
-	if _, err := p.storage.Get(context.Background(), file); err != nil {
-		log.Error().Err(err)
-		return
+	if _, err := p.storage.Get(ctx, file); err != nil {
+		return nil, err
 	}
+	return file, nil
+}
 
-	concordance := make(map[string]uint64)
-	wordsCh := make(chan common.WordsWrapper, 10)
-	exit := make(chan struct{})
-
-	go func() {
-		for x := range wordsCh {
-			for _, w := range x.Words {
-				concordance[strings.ToLower(w)]++
-			}
+// tokenize 按语种对一行文本进行分词, 词条从position开始依次编号.
+func (p *PipeTokenizeProcessor) tokenize(language common.LanguageType, line string, wrapper *common.ConcordanceWrapper, position *cursor) error {
+	switch language {
+	case common.LanguageTypeEnglish:
+		{
+			p.tokenizeEnglish(line, wrapper, position)
+		}
+	case common.LanguageTypeChinsese:
+		{
+			p.tokenizeChinese(line, wrapper, position)
+		}
+	case common.LanguageTypeMixed:
+		{
+			p.tokenizeMixed(line, wrapper, position)
+		}
+	default:
+		{
+			return ErrUnsupportedLanguage
 		}
-		exit <- struct{}{}
-	}()
-
-	fc := func(r rune) bool { return !unicode.IsLetter(r) }
-	for _, line := range file.Body {
-		words := strings.FieldsFunc(line, fc)
-		wordsCh <- common.WordsWrapper{Words: words}
 	}
-	close(wordsCh)
+	return nil
+}
 
-	<-exit
+// isWordSeparator 判断是否为切分英文等非汉字文本的分隔符, 字母与数字之外的字符都是分隔符.
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
 
-	output <- &common.ConcordanceWrapper{
-		DocID:       packet.DocId,
-		Concordance: concordance,
+// tokenizeEnglish 按非字母数字字符切分, 词条统一转为小写, 从而保留数字以及G20这样的缩写.
+func (p *PipeTokenizeProcessor) tokenizeEnglish(line string, wrapper *common.ConcordanceWrapper, position *cursor) {
+	for _, w := range strings.FieldsFunc(line, isWordSeparator) {
+		wrapper.Add(strings.ToLower(w), position.word)
+		position.word++
 	}
-	log.Debug().Msg("PipeTokenizeProcessor processes one data packet")
-
-	<-p.tokenBucket
 }
 
-func (p *PipeTokenizeProcessor) tokenizeChineseDoc(packet *pb.Packet, output common.ConcordanceChannel) {
-	p.tokenBucket <- struct{}{}
-
-	file := &common.File{
-		Type: packet.DocType,
-		Name: packet.DocId,
-		Body: make([]string, 0),
-	}
-	if _, err := p.storage.Readable(context.Background(), file); err != nil {
-		log.Error().Err(err)
-		return
+// tokenizeChinese 按配置的方式切分汉字: 用sego切分并只保留汉字词条, 和/或将汉字串切分为n-gram子字段.
+func (p *PipeTokenizeProcessor) tokenizeChinese(line string, wrapper *common.ConcordanceWrapper, position *cursor) {
+	if p.chDictionary {
+		segments := position.dict.segmenter.Segment([]byte(line))
+		for _, w := range p.chRegExp.FindAllString(sego.SegmentsToString(segments, false), -1) {
+			wrapper.Add(w, position.word)
+			position.word++
+		}
 	}
-	if _, err := p.storage.Get(context.Background(), file); err != nil {
-		log.Error().Err(err)
-		return
+	if p.chNGram {
+		for _, run := range p.chRegExp.FindAllString(line, -1) {
+			p.tokenizeNGram([]rune(run), wrapper, position)
+		}
 	}
+}
 
-	concordance := make(map[string]uint64)
-	wordsCh := make(chan common.WordsWrapper, 10)
-	exit := make(chan struct{})
-
-	go func() {
-		for x := range wordsCh {
-			for _, w := range x.Words {
-				concordance[w]++
+// tokenizeNGram 将汉字串切分为各个长度的n-gram, 位置为n-gram首字在所有汉字中的序号,
+// 因此不同长度的n-gram可以共用一个位置. 汉字串短于最短的n-gram时整体作为一个词条.
+func (p *PipeTokenizeProcessor) tokenizeNGram(run []rune, wrapper *common.ConcordanceWrapper, position *cursor) {
+	shortest := p.ngramSizes[0]
+	for _, n := range p.ngramSizes {
+		if n < shortest {
+			shortest = n
+		}
+	}
+	if len(run) < shortest {
+		wrapper.Add(common.NGramTerm(string(run)), position.ngram)
+	}
+	for i := range run {
+		for _, n := range p.ngramSizes {
+			if i+n <= len(run) {
+				wrapper.Add(common.NGramTerm(string(run[i:i+n])), position.ngram+uint32(i))
 			}
 		}
-		exit <- struct{}{}
-	}()
-
-	for _, line := range file.Body {
-		segments := p.chSegmenter.Segment([]byte(line))
-		words := p.chRegExp.FindAllString(sego.SegmentsToString(segments, false), -1)
-		wordsCh <- common.WordsWrapper{Words: words}
 	}
-	close(wordsCh)
-
-	<-exit
+	position.ngram += uint32(len(run))
+}
 
-	output <- &common.ConcordanceWrapper{
-		DocID:       packet.DocId,
-		Concordance: concordance,
+// tokenizeMixed 将文本切分为汉字串与非汉字串, 汉字串用sego切分,
+// 非汉字串按非字母数字字符切分并转为小写, 从而保留英文单词、缩写以及数字.
+func (p *PipeTokenizeProcessor) tokenizeMixed(line string, wrapper *common.ConcordanceWrapper, position *cursor) {
+	runes := []rune(line)
+	for start := 0; start < len(runes); {
+		han := common.IsHan(runes[start])
+		end := start + 1
+		for end < len(runes) && common.IsHan(runes[end]) == han {
+			end++
+		}
+		run := string(runes[start:end])
+		if han {
+			p.tokenizeChinese(run, wrapper, position)
+		} else {
+			for _, w := range strings.FieldsFunc(run, isWordSeparator) {
+				wrapper.Add(strings.ToLower(w), position.word)
+				position.word++
+			}
+		}
+		start = end
 	}
-	log.Debug().Msg("PipeTokenizeProcessor processes one data packet")
-
-	<-p.tokenBucket
 }
 
-// QueryTokenize 对查询语句进行分词.
-func (p *PipeTokenizeProcessor) QueryTokenize(query string, language common.LanguageType, concordance map[string]uint64) {
+// QueryTokenize 对查询语句进行分词, 并记录词条在查询语句中的位置.
+// 语种为auto时按查询语句检测语种, 检测结果记录在返回的concordance中.
+func (p *PipeTokenizeProcessor) QueryTokenize(query string, language common.LanguageType) *common.ConcordanceWrapper {
 	p.tokenBucket <- struct{}{}
 
-	if language == common.LanguageTypeEnglish {
-		fc := func(r rune) bool { return !unicode.IsLetter(r) }
-		words := strings.FieldsFunc(query, fc)
-		for _, w := range words {
-			concordance[strings.ToLower(w)]++
-		}
-	} else if language == common.LanguageTypeChinsese {
-		segments := p.chSegmenter.Segment([]byte(query))
-		words := p.chRegExp.FindAllString(sego.SegmentsToString(segments, false), -1)
-		for _, w := range words {
-			concordance[w]++
-		}
+	if language == common.LanguageTypeAuto {
+		language = common.DetectLanguage(query)
+	}
+	wrapper := common.NewConcordanceWrapper("")
+	wrapper.Language = language
+	c := p.newCursor(language)
+	p.tokenize(language, query, wrapper, c) // nolint
+	if c.dict != nil {
+		wrapper.DictVersion = c.dict.version
 	}
 
 	<-p.tokenBucket
+	return wrapper
 }
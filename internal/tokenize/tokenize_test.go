@@ -0,0 +1,150 @@
+package tokenize
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/common"
+	conf "github.com/amazingchow/photon-dance-vector-space-searcher/internal/config"
+	"github.com/amazingchow/photon-dance-vector-space-searcher/internal/indexing"
+)
+
+// newTestTokenizer 用只含少量词条的词典新建分词器
+func newTestTokenizer(t *testing.T, language common.LanguageType) *PipeTokenizeProcessor {
+	dir, err := ioutil.TempDir("", "dict")
+	assert.Empty(t, err)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "dictionary.txt")
+	assert.Empty(t, ioutil.WriteFile(fn, []byte("财政 100 n\n收入 100 n\n增长 100 v\n预算 100 n\n数字经济 100 n\n"), 0644))
+
+	dictionary := NewDictionary(&conf.TokenizerConfig{Dictionary: fn})
+	assert.Empty(t, dictionary.Load())
+	p := &PipeTokenizeProcessor{
+		tokenBucket:  make(chan struct{}, 1),
+		language:     language,
+		dictionary:   dictionary,
+		chRegExp:     regexp.MustCompile("[\u4E00-\u9FA5]+"),
+		chDictionary: true,
+		ngramSizes:   _DefaultNGramSizes,
+	}
+	return p
+}
+
+func TestDetectLanguage(t *testing.T) {
+	assert.Equal(t, common.LanguageTypeEnglish, common.DetectLanguage("Fiscal revenue grew by 5%"))
+	assert.Equal(t, common.LanguageTypeChinsese, common.DetectLanguage("财政收入增长", "预算。"))
+	assert.Equal(t, common.LanguageTypeMixed, common.DetectLanguage("财政收入增长", "GDP"))
+	assert.Equal(t, common.LanguageTypeMixed, common.DetectLanguage("2020年财政收入"))
+}
+
+func TestQueryTokenizeMixed(t *testing.T) {
+	p := newTestTokenizer(t, common.LanguageTypeAuto)
+
+	// 中文分词丢弃英文缩写与数字
+	query := p.QueryTokenize("2020年GDP增长", common.LanguageTypeChinsese)
+	assert.Equal(t, common.LanguageTypeChinsese, query.Language)
+	assert.Equal(t, map[string][]uint32{"年": {0}, "增长": {1}}, query.Positions)
+
+	query = p.QueryTokenize("2020年GDP增长, 财政收入Growth", common.LanguageTypeAuto)
+	assert.Equal(t, common.LanguageTypeMixed, query.Language)
+	assert.Equal(t, map[string][]uint32{
+		"2020":   {0},
+		"年":      {1},
+		"gdp":    {2},
+		"增长":     {3},
+		"财政":     {4},
+		"收入":     {5},
+		"growth": {6},
+	}, query.Positions)
+
+	query = p.QueryTokenize("Fiscal Revenue", common.LanguageTypeAuto)
+	assert.Equal(t, common.LanguageTypeEnglish, query.Language)
+	assert.Equal(t, map[string][]uint32{"fiscal": {0}, "revenue": {1}}, query.Positions)
+
+	// 英文文本与混合文本的非汉字部分使用相同的切分规则, 保留数字
+	query = p.QueryTokenize("G20 summit, 2020", common.LanguageTypeEnglish)
+	assert.Equal(t, map[string][]uint32{"g20": {0}, "summit": {1}, "2020": {2}}, query.Positions)
+}
+
+func TestQueryTokenizeNGram(t *testing.T) {
+	p := newTestTokenizer(t, common.LanguageTypeChinsese)
+	p.chDictionary, p.chNGram = false, true
+	query := p.QueryTokenize("财政收入, 税", common.LanguageTypeChinsese)
+	assert.Equal(t, map[string][]uint32{
+		"#财政":  {0},
+		"#财政收": {0},
+		"#政收":  {1},
+		"#政收入": {1},
+		"#收入":  {2},
+		// 短于最短n-gram的汉字串整体作为一个词条
+		"#税": {4},
+	}, query.Positions)
+
+	// 词典分词与n-gram子字段分别编号位置
+	p.chDictionary = true
+	query = p.QueryTokenize("收入增长", common.LanguageTypeChinsese)
+	assert.Equal(t, map[string][]uint32{
+		"收入":   {0},
+		"增长":   {1},
+		"#收入":  {0},
+		"#收入增": {0},
+		"#入增":  {1},
+		"#入增长": {1},
+		"#增长":  {2},
+	}, query.Positions)
+
+	_, err := NewPipeTokenizeProcessor(nil, common.LanguageTypeEnglish, &conf.TokenizerConfig{ChineseMode: "unigram"}, nil)
+	assert.True(t, errors.Is(err, ErrBadTokenizerConfig))
+	_, err = NewPipeTokenizeProcessor(nil, common.LanguageTypeEnglish, &conf.TokenizerConfig{NGramSizes: []int{0}}, nil)
+	assert.True(t, errors.Is(err, ErrBadTokenizerConfig))
+}
+
+func TestNGramRecall(t *testing.T) {
+	search := func(p *PipeTokenizeProcessor, text string, phrase bool) []string {
+		indexer := indexing.NewPipeIndexProcessor(&conf.IndexerConfig{}, nil)
+		for id, text := range []string{"发展数字经济", "财政收入增长"} {
+			wrapper := p.QueryTokenize(text, common.LanguageTypeChinsese)
+			wrapper.DocID = strconv.Itoa(id + 1)
+			_, err := indexer.Process(context.Background(), &common.Document{Concordance: wrapper})
+			assert.Empty(t, err)
+		}
+		indexer.BuildTFIDF()
+		snapshot := indexer.Snapshot()
+		scorer, err := indexer.Scorer("")
+		assert.Empty(t, err)
+
+		query := p.QueryTokenize(text, common.LanguageTypeChinsese)
+		q := &indexing.Query{Concordance: query.Concordance}
+		if phrase {
+			q.Phrases = []*indexing.PhraseQuery{indexing.NewPhraseQuery(query, false, 0)}
+		}
+		objects, _ := snapshot.Window(0, 10, scorer, q)
+		ids := make([]string, len(objects))
+		for i, obj := range objects {
+			ids[i] = obj.DocID
+		}
+		return ids
+	}
+
+	// "数字"不在词典中, 与文档中的"数字经济"无法匹配
+	p := newTestTokenizer(t, common.LanguageTypeChinsese)
+	assert.Empty(t, search(p, "数字", false))
+
+	// 合并n-gram子字段之后, 词的一部分也可以命中
+	p.chNGram = true
+	assert.Equal(t, []string{"1"}, search(p, "数字", false))
+	assert.Equal(t, []string{"2"}, search(p, "政收入", true))
+
+	// 只使用n-gram时短语在n-gram子字段上匹配
+	p.chDictionary = false
+	assert.Equal(t, []string{"1"}, search(p, "字经济", true))
+	assert.Empty(t, search(p, "经济发展", true))
+}
@@ -17,6 +17,8 @@ var (
 	ErrServiceUnavailable = fmt.Errorf("service unavailable")
 	// ErrContextDone context超时错误
 	ErrContextDone = fmt.Errorf("context done")
+	// ErrInvalidPageToken 分页令牌不合法错误
+	ErrInvalidPageToken = fmt.Errorf("invalid page token")
 )
 
 // IsContextDone 检查context是否超时.